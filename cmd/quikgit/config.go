@@ -0,0 +1,114 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// runConfigCommand implements `quikgit config get|set|list|path|validate|schema`.
+func runConfigCommand(cfg *config.Config, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand (get, set, list, path, validate, schema)")
+	}
+
+	switch args[0] {
+	case "get":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: config get KEY")
+		}
+		value, err := cfg.Get(args[1])
+		if err != nil {
+			return err
+		}
+		fmt.Println(value)
+
+	case "set":
+		if len(args) != 3 {
+			return fmt.Errorf("usage: config set KEY VALUE")
+		}
+		// Start from the file alone so environment and flag overrides
+		// for this run are never persisted.
+		fileCfg, err := config.LoadFile(cfg.ConfigPath)
+		if err != nil {
+			return err
+		}
+		if err := fileCfg.Set(args[1], args[2], config.SourceFile); err != nil {
+			return err
+		}
+		// Only the key being set blocks the save, so a broken file can be
+		// fixed one key at a time.
+		if errs, ok := fileCfg.Validate().(config.ValidationErrors); ok {
+			for _, e := range errs {
+				if e.Key == args[1] {
+					return e
+				}
+			}
+		}
+		if err := fileCfg.Save(); err != nil {
+			return fmt.Errorf("failed to save configuration: %w", err)
+		}
+
+	case "list":
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, setting := range cfg.Settings() {
+			value := setting.Value
+			if setting.Secret && value != "" {
+				value = "********"
+			}
+			source := string(setting.Source)
+			if setting.Source == config.SourceEnv {
+				source += " (" + setting.EnvVar + ")"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", setting.Key, value, source)
+		}
+		return w.Flush()
+
+	case "path":
+		fmt.Println(cfg.ConfigPath)
+
+	case "validate":
+		if from := cfg.MigratedFrom(); from != 0 {
+			fmt.Printf("%s uses schema version %d; it will be written as version %d on the next save\n",
+				cfg.ConfigPath, from, config.CurrentVersion)
+		}
+		if err := cfg.Validate(); err != nil {
+			printValidationErrors(cfg, err)
+			return fmt.Errorf("%s is invalid", cfg.ConfigPath)
+		}
+		fmt.Printf("%s is valid\n", cfg.ConfigPath)
+
+	case "schema":
+		schema, err := config.JSONSchema()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(schema))
+
+	default:
+		return fmt.Errorf("unknown subcommand %q (get, set, list, path, validate, schema)", args[0])
+	}
+
+	return nil
+}
+
+// printValidationErrors lists each invalid key along with the layer that set it.
+func printValidationErrors(cfg *config.Config, err error) {
+	errs, ok := err.(config.ValidationErrors)
+	if !ok {
+		fmt.Fprintln(os.Stderr, err)
+		return
+	}
+
+	for _, e := range errs {
+		source := cfg.SourceOf(e.Key)
+		if source == config.SourceEnv {
+			fmt.Fprintf(os.Stderr, "  %s (from %s)\n", e, config.EnvVar(e.Key))
+		} else {
+			fmt.Fprintf(os.Stderr, "  %s (from %s)\n", e, source)
+		}
+	}
+}
@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/internal/history"
+	"github.com/lvcasx1/quikgit/internal/install"
+	"github.com/lvcasx1/quikgit/internal/trust"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// runInstallCommand implements `quikgit install [--dry-run] [--trust]
+// [--no-scripts] [--skip NAME] [--env-values FILE] [--revert-changes]
+// [--offline] [PATH...]`.
+func runInstallCommand(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	dryRun := flags.Bool("dry-run", false, "Print the install plan without running it")
+	trustAll := flags.Bool("trust", false, "Run the scripts of untrusted repositories too")
+	noScripts := flags.Bool("no-scripts", false, "Run every repository with its scripts disabled")
+	var skip stringList
+	flags.Var(&skip, "skip", "Skip the plan command with this name (repeatable)")
+	envValues := flags.String("env-values", "", "File of KEY=value lines for the variables of created .env files")
+	revert := flags.Bool("revert-changes", false, "Undo the changes installs make to tracked files and the files they leave unignored")
+	offline := flags.Bool("offline", false, "Install from local caches only")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	for i, path := range paths {
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		if info, err := os.Stat(abs); err != nil || !info.IsDir() {
+			return fmt.Errorf("%s is not a directory", path)
+		}
+		paths[i] = abs
+	}
+
+	var client *github.Client
+	if authManager, err := newAuthManager(cfg); err == nil {
+		client = github.NewClient(authManager.GetClient())
+	}
+	installManager := newInstallManager(cfg, client)
+	if *envValues != "" {
+		values, err := install.LoadEnvValues(*envValues)
+		if err != nil {
+			return err
+		}
+		installManager.SetEnvFiles(cfg.Install.EnvFiles, values)
+	}
+	if *offline {
+		installManager.SetOffline(true, cfg.Install.Offline.FindLinks)
+	}
+
+	// --trust runs the scripts of untrusted repositories whatever the
+	// untrusted policy, so it has to apply before they are planned
+	if *trustAll {
+		installManager.SetTrust(installManager.Trust(), install.UntrustedConfirm)
+	}
+	var plans []*install.Plan
+	if *noScripts {
+		plans = installManager.PlansWithoutScripts(paths)
+	} else {
+		plans = installManager.Plans(paths)
+	}
+
+	skipped := 0
+	for _, plan := range plans {
+		skipped += plan.SkipCommands(skip...)
+	}
+	if len(skip) > 0 && skipped == 0 {
+		return fmt.Errorf("no planned command named %s", strings.Join(skip, ", "))
+	}
+
+	printSandbox(installManager.Sandbox())
+	fmt.Printf("Scheduler: %s\n", installManager.Scheduler().Describe())
+	if installManager.Offline() {
+		fmt.Println("Offline: installing from local caches only")
+	}
+	for _, plan := range plans {
+		printPlan(plan)
+	}
+
+	held := 0
+	if !*trustAll {
+		held = holdUntrusted(plans)
+	}
+	if held > 0 {
+		fmt.Printf("%d untrusted repositories held back; rerun with --trust or --no-scripts to install them\n", held)
+	}
+	if *dryRun {
+		return nil
+	}
+
+	run := history.NewRun(history.ActionInstall, "")
+	for _, plan := range plans {
+		fullName := plan.FullName
+		if fullName == "" {
+			fullName = plan.Repository
+		}
+		run.Entries = append(run.Entries, &history.Entry{FullName: fullName, Path: plan.Path})
+	}
+	defer saveRun(run)
+	keepLogs(installManager, run)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for progress := range installManager.GetProgressChannel() {
+			if progress.Command != "" && progress.Output == "" && progress.Status != "Running..." {
+				fmt.Printf("  %s: %s\n", progress.Repository, progress.Status)
+			}
+		}
+	}()
+
+	results, _ := installManager.InstallPlans(context.Background(), plans)
+	<-done
+
+	failed := 0
+	var notOffline []string
+	for i, result := range results {
+		run.Entries[i].Install = history.NewInstallRecord(result)
+		if !printInstallResult(result) {
+			failed++
+		}
+		if *revert && len(result.Modified)+len(result.Untracked) > 0 {
+			if err := install.RevertChanges(plans[i].Path, result); err != nil {
+				fmt.Printf("    ✗ reverting changes failed: %v\n", err)
+				failed++
+			} else {
+				fmt.Printf("    reverted %d modified and %d untracked files\n", len(result.Modified), len(result.Untracked))
+			}
+		}
+		if installManager.Offline() && !result.Success {
+			notOffline = append(notOffline, result.Repository)
+		}
+	}
+	if len(notOffline) > 0 {
+		fmt.Printf("Could not be installed offline: %s\n", strings.Join(notOffline, ", "))
+	}
+	return failedError(failed)
+}
+
+// newInstallManager creates an install manager from the install settings.
+// Repositories of the user and organizations client is signed in as, which
+// may be nil, and those on the allowlist are trusted.
+func newInstallManager(cfg *config.Config, client *github.Client) *install.Manager {
+	installManager := install.NewManager(cfg.Install.Concurrent, time.Duration(cfg.Install.TimeoutMinutes)*time.Minute)
+	installManager.SetSkipOnError(cfg.Install.SkipOnError)
+	installManager.SetVerify(cfg.Install.Verify)
+	installManager.SetDetectDepth(cfg.Install.DetectDepth)
+	installManager.SetToolchainPolicy(cfg.Install.ToolchainPolicy)
+	installManager.SetVersionManagers(cfg.Install.VersionManagers.ByTool(), cfg.Install.VersionManagers.Install)
+	sandbox := cfg.Install.Sandbox
+	installManager.SetSandbox(install.NewSandbox(sandbox.Mode, sandbox.Network, sandbox.AllowedHosts))
+	installManager.SetLimits(installLimits(cfg))
+	installManager.SetHooks(cfg.Hooks, false)
+	installManager.SetStrategy(cfg.Install.Container.Strategy, cfg.Install.Container.Runtime)
+	installManager.SetOffline(cfg.Install.Offline.Enabled, cfg.Install.Offline.FindLinks)
+
+	// Nobody is prompted for the variables of created .env files, which are
+	// given by the values file or left unset
+	var envValues map[string]string
+	if cfg.Install.EnvValues != "" {
+		values, err := install.LoadEnvValues(cfg.Install.EnvValues)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+		envValues = values
+	}
+	installManager.SetEnvFiles(cfg.Install.EnvFiles, envValues)
+
+	allowlist, err := trust.LoadAllowlist(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	var owners []string
+	if client != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if owners, err = trust.Owners(ctx, client); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	installManager.SetTrust(trust.NewChecker(owners, allowlist), cfg.Install.Untrusted)
+	return installManager
+}
+
+// installLimits is what the scheduler lets install commands use at once.
+func installLimits(cfg *config.Config) install.Limits {
+	scheduler := cfg.Install.Scheduler
+	return install.Limits{
+		Commands: cfg.Install.Concurrent,
+		Network:  scheduler.Network,
+		Build:    scheduler.Build,
+		CPUs:     scheduler.CPUs,
+		MemoryMB: scheduler.MemoryMB,
+	}
+}
+
+// keepLogs writes the output of the run's install commands to its log
+// directory.
+func keepLogs(installManager *install.Manager, run *history.Run) {
+	dir, err := history.LogDir(run.ID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: install logs not kept: %v\n", err)
+		return
+	}
+	installManager.SetLogDir(dir)
+}
+
+// holdUntrusted keeps untrusted repositories from running their scripts
+// without confirmation, which there is no one to give in a headless run. It
+// returns the number of repositories held back.
+func holdUntrusted(plans []*install.Plan) int {
+	held := 0
+	for _, plan := range plans {
+		if !plan.NeedsConfirmation() {
+			continue
+		}
+		held++
+		plan.Error = fmt.Errorf("not trusted: %s", plan.TrustReason)
+		if plan.FullName != "" {
+			plan.Error = fmt.Errorf("%w (quikgit trust add %s allows it)", plan.Error, plan.FullName)
+		}
+	}
+	return held
+}
+
+// printSandbox tells whether commands will run sandboxed.
+func printSandbox(sandbox *install.Sandbox) {
+	switch {
+	case sandbox.Mode == install.SandboxOff:
+	case sandbox.Enabled():
+		fmt.Printf("Sandbox: %s\n", sandbox.Describe())
+	case sandbox.Mode == install.SandboxRequired:
+		fmt.Printf("✗ Sandbox required but unavailable: %v\n", sandbox.Available())
+	default:
+		fmt.Printf("⚠ Sandbox unavailable, commands run unsandboxed: %v\n", sandbox.Available())
+	}
+}
+
+// printPlan prints a repository's install plan: each step's directory and
+// commands, with their names for --skip, missing tools and toolchain pins.
+func printPlan(plan *install.Plan) {
+	fmt.Printf("%s (%s)\n", plan.Repository, plan.Path)
+	if plan.NeedsConfirmation() {
+		fmt.Printf("  ⚠ not trusted: %s\n", plan.TrustReason)
+	}
+	if plan.NoScripts {
+		fmt.Println("  scripts disabled; commands that run repository code are skipped")
+	}
+	if plan.Error != nil {
+		fmt.Printf("  - %s\n", plan.Describe())
+		return
+	}
+	for _, file := range plan.EnvFiles {
+		fmt.Printf("  + %s\n", file.Describe())
+	}
+	if len(plan.Steps) == 0 {
+		fmt.Printf("  - %s\n", plan.Describe())
+		return
+	}
+
+	for _, step := range plan.Steps {
+		dir := step.Dir
+		if dir == "" {
+			dir = "."
+		}
+		fmt.Printf("  %s in %s\n", step.Project, dir)
+		switch {
+		case step.Container != "":
+			fmt.Printf("    runs with %s", step.Container)
+			if len(step.Matched) > 0 {
+				fmt.Printf(" instead of installing %s on the host", strings.Join(step.Matched, ", "))
+			}
+			fmt.Println()
+		case len(step.Matched) > 1:
+			fmt.Printf("    matched %s\n", strings.Join(step.Matched, ", "))
+		}
+		for i, cmd := range step.Commands {
+			line := strings.TrimSpace(cmd.Command + " " + strings.Join(cmd.Args, " "))
+			tags := []string{cmd.Name, install.Classify(cmd)}
+			if step.Hook != "" {
+				tags = append(tags, strings.ToLower(cmd.Description))
+			}
+			switch {
+			case step.Skip[i]:
+				fmt.Printf("    - %s [%s, skipped]\n", line, cmd.Name)
+				continue
+			case !cmd.Required:
+				tags = append(tags, "optional")
+			}
+			fmt.Printf("    $ %s [%s]\n", line, strings.Join(tags, ", "))
+		}
+		if len(step.Uncached) > 0 {
+			fmt.Printf("    ✗ offline: %s\n", step.DescribeUncached())
+		}
+		for _, tool := range step.Missing() {
+			fmt.Printf("    ✗ %s not found", tool)
+			if suggestions := install.GetInstallationSuggestions(tool); len(suggestions) > 0 {
+				fmt.Printf(": %s", suggestions[0])
+			}
+			fmt.Println()
+		}
+		for _, toolchain := range step.Toolchains {
+			mark := "✓"
+			if !toolchain.Satisfied {
+				mark = "⚠"
+			}
+			fmt.Printf("    %s %s\n", mark, toolchain)
+		}
+	}
+}
+
+// printInstallResult prints the outcome of one repository's install and
+// reports whether it counts as a success: an install, or a repository with
+// nothing to install. Repositories held back or stopped before installing,
+// such as by a toolchain mismatch or a missing sandbox, are failures.
+func printInstallResult(result install.InstallResult) bool {
+	switch {
+	case result.Success:
+		fmt.Printf("✓ installed %s (%s) in %s\n", result.Repository, result.ProjectType, result.Duration.Round(time.Second))
+		for _, project := range result.Projects {
+			if project.VirtualEnv != "" {
+				fmt.Printf("    %s virtualenv: %s\n", project.Name, project.VirtualEnv)
+			}
+		}
+		printResultNotes(result)
+		return true
+	case errors.Is(result.Error, install.ErrNoProjects):
+		fmt.Printf("- %s: %v\n", result.Repository, result.Error)
+		printResultNotes(result)
+		return true
+	case result.Error != nil:
+		fmt.Printf("✗ %s: %v\n", result.Repository, result.Error)
+		printResultNotes(result)
+		return false
+	default:
+		fmt.Printf("✗ install failed for %s\n", result.Repository)
+		for _, project := range result.Projects {
+			if !project.Success && project.Error != nil {
+				fmt.Printf("    %s: %v\n", project.Name, project.Error)
+			}
+		}
+		for _, cmd := range result.Commands {
+			if !cmd.Success && cmd.LogFile != "" {
+				fmt.Printf("    log of %s: %s\n", cmd.Command, cmd.LogFile)
+			}
+		}
+		return false
+	}
+}
+
+// printResultNotes lists the environment files an install created and its
+// warnings, such as variables left unset.
+func printResultNotes(result install.InstallResult) {
+	for _, path := range result.EnvFiles {
+		fmt.Printf("    created %s\n", path)
+	}
+	for _, warning := range result.Warnings {
+		fmt.Printf("    ⚠ %s\n", warning)
+	}
+}
+
+// stringList collects the values of a repeatable flag.
+type stringList []string
+
+func (s *stringList) String() string { return strings.Join(*s, ",") }
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/lvcasx1/quikgit/internal/install"
 	"github.com/lvcasx1/quikgit/internal/ui/bubbletea"
 	"github.com/lvcasx1/quikgit/pkg/config"
 )
@@ -25,9 +26,16 @@ var (
 	showHelp    = flag.Bool("help", false, "Show help information")
 	configPath  = flag.String("config", "", "Path to configuration file")
 	debug       = flag.Bool("debug", false, "Enable debug mode")
+	configFlags = config.BindFlags(flag.CommandLine)
 )
 
 func main() {
+	// Sandboxed installs with a network allowlist run their commands through
+	// this bridge, before any flag or configuration handling
+	if len(os.Args) > 3 && os.Args[1] == install.BridgeCommand && os.Args[3] == "--" {
+		os.Exit(install.RunBridge(os.Args[2], os.Args[4:]))
+	}
+
 	flag.Parse()
 
 	if *showVersion {
@@ -41,17 +49,56 @@ func main() {
 		os.Exit(0)
 	}
 
-	// Load configuration
-	cfg, err := config.Load()
+	if *configPath == "" {
+		*configPath = os.Getenv("QUIKGIT_CONFIG")
+	}
+
+	// Load configuration: defaults, file, QUIKGIT_* environment, then flags
+	cfg, err := config.LoadFrom(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Override config path if provided
-	if *configPath != "" {
-		cfg.ConfigPath = *configPath
+	if err := configFlags.Apply(cfg); err != nil {
+		log.Fatalf("Invalid configuration flag: %v", err)
+	}
+
+	if args := flag.Args(); len(args) > 0 {
+		switch args[0] {
+		case "config":
+			if err := runConfigCommand(cfg, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "quikgit config: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "install":
+			validateOrExit(cfg)
+			if err := runInstallCommand(cfg, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "quikgit install: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "trust":
+			if err := runTrustCommand(cfg, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "quikgit trust: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		case "workspace":
+			validateOrExit(cfg)
+			if err := runWorkspaceCommand(cfg, args[1:]); err != nil {
+				fmt.Fprintf(os.Stderr, "quikgit workspace: %v\n", err)
+				os.Exit(1)
+			}
+			os.Exit(0)
+		default:
+			fmt.Fprintf(os.Stderr, "Unknown command %q. Run '%s --help' for usage.\n", args[0], os.Args[0])
+			os.Exit(2)
+		}
 	}
 
+	validateOrExit(cfg)
+
 	// Set up debug logging if enabled
 	if *debug {
 		f, err := os.OpenFile("quikgit.log", os.O_RDWR|os.O_CREATE|os.O_APPEND, 0666)
@@ -70,17 +117,51 @@ func main() {
 	}
 }
 
+// validateOrExit refuses to run with an invalid configuration.
+func validateOrExit(cfg *config.Config) {
+	if err := cfg.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration in %s:\n", cfg.ConfigPath)
+		printValidationErrors(cfg, err)
+		fmt.Fprintf(os.Stderr, "Run '%s config validate' after fixing it.\n", os.Args[0])
+		os.Exit(1)
+	}
+}
+
 func showUsage() {
 	fmt.Printf(`%s v%s - GitHub Repository Manager TUI
 
 USAGE:
-    %s [OPTIONS]
+    %s [OPTIONS] [COMMAND]
 
 OPTIONS:
     --version          Show version information
     --help             Show this help message
     --config PATH      Path to configuration file
     --debug            Enable debug logging
+    --<key> VALUE      Override any configuration key for this run
+                       (e.g. --clone.concurrent 5, --install.enabled=false)
+
+COMMANDS:
+    config get KEY         Print the effective value of KEY
+    config set KEY VALUE   Write KEY to the configuration file
+    config list            Show the effective configuration and where each value came from
+    config path            Print the configuration file location
+    config validate        Check the configuration for invalid or unknown keys
+    config schema          Print the JSON Schema for the configuration file
+    install [PATH...]      Install the dependencies of local repositories (default: .)
+      --dry-run            Print the install plan without running anything
+      --skip NAME          Leave out the planned command NAME (repeatable)
+      --trust              Run the scripts of untrusted repositories too
+      --no-scripts         Install with every repository's scripts disabled
+      --env-values FILE    Values for the variables of .env files created from templates
+      --revert-changes     Undo the changes installs make to the working tree
+      --offline            Install from local caches only
+    trust list             Show the owners and repositories trusted to run install scripts
+    trust add OWNER[/REPO] Trust an owner or repository
+    trust remove OWNER[/REPO]
+                           Stop trusting an owner or repository
+    workspace show         Show the .quikgit.yaml workspace for the clone directory
+    workspace sync         Clone missing workspace repositories and install their dependencies
 
 DESCRIPTION:
     QuikGit is a terminal user interface for managing GitHub repositories.
@@ -106,6 +187,10 @@ KEYBOARD SHORTCUTS:
 CONFIGURATION:
     Configuration file is located at ~/.quikgit/config.yaml
     GitHub token is stored securely at ~/.quikgit/token
+    Values are layered: defaults, config file, QUIKGIT_* environment
+    variables (e.g. QUIKGIT_CLONE_CONCURRENT=5), then command-line flags
+    A .quikgit.yaml in the clone directory or a parent defines a team
+    workspace and overrides the clone layout and default organization
 
 SUPPORTED LANGUAGES:
     Go, Node.js, Python, Ruby, Rust, Java, C++, C#, Swift, PHP, Dart
@@ -120,6 +205,9 @@ EXAMPLES:
     # Enable debug logging
     %s --debug
 
+    # Clone with more parallelism and skip installs for this run only
+    %s --clone.concurrent 6 --install.enabled=false
+
 For more information, visit: https://github.com/lvcasx1/quikgit
-`, appName, version, os.Args[0], os.Args[0], os.Args[0], os.Args[0])
+`, appName, version, os.Args[0], os.Args[0], os.Args[0], os.Args[0], os.Args[0])
 }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/lvcasx1/quikgit/internal/trust"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// runTrustCommand implements `quikgit trust list|add|remove`.
+func runTrustCommand(cfg *config.Config, args []string) error {
+	allowlist, err := trust.LoadAllowlist(cfg)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+
+	switch args[0] {
+	case "list":
+		fmt.Printf("Allowlist: %s\n", allowlist.Path())
+		entries := allowlist.Entries()
+		if len(entries) == 0 {
+			fmt.Println("  (empty; your own and your organizations' repositories are always trusted)")
+		}
+		for _, entry := range entries {
+			fmt.Printf("  %s\n", entry)
+		}
+		return nil
+
+	case "add":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: trust add OWNER[/REPO]")
+		}
+		if err := allowlist.Add(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Trusted %s\n", args[1])
+		return nil
+
+	case "remove":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: trust remove OWNER[/REPO]")
+		}
+		removed, err := allowlist.Remove(args[1])
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("%s is not on the allowlist", args[1])
+		}
+		fmt.Printf("No longer trusting %s\n", args[1])
+		return nil
+
+	default:
+		return fmt.Errorf("unknown subcommand %q (list, add, remove)", args[0])
+	}
+}
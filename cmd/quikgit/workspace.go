@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/auth"
+	"github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/internal/history"
+	"github.com/lvcasx1/quikgit/internal/workspace"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// runWorkspaceCommand implements `quikgit workspace show|sync`.
+func runWorkspaceCommand(cfg *config.Config, args []string) error {
+	ws := cfg.Workspace
+	if ws == nil {
+		return fmt.Errorf("no %s found in the clone directory or its parents", config.WorkspaceFileName)
+	}
+
+	if len(args) == 0 {
+		args = []string{"show"}
+	}
+
+	switch args[0] {
+	case "show":
+		fmt.Printf("Workspace:     %s\n", ws.Name)
+		fmt.Printf("File:          %s\n", ws.Path)
+		fmt.Printf("Clone root:    %s\n", ws.CloneRoot())
+		if cfg.Clone.PathTemplate != "" {
+			fmt.Printf("Path template: %s\n", cfg.Clone.PathTemplate)
+		}
+		if ws.DefaultOrg != "" {
+			fmt.Printf("Default org:   %s\n", ws.DefaultOrg)
+		}
+		fmt.Printf("Repositories:  %d\n", len(ws.Repositories))
+		for _, repo := range ws.Repositories {
+			fmt.Printf("  %s\n", repo)
+		}
+		return nil
+
+	case "sync":
+		return syncWorkspace(cfg, ws)
+
+	default:
+		return fmt.Errorf("unknown subcommand %q (show, sync)", args[0])
+	}
+}
+
+// syncWorkspace clones the workspace repositories that are missing and
+// installs their dependencies.
+func syncWorkspace(cfg *config.Config, ws *config.Workspace) error {
+	authManager, err := newAuthManager(cfg)
+	if err != nil {
+		return err
+	}
+	client := github.NewClient(authManager.GetClient())
+
+	ctx := context.Background()
+
+	entries, err := workspace.Resolve(ctx, client, ws, cfg)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for _, entry := range entries {
+		switch {
+		case entry.Error != nil:
+			failed++
+			fmt.Printf("✗ %s: %v\n", entry.FullName, entry.Error)
+		case entry.Present:
+			fmt.Printf("✓ %s already at %s\n", entry.FullName, entry.Path)
+		}
+	}
+
+	missing := workspace.Missing(entries)
+	if len(missing) == 0 {
+		fmt.Println("Workspace is up to date")
+		return failedError(failed)
+	}
+
+	cloneDir, err := cfg.CloneDir()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(cloneDir, 0755); err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	cloneManager := github.NewCloneManager(authManager.GetToken(), cloneDir)
+	cloneManager.SetCreateSubdirs(cfg.Clone.CreateSubdirs)
+	cloneManager.SetPathTemplate(cfg.Clone.PathTemplate)
+
+	run := history.NewRun(history.ActionClone, cloneDir)
+	runEntries := make(map[string]*history.Entry)
+	for _, repo := range missing {
+		entry := &history.Entry{FullName: repo.FullName, Repository: repo, Path: cloneManager.TargetPath(repo)}
+		run.Entries = append(run.Entries, entry)
+		runEntries[repo.FullName] = entry
+	}
+	defer saveRun(run)
+
+	cloneManager.CloneRepositories(ctx, missing, cfg.Clone.Concurrent)
+	go cloneManager.Wait()
+
+	starts := make(map[string]time.Time)
+	var cloned []string
+	for progress := range cloneManager.GetProgressChannel() {
+		if _, ok := starts[progress.Repository]; !ok {
+			starts[progress.Repository] = time.Now()
+		}
+		if !progress.Completed {
+			continue
+		}
+
+		entry := runEntries[progress.Repository]
+		if entry == nil {
+			continue
+		}
+		entry.CloneDuration = time.Since(starts[progress.Repository])
+		entry.Transport = progress.Transport
+
+		if progress.Error != nil {
+			failed++
+			entry.CloneError = progress.Error.Error()
+			fmt.Printf("✗ %s: %v\n", progress.Repository, progress.Error)
+			continue
+		}
+		fmt.Printf("✓ cloned %s to %s\n", progress.Repository, progress.Path)
+		cloned = append(cloned, progress.Path)
+	}
+
+	if !cfg.Install.Enabled || len(cloned) == 0 {
+		return failedError(failed)
+	}
+
+	installManager := newInstallManager(cfg, client)
+	installManager.SetHooks(cfg.Hooks, true)
+	keepLogs(installManager, run)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for progress := range installManager.GetProgressChannel() {
+			if progress.Command != "" && progress.Output == "" && progress.Status != "Running..." {
+				fmt.Printf("  %s: %s\n", progress.Repository, progress.Status)
+			}
+		}
+	}()
+
+	plans := installManager.Plans(cloned)
+	printSandbox(installManager.Sandbox())
+	for _, plan := range plans {
+		printPlan(plan)
+	}
+	holdUntrusted(plans)
+
+	results, _ := installManager.InstallPlans(ctx, plans)
+	<-done
+
+	for i, result := range results {
+		if entry := run.EntryByPath(cloned[i]); entry != nil {
+			entry.Install = history.NewInstallRecord(result)
+		}
+
+		if !printInstallResult(result) {
+			failed++
+		}
+	}
+
+	return failedError(failed)
+}
+
+// newAuthManager authenticates with the saved token, GITHUB_TOKEN or the
+// github.token setting, in that order.
+func newAuthManager(cfg *config.Config) (*auth.AuthManager, error) {
+	authManager := auth.NewAuthManager()
+	if err := authManager.LoadToken(); err == nil && authManager.IsAuthenticated() {
+		return authManager, nil
+	}
+
+	for _, token := range []string{os.Getenv("GITHUB_TOKEN"), cfg.GitHub.Token} {
+		if token == "" {
+			continue
+		}
+		authManager.SetToken(token)
+		if authManager.IsAuthenticated() {
+			return authManager, nil
+		}
+	}
+
+	return nil, fmt.Errorf("not authenticated; run quikgit to set up a token or set GITHUB_TOKEN")
+}
+
+// saveRun records a finished run in the history shown by the TUI.
+func saveRun(run *history.Run) {
+	run.FinishedAt = time.Now()
+
+	store, err := history.NewStore()
+	if err == nil {
+		err = store.Save(run)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "warning: failed to save history: %v\n", err)
+	}
+}
+
+func failedError(failed int) error {
+	if failed > 0 {
+		return fmt.Errorf("%d repositories failed", failed)
+	}
+	return nil
+}
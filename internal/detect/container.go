@@ -0,0 +1,282 @@
+package detect
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of container setups, in the order they are looked for.
+const (
+	ContainerDevcontainer = "devcontainer"
+	ContainerCompose      = "compose"
+	ContainerDockerfile   = "dockerfile"
+)
+
+// devcontainerFiles are where a dev container can be defined.
+var devcontainerFiles = []string{".devcontainer/devcontainer.json", ".devcontainer.json"}
+
+// composeFiles are the Compose file names, preferred first.
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yml", "docker-compose.yaml"}
+
+// ContainerSetup is a repository's development environment defined as a
+// container, which dependencies can be installed in instead of the host.
+type ContainerSetup struct {
+	Kind string
+	File string // Where the setup is defined, relative to the repository root
+
+	// Image is the image a dev container runs; otherwise it is built from
+	// Dockerfile in Context, or the Compose files define it.
+	Image        string
+	Dockerfile   string // Relative to the repository root
+	Context      string // Relative to the repository root
+	ComposeFiles []string
+	Service      string // Compose service a dev container runs in
+
+	// WorkspaceFolder is where the repository is mounted in the container.
+	WorkspaceFolder string
+	// Commands are the dev container's lifecycle commands run once it is
+	// created (onCreate, updateContent and postCreate), as shell command
+	// lines in order.
+	Commands []string
+}
+
+// Name describes the setup, e.g. "Dev Container".
+func (s *ContainerSetup) Name() string {
+	switch s.Kind {
+	case ContainerDevcontainer:
+		return "Dev Container"
+	case ContainerCompose:
+		return "Docker Compose"
+	}
+	return "Dockerfile"
+}
+
+// ReplacesHost reports whether installing in the setup stands in for a host
+// install: only a dev container with lifecycle commands sets up the
+// dependencies of a workspace to develop in. A Compose file or Dockerfile
+// is often the production image, so building it says nothing about the
+// host.
+func (s *ContainerSetup) ReplacesHost() bool {
+	return s.Kind == ContainerDevcontainer && len(s.Commands) > 0
+}
+
+// devcontainerJSON is the part of devcontainer.json used.
+type devcontainerJSON struct {
+	Image string `json:"image"`
+	Build struct {
+		Dockerfile string `json:"dockerfile"`
+		Context    string `json:"context"`
+	} `json:"build"`
+	Dockerfile           string          `json:"dockerFile"` // Older name of build.dockerfile
+	DockerComposeFile    json.RawMessage `json:"dockerComposeFile"`
+	Service              string          `json:"service"`
+	WorkspaceFolder      string          `json:"workspaceFolder"`
+	OnCreateCommand      json.RawMessage `json:"onCreateCommand"`
+	UpdateContentCommand json.RawMessage `json:"updateContentCommand"`
+	PostCreateCommand    json.RawMessage `json:"postCreateCommand"`
+}
+
+// FindContainerSetup returns the container setup of the repository at
+// root: a dev container, else a Compose file, else a Dockerfile. It returns
+// nil when there is none.
+func FindContainerSetup(root string) (*ContainerSetup, error) {
+	for _, name := range devcontainerFiles {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		setup, err := parseDevcontainer(name, data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return setup, nil
+	}
+
+	for _, name := range composeFiles {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			return &ContainerSetup{Kind: ContainerCompose, File: name, ComposeFiles: []string{name}}, nil
+		}
+	}
+
+	if _, err := os.Stat(filepath.Join(root, "Dockerfile")); err == nil {
+		return &ContainerSetup{Kind: ContainerDockerfile, File: "Dockerfile", Dockerfile: "Dockerfile", Context: "."}, nil
+	}
+	return nil, nil
+}
+
+// parseDevcontainer reads the devcontainer.json at name, whose relative
+// paths are relative to its own directory.
+func parseDevcontainer(name string, data []byte) (*ContainerSetup, error) {
+	var config devcontainerJSON
+	if err := json.Unmarshal(stripJSONC(data), &config); err != nil {
+		return nil, err
+	}
+
+	dir := path.Dir(name)
+	setup := &ContainerSetup{
+		Kind:            ContainerDevcontainer,
+		File:            name,
+		Image:           config.Image,
+		Service:         config.Service,
+		WorkspaceFolder: config.WorkspaceFolder,
+	}
+
+	dockerfile := config.Build.Dockerfile
+	if dockerfile == "" {
+		dockerfile = config.Dockerfile
+	}
+	if dockerfile != "" {
+		context := config.Build.Context
+		if context == "" {
+			context = "."
+		}
+		setup.Dockerfile = path.Join(dir, dockerfile)
+		setup.Context = path.Join(dir, context)
+	}
+
+	files, err := stringOrList(config.DockerComposeFile)
+	if err != nil {
+		return nil, fmt.Errorf("dockerComposeFile: %w", err)
+	}
+	for _, file := range files {
+		setup.ComposeFiles = append(setup.ComposeFiles, path.Join(dir, file))
+	}
+
+	if setup.Image == "" && setup.Dockerfile == "" && len(setup.ComposeFiles) == 0 {
+		return nil, fmt.Errorf("no image, build or dockerComposeFile")
+	}
+	if len(setup.ComposeFiles) > 0 && setup.Service == "" {
+		return nil, fmt.Errorf("dockerComposeFile needs a service")
+	}
+
+	for _, raw := range []json.RawMessage{config.OnCreateCommand, config.UpdateContentCommand, config.PostCreateCommand} {
+		commands, err := lifecycleCommands(raw)
+		if err != nil {
+			return nil, err
+		}
+		setup.Commands = append(setup.Commands, commands...)
+	}
+	return setup, nil
+}
+
+// lifecycleCommands returns a lifecycle command as shell command lines: a
+// string is one, an array is a command and its arguments, and an object
+// holds several of those, run in name order.
+func lifecycleCommands(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var line string
+	if err := json.Unmarshal(raw, &line); err == nil {
+		return []string{line}, nil
+	}
+	var args []string
+	if err := json.Unmarshal(raw, &args); err == nil {
+		return []string{ShellJoin(args)}, nil
+	}
+
+	var parallel map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &parallel); err != nil {
+		return nil, fmt.Errorf("lifecycle commands must be a string, an array or an object")
+	}
+	names := make([]string, 0, len(parallel))
+	for name := range parallel {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var lines []string
+	for _, name := range names {
+		commands, err := lifecycleCommands(parallel[name])
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, commands...)
+	}
+	return lines, nil
+}
+
+func stringOrList(raw json.RawMessage) ([]string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+	var one string
+	if err := json.Unmarshal(raw, &one); err == nil {
+		return []string{one}, nil
+	}
+	var list []string
+	err := json.Unmarshal(raw, &list)
+	return list, err
+}
+
+// ShellJoin returns args as a shell command line, quoting the arguments
+// that need it.
+func ShellJoin(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && !strings.ContainsAny(arg, " \t\n\"'\\$`;&|<>()*?[]#~!{}") {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
+// stripJSONC removes the comments and trailing commas devcontainer.json
+// allows, leaving strings untouched.
+func stripJSONC(data []byte) []byte {
+	var out []byte
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := strings.Index(string(data[i+2:]), "*/")
+			if end < 0 {
+				return out
+			}
+			i += end + 3
+		case c == ']' || c == '}':
+			// Drop a comma before the closing bracket
+			j := len(out) - 1
+			for j >= 0 && strings.ContainsRune(" \t\r\n", rune(out[j])) {
+				j--
+			}
+			if j >= 0 && out[j] == ',' {
+				out = append(out[:j], out[j+1:]...)
+			}
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package detect
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestFindContainerSetup(t *testing.T) {
+	tests := []struct {
+		fixture  string
+		kind     string
+		commands []string
+		replaces bool
+	}{
+		{"devcontainer", ContainerDevcontainer, []string{"go mod download"}, true},
+		// A dev container without lifecycle commands installs nothing
+		{"devcontainer-image", ContainerDevcontainer, nil, false},
+		// Production images build beside the host install
+		{"compose", ContainerCompose, nil, false},
+		{"dockerfile", ContainerDockerfile, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			setup, err := FindContainerSetup(filepath.Join("testdata", tt.fixture))
+			if err != nil || setup == nil {
+				t.Fatalf("FindContainerSetup = %v, %v", setup, err)
+			}
+			if setup.Kind != tt.kind {
+				t.Errorf("kind = %q, want %q", setup.Kind, tt.kind)
+			}
+			if !slices.Equal(setup.Commands, tt.commands) {
+				t.Errorf("commands = %q, want %q", setup.Commands, tt.commands)
+			}
+			if setup.ReplacesHost() != tt.replaces {
+				t.Errorf("ReplacesHost = %v, want %v", setup.ReplacesHost(), tt.replaces)
+			}
+		})
+	}
+
+	setup, err := FindContainerSetup(filepath.Join("testdata", "make"))
+	if setup != nil || err != nil {
+		t.Errorf("FindContainerSetup without a container = %v, %v, want nil", setup, err)
+	}
+}
@@ -0,0 +1,199 @@
+package detect
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// loadBuiltinRules loads the built-in detectors only, so that the
+// detectors.d files of whoever runs the tests do not change the results.
+func loadBuiltinRules(t *testing.T) []Rule {
+	t.Helper()
+	rules, err := LoadRules("")
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+	return rules
+}
+
+// detectFixture runs DetectProjects on testdata/fixture and returns the
+// projects as "Name" for the root and "dir: Name" for subdirectories.
+func detectFixture(t *testing.T, fixture string, rules []Rule) ([]string, []*ProjectType) {
+	t.Helper()
+	detector := NewDetector(filepath.Join("testdata", fixture))
+	detector.SetRules(rules)
+	projects, err := detector.DetectProjects()
+	if err != nil {
+		t.Fatalf("DetectProjects(%s): %v", fixture, err)
+	}
+
+	names := make([]string, len(projects))
+	for i, project := range projects {
+		names[i] = project.Name
+		if project.Dir != "" {
+			names[i] = project.Dir + ": " + project.Name
+		}
+	}
+	return names, projects
+}
+
+// joinCommand returns cmd as the command line it runs.
+func joinCommand(cmd Command) string {
+	return strings.Join(append([]string{cmd.Command}, cmd.Args...), " ")
+}
+
+func TestDetectProjects(t *testing.T) {
+	rules := loadBuiltinRules(t)
+
+	tests := []struct {
+		fixture  string
+		projects []string
+		primary  string
+		command  string // First command of the primary project
+	}{
+		{"elixir", []string{"Elixir (Mix)"}, "Elixir (Mix)", "mix deps.get"},
+		{"haskell-stack", []string{"Haskell (Stack)", "Haskell (Cabal)"}, "Haskell (Stack)", "stack build --only-dependencies"},
+		{"haskell-cabal", []string{"Haskell (Cabal)"}, "Haskell (Cabal)", "cabal update"},
+		{"zig", []string{"Zig"}, "Zig", "zig build --fetch"},
+		{"nix-flake", []string{"Nix (flake)"}, "Nix (flake)", "nix develop --command true"},
+		{"nix-shell", []string{"Nix (shell.nix)"}, "Nix (shell.nix)", "nix-shell --run true"},
+		{"deno", []string{"Deno"}, "Deno", "deno install"},
+		{"bazel", []string{"Bazel"}, "Bazel", "bazel fetch //..."},
+		{"ocaml", []string{"OCaml (dune)"}, "OCaml (dune)", "opam install . --deps-only --yes"},
+		{"lua", []string{"Lua (LuaRocks)"}, "Lua (LuaRocks)", "luarocks make --only-deps --local"},
+		{"julia", []string{"Julia"}, "Julia", "julia --project=. -e using Pkg; Pkg.instantiate()"},
+		{"crystal", []string{"Crystal (Shards)"}, "Crystal (Shards)", "shards install"},
+		{"nim", []string{"Nim (Nimble)"}, "Nim (Nimble)", "nimble install --depsOnly --accept"},
+
+		// The Make fallback only applies when nothing else matched
+		{"make", []string{"Make"}, "Make", "make"},
+		{"make-setup", []string{"Setup (make setup)"}, "Setup (make setup)", "make setup"},
+		{"go-make", []string{"Go"}, "Go", "go mod download"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fixture, func(t *testing.T) {
+			names, projects := detectFixture(t, tt.fixture, rules)
+			if !slices.Equal(names, tt.projects) {
+				t.Fatalf("projects = %q, want %q", names, tt.projects)
+			}
+
+			primary := Primary(projects)
+			if primary.Name != tt.primary {
+				t.Errorf("primary = %q, want %q", primary.Name, tt.primary)
+			}
+			if len(primary.Commands) == 0 {
+				t.Fatalf("primary %q has no commands", primary.Name)
+			}
+			if got := joinCommand(primary.Commands[0]); got != tt.command {
+				t.Errorf("command = %q, want %q", got, tt.command)
+			}
+		})
+	}
+}
+
+func TestDetectProjectsWorkspaces(t *testing.T) {
+	rules := loadBuiltinRules(t)
+
+	tests := []struct {
+		name     string
+		fixture  string
+		projects []string
+	}{
+		// Packages of an npm workspace install with the root
+		{"npm workspaces", "monorepo-npm", []string{"Node.js"}},
+		// Crates of a Cargo workspace build with the root
+		{"cargo workspace", "monorepo-cargo", []string{"Rust"}},
+		// Modules a go.work uses install with it, others on their own
+		{"go workspace", "monorepo-gowork", []string{"Go Workspace", "tools: Go"}},
+		// Unrelated projects each install, node_modules is never walked
+		{"mixed", "monorepo-mixed", []string{"Node.js", "api: Go", "web: Node.js"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, _ := detectFixture(t, tt.fixture, rules)
+			if !slices.Equal(names, tt.projects) {
+				t.Errorf("projects = %q, want %q", names, tt.projects)
+			}
+		})
+	}
+}
+
+func TestDetectGoWorkspace(t *testing.T) {
+	_, projects := detectFixture(t, "monorepo-gowork", loadBuiltinRules(t))
+
+	// Every module the go.work uses downloads in its own directory, and
+	// nothing rewrites their go.mod files
+	var commands []string
+	for _, cmd := range projects[0].Commands {
+		commands = append(commands, joinCommand(cmd))
+	}
+	want := []string{"go -C lib mod download", "go -C svc mod download", "go build ./..."}
+	if !slices.Equal(commands, want) {
+		t.Errorf("Go Workspace commands = %q, want %q", commands, want)
+	}
+}
+
+func TestDetectProjectsDepth(t *testing.T) {
+	detector := NewDetector(filepath.Join("testdata", "monorepo-mixed"))
+	detector.SetRules(loadBuiltinRules(t))
+	detector.SetMaxDepth(0)
+
+	projects, err := detector.DetectProjects()
+	if err != nil {
+		t.Fatalf("DetectProjects: %v", err)
+	}
+	for _, project := range projects {
+		if project.Dir != "" {
+			t.Errorf("project %q found in %s with a depth of 0", project.Name, project.Dir)
+		}
+	}
+}
+
+func TestDetectorOverrides(t *testing.T) {
+	rules, err := LoadRules(filepath.Join("testdata", "detectors.d"))
+	if err != nil {
+		t.Fatalf("LoadRules: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		fixture  string
+		projects []string
+		command  string
+	}{
+		{"replaced", "monorepo-cargo", []string{"Rust"}, "cargo fetch"},
+		{"disabled", "monorepo-mixed", []string{"Node.js", "web: Node.js"}, ""},
+		{"added", "gleam", []string{"Gleam"}, "gleam deps download"},
+		{"untouched", "monorepo-npm", []string{"Node.js"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			names, projects := detectFixture(t, tt.fixture, rules)
+			if !slices.Equal(names, tt.projects) {
+				t.Fatalf("projects = %q, want %q", names, tt.projects)
+			}
+			if tt.command == "" {
+				return
+			}
+			if got := joinCommand(projects[0].Commands[0]); got != tt.command {
+				t.Errorf("command = %q, want %q", got, tt.command)
+			}
+		})
+	}
+
+	// The replaced rule records the file it came from, the disabled one is
+	// gone
+	for _, rule := range rules {
+		if rule.Name == "Rust" && !strings.HasSuffix(rule.Source, "10-overrides.yaml") {
+			t.Errorf("Rust source = %q, want the override file", rule.Source)
+		}
+		if rule.Name == "Go" {
+			t.Errorf("disabled Go rule still loaded")
+		}
+	}
+}
@@ -0,0 +1,212 @@
+package detect
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// envTemplateSuffixes mark a file as the template of the environment file
+// named without the suffix, such as .env.example for .env or
+// .env.local.sample for .env.local.
+var envTemplateSuffixes = []string{".example", ".sample", ".template", ".dist"}
+
+// EnvTemplate is an environment file template shipped by a repository,
+// whose target file does not exist yet.
+type EnvTemplate struct {
+	Dir    string // Relative to the repository root, empty for the root
+	Name   string // Template file name, e.g. ".env.example"
+	Target string // File to create, e.g. ".env"
+	Vars   []EnvVar
+}
+
+// EnvVar is a variable of an environment file.
+type EnvVar struct {
+	Key     string
+	Value   string // Default value, unquoted
+	Comment string // Comment lines right above the variable
+}
+
+// Empty returns the keys of the template's variables without a default.
+func (t EnvTemplate) Empty() []string {
+	var keys []string
+	for _, v := range t.Vars {
+		if v.Value == "" {
+			keys = append(keys, v.Key)
+		}
+	}
+	return keys
+}
+
+// Path returns the template's path relative to the repository root.
+func (t EnvTemplate) Path() string {
+	return filepath.ToSlash(filepath.Join(t.Dir, t.Name))
+}
+
+// TargetPath returns the target's path relative to the repository root.
+func (t EnvTemplate) TargetPath() string {
+	return filepath.ToSlash(filepath.Join(t.Dir, t.Target))
+}
+
+// FindEnvTemplates returns the environment file templates in dir, a
+// directory of the repository at root, whose target file is missing.
+// Existing files are never replaced, so templates with a target are left
+// out.
+func FindEnvTemplates(root, dir string) ([]EnvTemplate, error) {
+	entries, err := os.ReadDir(filepath.Join(root, filepath.FromSlash(dir)))
+	if err != nil {
+		return nil, err
+	}
+
+	var templates []EnvTemplate
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, ".env") {
+			continue
+		}
+		for _, suffix := range envTemplateSuffixes {
+			target, found := strings.CutSuffix(name, suffix)
+			if !found {
+				continue
+			}
+			path := filepath.Join(root, filepath.FromSlash(dir))
+			if _, err := os.Lstat(filepath.Join(path, target)); !os.IsNotExist(err) {
+				break
+			}
+			vars, err := ParseEnvFile(filepath.Join(path, name))
+			if err != nil {
+				return templates, err
+			}
+			templates = append(templates, EnvTemplate{Dir: dir, Name: name, Target: target, Vars: vars})
+			break
+		}
+	}
+
+	sort.Slice(templates, func(i, j int) bool { return templates[i].Name < templates[j].Name })
+	return templates, nil
+}
+
+var envLine = regexp.MustCompile(`^(?:export\s+)?([A-Za-z_][A-Za-z0-9_.]*)\s*=\s*(.*)$`)
+
+// envReference matches a value that only references a variable, with no
+// default: ${NAME}, ${NAME:-} or ${NAME-}.
+var envReference = regexp.MustCompile(`^\$\{[A-Za-z_][A-Za-z0-9_]*(:?-)?\}$`)
+
+// ParseEnvFile reads the KEY=value lines of an environment file. Values may
+// be quoted; unquoted values end at a " #" comment. A value that only
+// references a variable without a default, such as ${API_KEY}, counts as
+// empty; ${NAME:-default} is kept for the tools that expand it.
+func ParseEnvFile(path string) ([]EnvVar, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var vars []EnvVar
+	var comment []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "":
+			comment = nil
+			continue
+		case strings.HasPrefix(line, "#"):
+			comment = append(comment, strings.TrimSpace(strings.TrimPrefix(line, "#")))
+			continue
+		}
+
+		match := envLine.FindStringSubmatch(line)
+		if match == nil {
+			comment = nil
+			continue
+		}
+		vars = append(vars, EnvVar{Key: match[1], Value: envValue(match[2]), Comment: strings.Join(comment, " ")})
+		comment = nil
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", filepath.Base(path), err)
+	}
+	return vars, nil
+}
+
+func envValue(raw string) string {
+	if len(raw) >= 2 && raw[0] == '\'' {
+		if end := strings.IndexByte(raw[1:], '\''); end >= 0 {
+			return raw[1 : end+1]
+		}
+	}
+	if len(raw) >= 2 && raw[0] == '"' {
+		// Escaped quotes do not end the value
+		var value strings.Builder
+		for i := 1; i < len(raw); i++ {
+			switch c := raw[i]; {
+			case c == '"':
+				return value.String()
+			case c == '\\' && i+1 < len(raw):
+				i++
+				switch raw[i] {
+				case 'n':
+					value.WriteByte('\n')
+				case '"', '\\':
+					value.WriteByte(raw[i])
+				default:
+					value.WriteByte('\\')
+					value.WriteByte(raw[i])
+				}
+			default:
+				value.WriteByte(c)
+			}
+		}
+	}
+	if i := strings.Index(raw, " #"); i >= 0 {
+		raw = raw[:i]
+	}
+	raw = strings.TrimSpace(raw)
+	if envReference.MatchString(raw) {
+		return ""
+	}
+	return raw
+}
+
+// QuoteEnvValue returns value as written in an environment file, quoted
+// when it contains spaces, quotes, # or newlines.
+func QuoteEnvValue(value string) string {
+	if !strings.ContainsAny(value, " \t\"'#\n\\$`") {
+		return value
+	}
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(value) + `"`
+}
+
+// RenderEnvFile returns the template at path with the given variables set
+// to their value, keeping its comments, order and other lines as they are.
+func RenderEnvFile(path string, values map[string]string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.Split(string(data), "\n")
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		match := envLine.FindStringSubmatch(trimmed)
+		if match == nil {
+			continue
+		}
+		value, ok := values[match[1]]
+		if !ok {
+			continue
+		}
+		prefix := ""
+		if !strings.HasPrefix(trimmed, match[1]) {
+			prefix = "export "
+		}
+		lines[i] = prefix + match[1] + "=" + QuoteEnvValue(value)
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
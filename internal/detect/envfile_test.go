@@ -0,0 +1,89 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestParseEnvFile(t *testing.T) {
+	vars, err := ParseEnvFile(filepath.Join("testdata", "env", ".env.example"))
+	if err != nil {
+		t.Fatalf("ParseEnvFile: %v", err)
+	}
+
+	want := []EnvVar{
+		{Key: "APP_NAME", Value: "Demo App", Comment: "Application settings"},
+		{Key: "APP_ENV", Value: "development"},
+		{Key: "SECRET_KEY", Value: "", Comment: "Secret key, generate one with openssl rand -hex 32"},
+		{Key: "API_KEY", Value: ""},
+		{Key: "DATABASE_URL", Value: "${DATABASE_URL:-postgres://localhost/demo}"},
+		{Key: "GREETING", Value: `hello "world" # not a comment`},
+		{Key: "ESCAPED", Value: "line one\nline \"two\""},
+	}
+	if !slices.Equal(vars, want) {
+		t.Errorf("vars =\n%q\nwant\n%q", vars, want)
+	}
+
+	template := EnvTemplate{Vars: vars}
+	if empty := template.Empty(); !slices.Equal(empty, []string{"SECRET_KEY", "API_KEY"}) {
+		t.Errorf("Empty = %q, want SECRET_KEY and API_KEY", empty)
+	}
+}
+
+func TestQuoteEnvValue(t *testing.T) {
+	tests := map[string]string{
+		"plain":       "plain",
+		"two words":   `"two words"`,
+		`say "hi"`:    `"say \"hi\""`,
+		"a#b":         `"a#b"`,
+		"line\nbreak": `"line\nbreak"`,
+		`back\slash`:  `"back\\slash"`,
+		"$HOME":       `"$HOME"`,
+		"":            "",
+	}
+	for value, want := range tests {
+		if got := QuoteEnvValue(value); got != want {
+			t.Errorf("QuoteEnvValue(%q) = %s, want %s", value, got, want)
+		}
+	}
+}
+
+func TestRenderEnvFile(t *testing.T) {
+	path := filepath.Join("testdata", "env", ".env.example")
+	data, err := RenderEnvFile(path, map[string]string{
+		"APP_ENV":    "staging",
+		"SECRET_KEY": `s3cr3t "quoted"`,
+		"API_KEY":    "abc",
+	})
+	if err != nil {
+		t.Fatalf("RenderEnvFile: %v", err)
+	}
+
+	original, _ := os.ReadFile(path)
+	want := strings.NewReplacer(
+		"export APP_ENV=development # local, staging or production", "export APP_ENV=staging",
+		"SECRET_KEY=\n", `SECRET_KEY="s3cr3t \"quoted\""`+"\n",
+		"API_KEY=${API_KEY}", "API_KEY=abc",
+	).Replace(string(original))
+	if string(data) != want {
+		t.Errorf("rendered =\n%s\nwant\n%s", data, want)
+	}
+
+	// What is written reads back as the values given
+	rendered := filepath.Join(t.TempDir(), ".env")
+	if err := os.WriteFile(rendered, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	vars, err := ParseEnvFile(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range vars {
+		if v.Key == "SECRET_KEY" && v.Value != `s3cr3t "quoted"` {
+			t.Errorf("SECRET_KEY reads back as %q", v.Value)
+		}
+	}
+}
@@ -4,15 +4,26 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 )
 
 type ProjectType struct {
 	Name        string
 	Language    string
+	Ecosystem   string
 	Files       []string
 	Commands    []Command
 	Description string
+	Priority    int
+	// Dir is the project's directory relative to the repository root,
+	// slash-separated, or empty for the root itself.
+	Dir string
+	// PackageManager is set for JavaScript and TypeScript projects.
+	PackageManager string
+	// VirtualEnv is the environment Python dependencies are installed into,
+	// relative to Dir.
+	VirtualEnv string
 }
 
 type Command struct {
@@ -21,578 +32,66 @@ type Command struct {
 	Args        []string
 	Description string
 	Required    bool
+	Env         []string // Added to the environment, as KEY=value
+	// Class is "network" or "build" when the detector declares what the
+	// command mostly does; otherwise the installer guesses.
+	Class string
+	// Verify marks a command checking the install, such as a build, which
+	// only runs when verification is enabled.
+	Verify bool
+	// NoScripts runs the command without executing code from the
+	// repository; nil when the command cannot avoid it.
+	NoScripts *NoScripts
+	// Offline runs the command from local caches only; nil when the
+	// command has no way to avoid the network.
+	Offline *Offline
 }
 
-// Framework detection based on package.json dependencies
-var JSFrameworkDetectors = []ProjectType{
-	{
-		Name:     "Next.js (Yarn Package)",
-		Language: "JavaScript",
-		Files:    []string{"package.json", "yarn.lock"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-next",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Next.js dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Next.js React framework with Yarn (detected via package.json)",
-	},
-	{
-		Name:     "Next.js (Package)",
-		Language: "JavaScript",
-		Files:    []string{"package.json"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-next",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Next.js dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Next.js React framework (detected via package.json)",
-	},
-	{
-		Name:     "Vue.js (Yarn Package)",
-		Language: "JavaScript",
-		Files:    []string{"package.json", "yarn.lock"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-vue",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Vue.js dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Vue.js framework with Yarn (detected via package.json)",
-	},
-	{
-		Name:     "Vue.js (Package)",
-		Language: "JavaScript",
-		Files:    []string{"package.json"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-vue",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Vue.js dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Vue.js framework (detected via package.json)",
-	},
-	{
-		Name:     "Angular (Yarn Package)",
-		Language: "TypeScript",
-		Files:    []string{"package.json", "yarn.lock"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-angular",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Angular dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Angular framework with Yarn (detected via package.json)",
-	},
-	{
-		Name:     "Angular (Package)",
-		Language: "TypeScript",
-		Files:    []string{"package.json"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-angular",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Angular dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Angular framework (detected via package.json)",
-	},
-	{
-		Name:     "React (Yarn Package)",
-		Language: "JavaScript",
-		Files:    []string{"package.json", "yarn.lock"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-react",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install React dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "React framework with Yarn (detected via package.json)",
-	},
-	{
-		Name:     "React (Package)",
-		Language: "JavaScript",
-		Files:    []string{"package.json"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-react",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install React dependencies",
-				Required:    true,
-			},
-		},
-		Description: "React framework (detected via package.json)",
-	},
+// NoScripts describes how to run a command with the repository's scripts,
+// such as npm lifecycle scripts or build backends, disabled.
+type NoScripts struct {
+	Args []string `yaml:"args,omitempty"` // Replace the command's arguments when set
+	Env  []string `yaml:"env,omitempty"`  // Added to the environment, as KEY=value
 }
 
-var SupportedProjects = []ProjectType{
-	{
-		Name:     "Go",
-		Language: "Go",
-		Files:    []string{"go.mod", "go.sum", "*.go"},
-		Commands: []Command{
-			{
-				Name:        "go-mod-tidy",
-				Command:     "go",
-				Args:        []string{"mod", "tidy"},
-				Description: "Download and organize dependencies",
-				Required:    true,
-			},
-			{
-				Name:        "go-mod-download",
-				Command:     "go",
-				Args:        []string{"mod", "download"},
-				Description: "Download dependencies to cache",
-				Required:    false,
-			},
-		},
-		Description: "Go module project",
-	},
-	{
-		Name:     "Node.js (npm)",
-		Language: "JavaScript",
-		Files:    []string{"package.json", "package-lock.json"},
-		Commands: []Command{
-			{
-				Name:        "npm-install",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Node.js dependencies via npm",
-				Required:    true,
-			},
-		},
-		Description: "Node.js project with npm",
-	},
-	{
-		Name:     "Node.js (yarn)",
-		Language: "JavaScript",
-		Files:    []string{"package.json", "yarn.lock"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Node.js dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Node.js project with Yarn",
-	},
-	{
-		Name:     "Python (pip)",
-		Language: "Python",
-		Files:    []string{"requirements.txt"},
-		Commands: []Command{
-			{
-				Name:        "pip-install",
-				Command:     "pip",
-				Args:        []string{"install", "-r", "requirements.txt"},
-				Description: "Install Python dependencies via pip",
-				Required:    true,
-			},
-		},
-		Description: "Python project with requirements.txt",
-	},
-	{
-		Name:     "Python (Pipenv)",
-		Language: "Python",
-		Files:    []string{"Pipfile"},
-		Commands: []Command{
-			{
-				Name:        "pipenv-install",
-				Command:     "pipenv",
-				Args:        []string{"install"},
-				Description: "Install Python dependencies via Pipenv",
-				Required:    true,
-			},
-		},
-		Description: "Python project with Pipenv",
-	},
-	{
-		Name:     "Python (Poetry)",
-		Language: "Python",
-		Files:    []string{"pyproject.toml", "poetry.lock"},
-		Commands: []Command{
-			{
-				Name:        "poetry-install",
-				Command:     "poetry",
-				Args:        []string{"install"},
-				Description: "Install Python dependencies via Poetry",
-				Required:    true,
-			},
-		},
-		Description: "Python project with Poetry",
-	},
-	{
-		Name:     "Ruby (Bundler)",
-		Language: "Ruby",
-		Files:    []string{"Gemfile"},
-		Commands: []Command{
-			{
-				Name:        "bundle-install",
-				Command:     "bundle",
-				Args:        []string{"install"},
-				Description: "Install Ruby gems via Bundler",
-				Required:    true,
-			},
-		},
-		Description: "Ruby project with Bundler",
-	},
-	{
-		Name:     "Rust",
-		Language: "Rust",
-		Files:    []string{"Cargo.toml"},
-		Commands: []Command{
-			{
-				Name:        "cargo-build",
-				Command:     "cargo",
-				Args:        []string{"build"},
-				Description: "Build Rust project and download dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Rust project with Cargo",
-	},
-	{
-		Name:     "PHP (Composer)",
-		Language: "PHP",
-		Files:    []string{"composer.json"},
-		Commands: []Command{
-			{
-				Name:        "composer-install",
-				Command:     "composer",
-				Args:        []string{"install"},
-				Description: "Install PHP dependencies via Composer",
-				Required:    true,
-			},
-		},
-		Description: "PHP project with Composer",
-	},
-	{
-		Name:     "Java (Maven)",
-		Language: "Java",
-		Files:    []string{"pom.xml"},
-		Commands: []Command{
-			{
-				Name:        "maven-install",
-				Command:     "mvn",
-				Args:        []string{"install"},
-				Description: "Build Java project and install dependencies via Maven",
-				Required:    true,
-			},
-		},
-		Description: "Java project with Maven",
-	},
-	{
-		Name:     "Java (Gradle)",
-		Language: "Java",
-		Files:    []string{"build.gradle", "build.gradle.kts"},
-		Commands: []Command{
-			{
-				Name:        "gradle-build",
-				Command:     "gradle",
-				Args:        []string{"build"},
-				Description: "Build Java project via Gradle",
-				Required:    true,
-			},
-		},
-		Description: "Java project with Gradle",
-	},
-	{
-		Name:     "C++ (CMake)",
-		Language: "C++",
-		Files:    []string{"CMakeLists.txt"},
-		Commands: []Command{
-			{
-				Name:        "cmake-build",
-				Command:     "cmake",
-				Args:        []string{".", "-B", "build"},
-				Description: "Configure CMake build",
-				Required:    true,
-			},
-			{
-				Name:        "make-build",
-				Command:     "make",
-				Args:        []string{"-C", "build"},
-				Description: "Build C++ project",
-				Required:    false,
-			},
-		},
-		Description: "C++ project with CMake",
-	},
-	{
-		Name:     "C# (.NET)",
-		Language: "C#",
-		Files:    []string{"*.csproj", "*.sln"},
-		Commands: []Command{
-			{
-				Name:        "dotnet-restore",
-				Command:     "dotnet",
-				Args:        []string{"restore"},
-				Description: "Restore .NET dependencies",
-				Required:    true,
-			},
-			{
-				Name:        "dotnet-build",
-				Command:     "dotnet",
-				Args:        []string{"build"},
-				Description: "Build .NET project",
-				Required:    false,
-			},
-		},
-		Description: ".NET project",
-	},
-	{
-		Name:     "Swift",
-		Language: "Swift",
-		Files:    []string{"Package.swift"},
-		Commands: []Command{
-			{
-				Name:        "swift-build",
-				Command:     "swift",
-				Args:        []string{"build"},
-				Description: "Build Swift package",
-				Required:    true,
-			},
-		},
-		Description: "Swift package",
-	},
-	{
-		Name:     "Dart (Flutter)",
-		Language: "Dart",
-		Files:    []string{"pubspec.yaml"},
-		Commands: []Command{
-			{
-				Name:        "flutter-pub-get",
-				Command:     "flutter",
-				Args:        []string{"pub", "get"},
-				Description: "Get Flutter dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Flutter project",
-	},
-	{
-		Name:     "Next.js",
-		Language: "JavaScript",
-		Files:    []string{"next.config.js", "next.config.mjs", "next.config.ts", "pages/", "app/"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-next",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Next.js dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Next.js React framework",
-	},
-	{
-		Name:     "Next.js (Yarn)",
-		Language: "JavaScript",
-		Files:    []string{"yarn.lock", "next.config.js", "next.config.mjs", "next.config.ts", "pages/", "app/"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-next",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Next.js dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Next.js React framework with Yarn",
-	},
-	{
-		Name:     "Vue.js",
-		Language: "JavaScript",
-		Files:    []string{"vue.config.js", "vite.config.js", "src/main.js", "src/App.vue"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-vue",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Vue.js dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Vue.js framework",
-	},
-	{
-		Name:     "Vue.js (Yarn)",
-		Language: "JavaScript",
-		Files:    []string{"yarn.lock", "vue.config.js", "vite.config.js", "src/main.js", "src/App.vue"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-vue",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Vue.js dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Vue.js framework with Yarn",
-	},
-	{
-		Name:     "Angular",
-		Language: "TypeScript",
-		Files:    []string{"angular.json", "src/app/app.module.ts"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-angular",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Angular dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Angular framework",
-	},
-	{
-		Name:     "Angular (Yarn)",
-		Language: "TypeScript",
-		Files:    []string{"yarn.lock", "angular.json", "src/app/app.module.ts"},
-		Commands: []Command{
-			{
-				Name:        "yarn-install-angular",
-				Command:     "yarn",
-				Args:        []string{"install"},
-				Description: "Install Angular dependencies via Yarn",
-				Required:    true,
-			},
-		},
-		Description: "Angular framework with Yarn",
-	},
-	{
-		Name:     "Svelte",
-		Language: "JavaScript",
-		Files:    []string{"svelte.config.js", "src/App.svelte"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-svelte",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Svelte dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Svelte framework",
-	},
-	{
-		Name:     "SvelteKit",
-		Language: "JavaScript",
-		Files:    []string{"svelte.config.js", "src/app.html"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-sveltekit",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install SvelteKit dependencies",
-				Required:    true,
-			},
-		},
-		Description: "SvelteKit framework",
-	},
-	{
-		Name:     "Nuxt.js",
-		Language: "JavaScript",
-		Files:    []string{"nuxt.config.js", "nuxt.config.ts"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-nuxt",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Nuxt.js dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Nuxt.js Vue framework",
-	},
-	{
-		Name:     "Gatsby",
-		Language: "JavaScript",
-		Files:    []string{"gatsby-config.js", "gatsby-config.ts"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-gatsby",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Gatsby dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Gatsby React framework",
-	},
-	{
-		Name:     "Vite",
-		Language: "JavaScript",
-		Files:    []string{"vite.config.js", "vite.config.ts"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-vite",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Vite dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Vite build tool",
-	},
-	{
-		Name:     "Astro",
-		Language: "JavaScript",
-		Files:    []string{"astro.config.mjs", "astro.config.js"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-astro",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Astro dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Astro static site generator",
-	},
-	{
-		Name:     "Remix",
-		Language: "JavaScript",
-		Files:    []string{"remix.config.js", "app/entry.client.tsx"},
-		Commands: []Command{
-			{
-				Name:        "npm-install-remix",
-				Command:     "npm",
-				Args:        []string{"install"},
-				Description: "Install Remix dependencies",
-				Required:    true,
-			},
-		},
-		Description: "Remix React framework",
-	},
+// WithoutScripts returns the command with scripts disabled. ok is false when
+// the command always runs code from the repository.
+func (c Command) WithoutScripts() (cmd Command, ok bool) {
+	if c.NoScripts == nil {
+		return c, false
+	}
+	cmd = c
+	if len(c.NoScripts.Args) > 0 {
+		cmd.Args = c.NoScripts.Args
+	}
+	cmd.Env = append(append([]string(nil), c.Env...), c.NoScripts.Env...)
+	return cmd, true
+}
+
+// Offline describes how to run a command without the network, resolving
+// dependencies from local caches.
+type Offline struct {
+	Args []string `yaml:"args,omitempty"` // Appended to the command's arguments
+	Env  []string `yaml:"env,omitempty"`  // Added to the environment, as KEY=value
+}
+
+// WithOffline returns the command run from local caches. ok is false when
+// the command cannot avoid the network.
+func (c Command) WithOffline() (cmd Command, ok bool) {
+	if c.Offline == nil {
+		return c, false
+	}
+	cmd = c
+	cmd.Args = append(append([]string(nil), c.Args...), c.Offline.Args...)
+	cmd.Env = append(append([]string(nil), c.Env...), c.Offline.Env...)
+	return cmd, true
 }
 
 type Detector struct {
 	projectPath string
+	maxDepth    int
+	rules       []Rule
+	rulesErr    error
 }
 
 type PackageJSON struct {
@@ -601,8 +100,23 @@ type PackageJSON struct {
 	Scripts         map[string]string `json:"scripts"`
 }
 
+// NewDetector uses the built-in detectors together with the user's
+// detectors.d files.
 func NewDetector(projectPath string) *Detector {
-	return &Detector{projectPath: projectPath}
+	rules, err := Rules()
+	return &Detector{projectPath: projectPath, maxDepth: DefaultMaxDepth, rules: rules, rulesErr: err}
+}
+
+// SetRules replaces the detectors used, for example with LoadRules output.
+func (d *Detector) SetRules(rules []Rule) {
+	d.rules = rules
+	d.rulesErr = nil
+}
+
+// SetMaxDepth limits how many directory levels below the root are searched
+// for nested projects; zero only examines the root.
+func (d *Detector) SetMaxDepth(depth int) {
+	d.maxDepth = depth
 }
 
 func (d *Detector) parsePackageJSON() (*PackageJSON, error) {
@@ -638,133 +152,169 @@ func (d *Detector) hasPackageDependency(depNames ...string) bool {
 	return false
 }
 
+// DetectProjects returns every project type matched in the repository root
+// and in subdirectories holding a manifest, each with its Dir set. Nested
+// projects that belong to a workspace declared higher up are left out.
 func (d *Detector) DetectProjects() ([]*ProjectType, error) {
+	if d.rulesErr != nil {
+		return nil, d.rulesErr
+	}
+
 	var detected []*ProjectType
+	workspaces := make(map[string][]string)
+	// Go modules used by a go.work, which installs them all
+	goWork := make(map[string]string)
+
+	for _, dir := range d.projectDirs() {
+		sub := &Detector{projectPath: filepath.Join(d.projectPath, filepath.FromSlash(dir)), rules: d.rules}
 
-	// First, check for JavaScript frameworks via package.json
-	for _, project := range JSFrameworkDetectors {
-		if d.matchesJSFramework(project) {
-			projectCopy := project
-			detected = append(detected, &projectCopy)
+		projects, err := sub.detectHere()
+		if err != nil {
+			return nil, err
+		}
+		for _, project := range projects {
+			if coveredByWorkspace(dir, project.Language, workspaces) {
+				continue
+			}
+			if work, ok := goWork[dir]; ok && work != dir && project.Language == "Go" {
+				continue
+			}
+			project.Dir = dir
+			detected = append(detected, project)
 		}
-	}
 
-	// Then check regular file-based detection
-	for _, project := range SupportedProjects {
-		if d.matchesProject(project) {
-			projectCopy := project
-			detected = append(detected, &projectCopy)
+		if languages := workspaceLanguages(sub.projectPath); len(languages) > 0 {
+			workspaces[dir] = languages
+		}
+		for _, module := range goWorkModules(d.projectPath, dir) {
+			goWork[module] = dir
 		}
 	}
 
 	return detected, nil
 }
 
-func (d *Detector) matchesJSFramework(project ProjectType) bool {
-	// Check if package.json exists first
-	if !d.hasMatchingFiles("package.json") {
-		return false
-	}
+// detectHere matches the detectors against the detector's directory only.
+func (d *Detector) detectHere() ([]*ProjectType, error) {
+	var detected, fallbacks []*ProjectType
+	pm, hasPackageJSON := d.ResolvePackageManager()
+	setup, hasSetup := d.ResolveSetup()
+
+	for _, rule := range d.rules {
+		if !d.matches(rule) {
+			continue
+		}
+
+		project := rule.ProjectType()
+
+		// Node.js projects without commands of their own install through the
+		// resolved package manager, and need a package.json to do so
+		if project.Ecosystem == "Node.js" && len(project.Commands) == 0 {
+			if !hasPackageJSON {
+				continue
+			}
+			project.PackageManager = pm.String()
+			project.Commands = []Command{pm.InstallCommand()}
+			// A setup script runs after the install, unless the directory
+			// has a setup entry point of its own that would run it anyway
+			if script, ok := d.SetupScript(pm); ok && !hasSetup {
+				project.Commands = append(project.Commands, pm.RunCommand(script))
+			}
+		}
 
-	// Determine if this is a yarn or npm project
-	hasYarnLock := d.hasMatchingFiles("yarn.lock")
-
-	// Check for specific framework dependencies
-	switch project.Name {
-	case "Next.js (Yarn Package)":
-		return hasYarnLock && d.hasPackageDependency("next")
-	case "Next.js (Package)":
-		return !hasYarnLock && d.hasPackageDependency("next")
-	case "Vue.js (Yarn Package)":
-		return hasYarnLock && d.hasPackageDependency("vue", "@vue/cli", "nuxt")
-	case "Vue.js (Package)":
-		return !hasYarnLock && d.hasPackageDependency("vue", "@vue/cli", "nuxt")
-	case "Angular (Yarn Package)":
-		return hasYarnLock && d.hasPackageDependency("@angular/core", "@angular/cli")
-	case "Angular (Package)":
-		return !hasYarnLock && d.hasPackageDependency("@angular/core", "@angular/cli")
-	case "React (Yarn Package)":
-		return hasYarnLock && d.hasPackageDependency("react") && !d.hasPackageDependency("next", "gatsby", "@remix-run/react")
-	case "React (Package)":
-		return !hasYarnLock && d.hasPackageDependency("react") && !d.hasPackageDependency("next", "gatsby", "@remix-run/react")
+		// Go workspaces download the dependencies of every module they use,
+		// leaving the modules' go.mod and go.sum as they are
+		if project.Ecosystem == "Go" && slices.Contains(rule.Manifests, "go.work") {
+			project.Commands = append(d.goWorkDownloads(), project.Commands...)
+		}
+
+		// Likewise Python projects install into a virtual environment
+		if project.Ecosystem == "Python" && len(project.Commands) == 0 {
+			python, ok := d.ResolvePythonInstall()
+			if !ok {
+				continue
+			}
+			project.VirtualEnv = python.VirtualEnv
+			project.Commands = python.Commands
+		}
+
+		if rule.Fallback {
+			fallbacks = append(fallbacks, &project)
+			continue
+		}
+		detected = append(detected, &project)
 	}
 
-	return false
+	// The setup entry point runs after the dependencies are installed, and
+	// replaces fallbacks such as a plain make
+	if hasSetup {
+		detected = append(detected, &setup)
+	}
+	if len(detected) == 0 {
+		return fallbacks, nil
+	}
+	return detected, nil
 }
 
+// DetectPrimaryProject returns the highest-priority project in the
+// repository root, or in the first subdirectory with a project when the
+// root has none.
 func (d *Detector) DetectPrimaryProject() (*ProjectType, error) {
+	projects, err := d.DetectPrimaryProjects()
+	if err != nil || len(projects) == 0 {
+		return nil, err
+	}
+	return projects[0], nil
+}
+
+// DetectPrimaryProjects returns the highest-priority project of each
+// directory that has one, the root first.
+func (d *Detector) DetectPrimaryProjects() ([]*ProjectType, error) {
 	projects, err := d.DetectProjects()
 	if err != nil {
 		return nil, err
 	}
 
-	if len(projects) == 0 {
-		return nil, nil
+	var dirs []string
+	byDir := make(map[string][]*ProjectType)
+	for _, project := range projects {
+		if _, seen := byDir[project.Dir]; !seen {
+			dirs = append(dirs, project.Dir)
+		}
+		byDir[project.Dir] = append(byDir[project.Dir], project)
 	}
 
-	// Priority order for conflicting project types (package.json detection gets highest priority)
-	priorities := map[string]int{
-		// Package.json based detection (highest priority)
-		"Next.js (Yarn Package)":    35,
-		"Next.js (Package)":         34,
-		"Angular (Yarn Package)":    33,
-		"Angular (Package)":         32,
-		"Vue.js (Yarn Package)":     31,
-		"Vue.js (Package)":          30,
-		"React (Yarn Package)":      29,
-		"React (Package)":           28,
-
-		// File-based framework detection
-		"Next.js (Yarn)":     25,
-		"Next.js":            24,
-		"Nuxt.js":            23,
-		"Gatsby":             22,
-		"Remix":              21,
-		"Angular (Yarn)":     20,
-		"Angular":            19,
-		"Vue.js (Yarn)":      18,
-		"Vue.js":             17,
-		"SvelteKit":          16,
-		"Svelte":             15,
-		"Astro":              14,
-		"Vite":               13,
-
-		// Generic Node.js (lower priority than frameworks)
-		"Node.js (yarn)":     12,
-		"Node.js (npm)":      11,
-
-		// Other languages
-		"Python (Poetry)":    10,
-		"Python (Pipenv)":    9,
-		"Python (pip)":       8,
-		"Go":                 7,
-		"Rust":               6,
-		"Java (Gradle)":      5,
-		"Java (Maven)":       4,
-		"Ruby (Bundler)":     3,
-		"Dart (Flutter)":     2,
-		"Swift":              1,
+	var primaries []*ProjectType
+	for _, dir := range dirs {
+		primaries = append(primaries, Primary(byDir[dir]))
 	}
+	return primaries, nil
+}
 
-	var best *ProjectType
-	bestPriority := -1
-
-	for _, project := range projects {
-		if priority, exists := priorities[project.Name]; exists && priority > bestPriority {
+// Primary returns the highest-priority project of projects, the earliest
+// detected one on a tie.
+func Primary(projects []*ProjectType) *ProjectType {
+	best := projects[0]
+	for _, project := range projects[1:] {
+		if project.Priority > best.Priority {
 			best = project
-			bestPriority = priority
 		}
 	}
+	return best
+}
 
-	if best == nil {
-		return projects[0], nil
+// Ecosystem names the package ecosystem a language installs through, so
+// that JavaScript and TypeScript projects share one install.
+func Ecosystem(language string) string {
+	switch language {
+	case "JavaScript", "TypeScript":
+		return "Node.js"
 	}
-
-	return best, nil
+	return language
 }
 
-func (d *Detector) matchesProject(project ProjectType) bool {
-	for _, pattern := range project.Files {
+func (d *Detector) matchesAnyFile(patterns []string) bool {
+	for _, pattern := range patterns {
 		if d.hasMatchingFiles(pattern) {
 			return true
 		}
@@ -786,12 +336,12 @@ func (d *Detector) hasMatchingFiles(pattern string) bool {
 	if err != nil {
 		return false
 	}
-	
+
 	// Handle directory patterns (ending with /)
 	if strings.HasSuffix(pattern, "/") {
 		return stat.IsDir()
 	}
-	
+
 	return true
 }
 
@@ -830,24 +380,27 @@ func (d *Detector) GetProjectInfo(projectType *ProjectType) map[string]interface
 }
 
 func GetSupportedLanguages() []string {
-	languageSet := make(map[string]bool)
-	for _, project := range SupportedProjects {
-		languageSet[project.Language] = true
-	}
+	rules, _ := Rules()
 
+	languageSet := make(map[string]bool)
 	var languages []string
-	for lang := range languageSet {
-		languages = append(languages, lang)
+	for _, rule := range rules {
+		if !languageSet[rule.Language] {
+			languageSet[rule.Language] = true
+			languages = append(languages, rule.Language)
+		}
 	}
 
 	return languages
 }
 
 func GetProjectByName(name string) *ProjectType {
-	for _, project := range SupportedProjects {
-		if project.Name == name {
-			projectCopy := project
-			return &projectCopy
+	rules, _ := Rules()
+
+	for _, rule := range rules {
+		if rule.Name == name {
+			project := rule.ProjectType()
+			return &project
 		}
 	}
 	return nil
@@ -0,0 +1,130 @@
+package detect
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PackageManager is the JavaScript package manager a project installs with.
+type PackageManager struct {
+	Name     string // npm, pnpm, yarn or bun
+	Version  string // From the packageManager field, if pinned
+	Lockfile string // Lockfile found, empty when there is none
+	Berry    bool   // Yarn 2 or later
+}
+
+// lockfiles in the order they are checked when package.json does not name a
+// package manager.
+var lockfiles = []struct {
+	file    string
+	manager string
+}{
+	{"bun.lockb", "bun"},
+	{"bun.lock", "bun"},
+	{"pnpm-lock.yaml", "pnpm"},
+	{"yarn.lock", "yarn"},
+	{"package-lock.json", "npm"},
+	{"npm-shrinkwrap.json", "npm"},
+}
+
+// ResolvePackageManager picks the package manager of the JavaScript project
+// in the detector's directory: the packageManager field of package.json
+// wins, then the lockfile present, then npm. ok is false without a
+// package.json.
+func (d *Detector) ResolvePackageManager() (pm PackageManager, ok bool) {
+	data, err := os.ReadFile(filepath.Join(d.projectPath, "package.json"))
+	if err != nil {
+		return PackageManager{}, false
+	}
+
+	var pkg struct {
+		PackageManager string `json:"packageManager"`
+	}
+	_ = json.Unmarshal(data, &pkg)
+
+	if name, version, _ := strings.Cut(pkg.PackageManager, "@"); name != "" {
+		pm.Name = name
+		// Drop a "+sha..." integrity suffix
+		pm.Version, _, _ = strings.Cut(version, "+")
+	}
+
+	for _, lock := range lockfiles {
+		if pm.Name != "" && lock.manager != pm.Name {
+			continue
+		}
+		if d.hasMatchingFiles(lock.file) {
+			pm.Name = lock.manager
+			pm.Lockfile = lock.file
+			break
+		}
+	}
+
+	switch pm.Name {
+	case "npm", "pnpm", "yarn", "bun":
+	default:
+		pm = PackageManager{Name: "npm"}
+		if d.hasMatchingFiles("package-lock.json") {
+			pm.Lockfile = "package-lock.json"
+		}
+	}
+
+	if pm.Name == "yarn" {
+		pm.Berry = d.hasMatchingFiles(".yarnrc.yml") ||
+			(pm.Version != "" && !strings.HasPrefix(pm.Version, "1."))
+	}
+
+	return pm, true
+}
+
+// InstallCommand returns the install command, a reproducible one that
+// refuses to change the lockfile when there is one.
+func (pm PackageManager) InstallCommand() Command {
+	cmd := Command{
+		Name:        pm.Name + "-install",
+		Command:     pm.Name,
+		Args:        []string{"install"},
+		Description: "Install dependencies via " + pm.Name,
+		Required:    true,
+	}
+	if pm.Lockfile != "" {
+		switch {
+		case pm.Name == "npm":
+			cmd.Name = "npm-ci"
+			cmd.Args = []string{"ci"}
+		case pm.Name == "yarn" && pm.Berry:
+			cmd.Args = append(cmd.Args, "--immutable")
+		default:
+			cmd.Args = append(cmd.Args, "--frozen-lockfile")
+		}
+		cmd.Description += " from " + pm.Lockfile
+	}
+
+	// Lifecycle scripts, including those of dependencies, are skipped
+	if pm.Name == "yarn" && pm.Berry {
+		cmd.NoScripts = &NoScripts{Env: []string{"YARN_ENABLE_SCRIPTS=false"}}
+	} else {
+		cmd.NoScripts = &NoScripts{Args: append(append([]string(nil), cmd.Args...), "--ignore-scripts")}
+	}
+
+	// Dependencies come from the package manager's cache; npm only prefers
+	// it, fetching what it misses
+	switch {
+	case pm.Name == "npm":
+		cmd.Offline = &Offline{Args: []string{"--prefer-offline"}}
+	case pm.Name == "yarn" && pm.Berry:
+		cmd.Offline = &Offline{Env: []string{"YARN_ENABLE_OFFLINE_MODE=1"}}
+	case pm.Name == "yarn", pm.Name == "pnpm":
+		cmd.Offline = &Offline{Args: []string{"--offline"}}
+	}
+	return cmd
+}
+
+// String names the package manager with its pinned version, if any.
+func (pm PackageManager) String() string {
+	if pm.Version == "" {
+		return pm.Name
+	}
+	return pm.Name + "@" + pm.Version
+}
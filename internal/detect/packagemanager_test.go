@@ -0,0 +1,141 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePackageManager(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		manager string
+		command string
+	}{
+		{
+			name:    "npm without a lockfile",
+			files:   map[string]string{"package.json": `{}`},
+			manager: "npm",
+			command: "npm install",
+		},
+		{
+			name:    "npm with a lockfile",
+			files:   map[string]string{"package.json": `{}`, "package-lock.json": `{}`},
+			manager: "npm",
+			command: "npm ci",
+		},
+		{
+			name:    "npm shrinkwrap",
+			files:   map[string]string{"package.json": `{}`, "npm-shrinkwrap.json": `{}`},
+			manager: "npm",
+			command: "npm ci",
+		},
+		{
+			name:    "pnpm",
+			files:   map[string]string{"package.json": `{}`, "pnpm-lock.yaml": ""},
+			manager: "pnpm",
+			command: "pnpm install --frozen-lockfile",
+		},
+		{
+			name:    "bun",
+			files:   map[string]string{"package.json": `{}`, "bun.lock": ""},
+			manager: "bun",
+			command: "bun install --frozen-lockfile",
+		},
+		{
+			name:    "bun binary lockfile",
+			files:   map[string]string{"package.json": `{}`, "bun.lockb": ""},
+			manager: "bun",
+			command: "bun install --frozen-lockfile",
+		},
+		{
+			name:    "yarn classic",
+			files:   map[string]string{"package.json": `{}`, "yarn.lock": ""},
+			manager: "yarn",
+			command: "yarn install --frozen-lockfile",
+		},
+		{
+			name:    "yarn berry from .yarnrc.yml",
+			files:   map[string]string{"package.json": `{}`, "yarn.lock": "", ".yarnrc.yml": ""},
+			manager: "yarn",
+			command: "yarn install --immutable",
+		},
+		{
+			name:    "yarn berry from packageManager",
+			files:   map[string]string{"package.json": `{"packageManager": "yarn@4.1.0"}`, "yarn.lock": ""},
+			manager: "yarn@4.1.0",
+			command: "yarn install --immutable",
+		},
+		{
+			name:    "yarn classic from packageManager",
+			files:   map[string]string{"package.json": `{"packageManager": "yarn@1.22.19"}`, "yarn.lock": ""},
+			manager: "yarn@1.22.19",
+			command: "yarn install --frozen-lockfile",
+		},
+		{
+			name:    "packageManager wins over lockfiles",
+			files:   map[string]string{"package.json": `{"packageManager": "pnpm@9.1.0+sha512.abc"}`, "package-lock.json": `{}`, "yarn.lock": ""},
+			manager: "pnpm@9.1.0",
+			command: "pnpm install",
+		},
+		{
+			name:    "packageManager with its lockfile",
+			files:   map[string]string{"package.json": `{"packageManager": "pnpm@9.1.0"}`, "package-lock.json": `{}`, "pnpm-lock.yaml": ""},
+			manager: "pnpm@9.1.0",
+			command: "pnpm install --frozen-lockfile",
+		},
+		{
+			name:    "unknown packageManager",
+			files:   map[string]string{"package.json": `{"packageManager": "deno@2.0.0"}`, "package-lock.json": `{}`},
+			manager: "npm",
+			command: "npm ci",
+		},
+		{
+			name:    "bun lockfile first",
+			files:   map[string]string{"package.json": `{}`, "bun.lockb": "", "pnpm-lock.yaml": "", "yarn.lock": "", "package-lock.json": `{}`},
+			manager: "bun",
+			command: "bun install --frozen-lockfile",
+		},
+		{
+			name:    "pnpm lockfile before yarn and npm",
+			files:   map[string]string{"package.json": `{}`, "pnpm-lock.yaml": "", "yarn.lock": "", "package-lock.json": `{}`},
+			manager: "pnpm",
+			command: "pnpm install --frozen-lockfile",
+		},
+		{
+			name:    "yarn lockfile before npm",
+			files:   map[string]string{"package.json": `{}`, "yarn.lock": "", "package-lock.json": `{}`},
+			manager: "yarn",
+			command: "yarn install --frozen-lockfile",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, data := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			pm, ok := NewDetector(dir).ResolvePackageManager()
+			if !ok {
+				t.Fatal("ResolvePackageManager found no package.json")
+			}
+			if pm.String() != tt.manager {
+				t.Errorf("package manager = %s, want %s", pm, tt.manager)
+			}
+			if got := joinCommand(pm.InstallCommand()); got != tt.command {
+				t.Errorf("install command = %q, want %q", got, tt.command)
+			}
+		})
+	}
+}
+
+func TestResolvePackageManagerWithoutPackageJSON(t *testing.T) {
+	if _, ok := NewDetector(t.TempDir()).ResolvePackageManager(); ok {
+		t.Error("ResolvePackageManager found a project without package.json")
+	}
+}
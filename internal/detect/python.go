@@ -0,0 +1,101 @@
+package detect
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+)
+
+// VirtualEnvDir is where Python dependencies are installed, relative to the
+// project directory.
+const VirtualEnvDir = ".venv"
+
+var pep621Pattern = regexp.MustCompile(`(?m)^\[project\]`)
+
+// PythonInstall describes how a Python project's dependencies are installed
+// into its virtual environment.
+type PythonInstall struct {
+	UV         bool   // uv manages the environment
+	Project    bool   // PEP 621 project installed from pyproject.toml
+	VirtualEnv string // Relative to the project directory
+	Commands   []Command
+}
+
+// ResolvePythonInstall picks the commands that create a .venv in the
+// detector's directory and install into it: the PEP 621 project when
+// pyproject.toml has a [project] table, requirements.txt otherwise. uv is
+// used when it is on PATH. ok is false when there is nothing to install.
+func (d *Detector) ResolvePythonInstall() (install PythonInstall, ok bool) {
+	data, err := os.ReadFile(filepath.Join(d.projectPath, "pyproject.toml"))
+	install.Project = err == nil && pep621Pattern.Match(data)
+	if !install.Project && !d.hasMatchingFiles("requirements.txt") {
+		return PythonInstall{}, false
+	}
+
+	_, err = exec.LookPath("uv")
+	install.UV = err == nil
+	install.VirtualEnv = VirtualEnvDir
+
+	// With scripts disabled nothing is built from source, since building
+	// runs setup.py or the project's build backend; installing the project
+	// itself with pip always does
+	switch {
+	case install.UV && install.Project:
+		args := []string{"sync"}
+		if d.hasMatchingFiles("uv.lock") {
+			args = append(args, "--locked")
+		}
+		install.Commands = []Command{
+			{Name: "uv-sync", Command: "uv", Args: args, Description: "Create .venv and install the project with uv", Required: true,
+				NoScripts: &NoScripts{Args: append(append([]string(nil), args...), "--no-install-project", "--no-build")},
+				Offline:   &Offline{Args: []string{"--offline"}}},
+		}
+
+	case install.UV:
+		install.Commands = []Command{
+			{Name: "uv-venv", Command: "uv", Args: []string{"venv", "--allow-existing", VirtualEnvDir}, Description: "Create a virtual environment with uv", Required: true,
+				NoScripts: &NoScripts{}, Offline: &Offline{Args: []string{"--offline"}}},
+			{Name: "uv-pip-install", Command: "uv", Args: []string{"pip", "install", "--python", VirtualEnvDir, "-r", "requirements.txt"}, Description: "Install requirements into .venv with uv", Required: true,
+				NoScripts: &NoScripts{Args: []string{"pip", "install", "--python", VirtualEnvDir, "--no-build", "-r", "requirements.txt"}},
+				Offline:   &Offline{Args: []string{"--offline"}}},
+		}
+
+	default:
+		pip := []string{"-m", "pip", "install", "-r", "requirements.txt"}
+		noScripts := &NoScripts{Args: []string{"-m", "pip", "install", "--only-binary=:all:", "-r", "requirements.txt"}}
+		description := "Install requirements into .venv"
+		if install.Project {
+			pip = []string{"-m", "pip", "install", "-e", "."}
+			noScripts = nil
+			description = "Install the project into .venv"
+		}
+		install.Commands = []Command{
+			{Name: "python-venv", Command: pythonCommand(), Args: []string{"-m", "venv", VirtualEnvDir}, Description: "Create a virtual environment", Required: true,
+				NoScripts: &NoScripts{}, Offline: &Offline{}},
+			// Offline, pip installs from the wheelhouse in PIP_FIND_LINKS
+			{Name: "pip-install", Command: venvPython(), Args: pip, Description: description, Required: true,
+				NoScripts: noScripts, Offline: &Offline{Args: []string{"--no-index"}}},
+		}
+	}
+
+	return install, true
+}
+
+// pythonCommand is the interpreter used to create virtual environments.
+func pythonCommand() string {
+	if _, err := exec.LookPath("python3"); err == nil || runtime.GOOS != "windows" {
+		return "python3"
+	}
+	return "python"
+}
+
+// venvPython is the virtual environment's interpreter, relative to the
+// project directory.
+func venvPython() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(VirtualEnvDir, "Scripts", "python.exe")
+	}
+	return filepath.Join(VirtualEnvDir, "bin", "python")
+}
@@ -0,0 +1,284 @@
+package detect
+
+import (
+	_ "embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RulesDirName is the directory below the config directory holding user
+// detector files.
+const RulesDirName = "detectors.d"
+
+//go:embed detectors.yaml
+var builtinRules []byte
+
+// Rule describes how to recognise one project type and install it.
+type Rule struct {
+	Name        string        `yaml:"name"`
+	Language    string        `yaml:"language"`
+	Ecosystem   string        `yaml:"ecosystem,omitempty"`
+	Description string        `yaml:"description,omitempty"`
+	Priority    int           `yaml:"priority,omitempty"`
+	Manifests   []string      `yaml:"manifests,omitempty"`
+	Match       Match         `yaml:"match"`
+	Commands    []RuleCommand `yaml:"commands,omitempty"`
+	// Fallback rules match only directories no other rule matched.
+	Fallback bool `yaml:"fallback,omitempty"`
+	Disabled bool `yaml:"disabled,omitempty"`
+	// Source is the file the rule was loaded from.
+	Source string `yaml:"-"`
+
+	contents []contentMatcher
+}
+
+// Match holds the conditions a directory must meet; all that are set must
+// hold.
+type Match struct {
+	Files               []string       `yaml:"files,omitempty"`
+	Dependencies        []string       `yaml:"dependencies,omitempty"`
+	WithoutDependencies []string       `yaml:"without_dependencies,omitempty"`
+	Contents            []ContentMatch `yaml:"contents,omitempty"`
+}
+
+// ContentMatch requires File to exist and match the regular expression.
+type ContentMatch struct {
+	File    string `yaml:"file"`
+	Pattern string `yaml:"pattern"`
+}
+
+type RuleCommand struct {
+	Name        string   `yaml:"name"`
+	Command     string   `yaml:"command"`
+	Args        []string `yaml:"args,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Required    bool     `yaml:"required"`
+	Class       string   `yaml:"class,omitempty"` // network or build
+	// Verify is set for commands that check the install rather than being
+	// part of it, such as a build.
+	Verify bool `yaml:"verify,omitempty"`
+	// NoScripts is set for commands that can run without executing code
+	// from the repository; an empty value means they already do.
+	NoScripts *NoScripts `yaml:"no_scripts,omitempty"`
+	// Offline is set for commands that can run from local caches; an empty
+	// value means they never use the network.
+	Offline *Offline `yaml:"offline,omitempty"`
+}
+
+type contentMatcher struct {
+	file    string
+	pattern *regexp.Regexp
+}
+
+type ruleFile struct {
+	Detectors []Rule `yaml:"detectors"`
+}
+
+var (
+	rulesOnce   sync.Once
+	loadedRules []Rule
+	rulesErr    error
+)
+
+// Rules returns the built-in detectors merged with those in
+// ~/.quikgit/detectors.d/. They are loaded once per process.
+func Rules() ([]Rule, error) {
+	rulesOnce.Do(func() {
+		dir := ""
+		if home, err := os.UserHomeDir(); err == nil {
+			dir = filepath.Join(home, ".quikgit", RulesDirName)
+		}
+		loadedRules, rulesErr = LoadRules(dir)
+	})
+	return loadedRules, rulesErr
+}
+
+// LoadRules parses the built-in detectors, then every .yaml, .yml and .json
+// file in dir in name order. A rule named like an earlier one replaces it in
+// place, or removes it when disabled; other rules are appended.
+func LoadRules(dir string) ([]Rule, error) {
+	rules, err := parseRules(builtinRules, "built-in")
+	if err != nil {
+		return nil, err
+	}
+
+	var files []string
+	if dir != "" {
+		entries, err := os.ReadDir(dir)
+		if err != nil && !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to read %s: %w", dir, err)
+		}
+		for _, entry := range entries {
+			switch strings.ToLower(filepath.Ext(entry.Name())) {
+			case ".yaml", ".yml", ".json":
+				if !entry.IsDir() {
+					files = append(files, filepath.Join(dir, entry.Name()))
+				}
+			}
+		}
+		sort.Strings(files)
+	}
+
+	for _, path := range files {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+		overrides, err := parseRules(data, path)
+		if err != nil {
+			return nil, err
+		}
+		rules = mergeRules(rules, overrides)
+	}
+
+	return rules, nil
+}
+
+func parseRules(data []byte, source string) ([]Rule, error) {
+	var file ruleFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("%s: %w", source, err)
+	}
+
+	for i := range file.Detectors {
+		rule := &file.Detectors[i]
+		rule.Source = source
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("%s: detector %d: %w", source, i+1, err)
+		}
+	}
+	return file.Detectors, nil
+}
+
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if r.Disabled {
+		return nil
+	}
+	if r.Language == "" {
+		return fmt.Errorf("%s: language is required", r.Name)
+	}
+	if len(r.Match.Files) == 0 && len(r.Match.Dependencies) == 0 && len(r.Match.Contents) == 0 {
+		return fmt.Errorf("%s: match needs files, dependencies or contents", r.Name)
+	}
+	for _, cmd := range r.Commands {
+		if cmd.Command == "" {
+			return fmt.Errorf("%s: every command needs a command", r.Name)
+		}
+		if cmd.Class != "" && cmd.Class != "network" && cmd.Class != "build" {
+			return fmt.Errorf("%s: %s: class must be network or build", r.Name, cmd.Name)
+		}
+	}
+
+	r.contents = nil
+	for _, content := range r.Match.Contents {
+		if content.File == "" {
+			return fmt.Errorf("%s: contents entries need a file", r.Name)
+		}
+		pattern, err := regexp.Compile(content.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: invalid pattern for %s: %w", r.Name, content.File, err)
+		}
+		r.contents = append(r.contents, contentMatcher{file: content.File, pattern: pattern})
+	}
+	return nil
+}
+
+func mergeRules(rules, overrides []Rule) []Rule {
+	for _, override := range overrides {
+		index := -1
+		for i, rule := range rules {
+			if rule.Name == override.Name {
+				index = i
+				break
+			}
+		}
+
+		switch {
+		case index >= 0 && override.Disabled:
+			rules = append(rules[:index], rules[index+1:]...)
+		case index >= 0:
+			rules[index] = override
+		case !override.Disabled:
+			rules = append(rules, override)
+		}
+	}
+	return rules
+}
+
+// ProjectType returns the project type the rule detects.
+func (r Rule) ProjectType() ProjectType {
+	project := ProjectType{
+		Name:        r.Name,
+		Language:    r.Language,
+		Ecosystem:   r.Ecosystem,
+		Files:       r.Match.Files,
+		Description: r.Description,
+		Priority:    r.Priority,
+	}
+	if project.Ecosystem == "" {
+		project.Ecosystem = Ecosystem(r.Language)
+	}
+	for _, cmd := range r.Commands {
+		project.Commands = append(project.Commands, Command{
+			Name:        cmd.Name,
+			Command:     cmd.Command,
+			Args:        cmd.Args,
+			Description: cmd.Description,
+			Required:    cmd.Required,
+			Class:       cmd.Class,
+			Verify:      cmd.Verify,
+			NoScripts:   cmd.NoScripts,
+			Offline:     cmd.Offline,
+		})
+	}
+	return project
+}
+
+// matches reports whether every condition of the rule holds in the
+// detector's directory.
+func (d *Detector) matches(rule Rule) bool {
+	match := rule.Match
+
+	if len(match.Files) > 0 && !d.matchesAnyFile(match.Files) {
+		return false
+	}
+	if len(match.Dependencies) > 0 && !d.hasPackageDependency(match.Dependencies...) {
+		return false
+	}
+	if len(match.WithoutDependencies) > 0 && d.hasPackageDependency(match.WithoutDependencies...) {
+		return false
+	}
+	for _, content := range rule.contents {
+		data, err := os.ReadFile(filepath.Join(d.projectPath, content.file))
+		if err != nil || !content.pattern.Match(data) {
+			return false
+		}
+	}
+	return true
+}
+
+// manifestFiles returns the manifests declared by the rules, in order and
+// without duplicates.
+func manifestFiles(rules []Rule) []string {
+	var manifests []string
+	seen := make(map[string]bool)
+	for _, rule := range rules {
+		for _, manifest := range rule.Manifests {
+			if !seen[manifest] {
+				seen[manifest] = true
+				manifests = append(manifests, manifest)
+			}
+		}
+	}
+	return manifests
+}
@@ -0,0 +1,146 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SetupPriority ranks a setup entry point above every built-in detector, as
+// the repository's own way to get ready for development.
+const SetupPriority = 50
+
+// setupTargets are the conventional names of setup entry points, preferred
+// first.
+var setupTargets = []string{"setup", "bootstrap"}
+
+var (
+	makefiles = []string{"GNUmakefile", "makefile", "Makefile"}
+	justfiles = []string{"justfile", "Justfile", ".justfile"}
+	taskfiles = []string{"Taskfile.yml", "Taskfile.yaml", "taskfile.yml", "taskfile.yaml"}
+)
+
+// targetPatterns match a Makefile rule or justfile recipe of each setup
+// target, but not a variable assignment such as "setup := ...".
+var targetPatterns = map[string]*regexp.Regexp{
+	"setup":     regexp.MustCompile(`(?m)^@?setup(\s[^:=\n]*)?:([^=]|$)`),
+	"bootstrap": regexp.MustCompile(`(?m)^@?bootstrap(\s[^:=\n]*)?:([^=]|$)`),
+}
+
+// ResolveSetup finds the repository's own setup entry point in the
+// detector's directory: script/bootstrap from "Scripts to Rule Them All",
+// then a setup or bootstrap target of make, just or Task. ok is false when
+// there is none.
+func (d *Detector) ResolveSetup() (project ProjectType, ok bool) {
+	cmd, file, ok := d.setupCommand()
+	if !ok {
+		return ProjectType{}, false
+	}
+	cmd.Name = "setup-" + cmd.Name
+	cmd.Required = true
+
+	return ProjectType{
+		Name:        "Setup (" + commandLine(cmd) + ")",
+		Language:    "Shell",
+		Ecosystem:   "Setup",
+		Files:       []string{file},
+		Commands:    []Command{cmd},
+		Description: "The repository's own setup entry point",
+		Priority:    SetupPriority,
+	}, true
+}
+
+func (d *Detector) setupCommand() (cmd Command, file string, ok bool) {
+	if runtime.GOOS != "windows" {
+		if info, err := os.Stat(filepath.Join(d.projectPath, "script", "bootstrap")); err == nil && info.Mode()&0o111 != 0 {
+			return Command{Name: "bootstrap", Command: "script/bootstrap", Description: "Resolve dependencies with script/bootstrap"}, "script/bootstrap", true
+		}
+	}
+
+	for _, target := range setupTargets {
+		for _, name := range makefiles {
+			if d.hasTarget(name, target) {
+				return Command{Name: "make", Command: "make", Args: []string{target}, Description: "Run make " + target}, name, true
+			}
+		}
+	}
+	for _, target := range setupTargets {
+		for _, name := range justfiles {
+			if d.hasTarget(name, target) {
+				return Command{Name: "just", Command: "just", Args: []string{target}, Description: "Run just " + target}, name, true
+			}
+		}
+	}
+	for _, target := range setupTargets {
+		for _, name := range taskfiles {
+			if d.hasTask(name, target) {
+				return Command{Name: "task", Command: "task", Args: []string{target}, Description: "Run task " + target}, name, true
+			}
+		}
+	}
+	return Command{}, "", false
+}
+
+// hasTarget reports whether the Makefile or justfile name defines target.
+func (d *Detector) hasTarget(name, target string) bool {
+	data, err := os.ReadFile(filepath.Join(d.projectPath, name))
+	return err == nil && targetPatterns[target].Match(data)
+}
+
+// hasTask reports whether the Taskfile name defines the task.
+func (d *Detector) hasTask(name, task string) bool {
+	data, err := os.ReadFile(filepath.Join(d.projectPath, name))
+	if err != nil {
+		return false
+	}
+	var taskfile struct {
+		Tasks map[string]yaml.Node `yaml:"tasks"`
+	}
+	if err := yaml.Unmarshal(data, &taskfile); err != nil {
+		return false
+	}
+	_, ok := taskfile.Tasks[task]
+	return ok
+}
+
+// SetupScript returns the package.json script that sets the JavaScript
+// project up after its install: setup or bootstrap, or prepare for Yarn 2
+// and later, which no longer run it on install. ok is false when there is
+// none.
+func (d *Detector) SetupScript(pm PackageManager) (script string, ok bool) {
+	pkg, err := d.parsePackageJSON()
+	if err != nil {
+		return "", false
+	}
+	for _, script := range setupTargets {
+		if _, ok := pkg.Scripts[script]; ok {
+			return script, true
+		}
+	}
+	if _, ok := pkg.Scripts["prepare"]; ok && pm.Name == "yarn" && pm.Berry {
+		return "prepare", true
+	}
+	return "", false
+}
+
+// RunCommand returns the command running a package.json script.
+func (pm PackageManager) RunCommand(script string) Command {
+	return Command{
+		Name:        pm.Name + "-run-" + script,
+		Command:     pm.Name,
+		Args:        []string{"run", script},
+		Description: "Run the " + script + " script",
+		Required:    true,
+	}
+}
+
+func commandLine(cmd Command) string {
+	line := cmd.Command
+	for _, arg := range cmd.Args {
+		line += " " + arg
+	}
+	return line
+}
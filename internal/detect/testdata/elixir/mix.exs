@@ -0,0 +1,3 @@
+defmodule Demo.MixProject do
+  use Mix.Project
+end
@@ -0,0 +1 @@
+{ pkgs ? import <nixpkgs> {} }: pkgs.mkShell { }
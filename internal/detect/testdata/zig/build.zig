@@ -0,0 +1 @@
+const std = @import("std");
@@ -0,0 +1,297 @@
+package detect
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Toolchain is a version requirement pinned by a project file, and the
+// version of the tool found locally once checked.
+type Toolchain struct {
+	Tool     string // node, python, go, rust, ruby or dotnet
+	Required string // Constraint as written, e.g. "18", ">=1.22" or "^18 || ^20"
+	Source   string // File the pin was read from, relative to the repository
+	Found    string // Local version, empty when the tool is missing
+	Manager  string // Version manager providing the pinned version, if any
+	Checked  bool
+	// Satisfied is also true when Required is not a version, such as
+	// "lts/*" or "stable".
+	Satisfied bool
+}
+
+func (t Toolchain) String() string {
+	if t.Manager != "" {
+		return fmt.Sprintf("%s %s required by %s, provided by %s", t.Tool, t.Required, t.Source, t.Manager)
+	}
+	if t.Found == "" {
+		return fmt.Sprintf("%s %s required by %s, not installed", t.Tool, t.Required, t.Source)
+	}
+	return fmt.Sprintf("%s %s required by %s, found %s", t.Tool, t.Required, t.Source, t.Found)
+}
+
+// Check compares the pin with the version found locally.
+func (t *Toolchain) Check(found string) {
+	t.Found = ExtractVersion(found)
+	t.Checked = true
+
+	ok, known := MatchVersion(t.Required, t.Found)
+	t.Satisfied = ok || (!known && t.Found != "")
+}
+
+// ecosystemTools maps ecosystems to the tool whose version they pin.
+var ecosystemTools = map[string]string{
+	"Node.js": "node",
+	"Python":  "python",
+	"Go":      "go",
+	"Rust":    "rust",
+	"Ruby":    "ruby",
+	"C#":      "dotnet",
+}
+
+// ToolFor returns the tool an ecosystem's version pins refer to, or "".
+func ToolFor(ecosystem string) string {
+	return ecosystemTools[ecosystem]
+}
+
+// toolVersionNames maps .tool-versions plugin names to tools.
+var toolVersionNames = map[string]string{
+	"nodejs":      "node",
+	"node":        "node",
+	"python":      "python",
+	"golang":      "go",
+	"go":          "go",
+	"rust":        "rust",
+	"ruby":        "ruby",
+	"dotnet":      "dotnet",
+	"dotnet-core": "dotnet",
+}
+
+// FindToolchains reads the version pins for tool in dir and, for a tool not
+// pinned there, in its parents up to root. dir and root are absolute.
+func FindToolchains(root, dir, tool string) []Toolchain {
+	for {
+		pins := readPins(dir, tool)
+		for i := range pins {
+			if rel, err := filepath.Rel(root, filepath.Join(dir, pins[i].Source)); err == nil {
+				pins[i].Source = filepath.ToSlash(rel)
+			}
+		}
+		if len(pins) > 0 {
+			return pins
+		}
+
+		if dir == root || !strings.HasPrefix(dir, root) {
+			return nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil
+		}
+		dir = parent
+	}
+}
+
+// readPins returns the pins for tool declared directly in dir, with Source
+// set to the file name.
+func readPins(dir, tool string) []Toolchain {
+	var pins []Toolchain
+	add := func(required, source string) {
+		required = strings.TrimSpace(required)
+		if required != "" {
+			pins = append(pins, Toolchain{Tool: tool, Required: required, Source: source})
+		}
+	}
+
+	switch tool {
+	case "node":
+		add(strings.TrimPrefix(firstLine(filepath.Join(dir, ".nvmrc")), "v"), ".nvmrc")
+		add(strings.TrimPrefix(firstLine(filepath.Join(dir, ".node-version")), "v"), ".node-version")
+		add(packageEngine(filepath.Join(dir, "package.json"), "node"), "package.json")
+	case "python":
+		add(strings.TrimPrefix(firstLine(filepath.Join(dir, ".python-version")), "python-"), ".python-version")
+	case "go":
+		if version := goDirective(filepath.Join(dir, "go.mod")); version != "" {
+			add(">="+version, "go.mod")
+		}
+	case "rust":
+		add(rustChannel(filepath.Join(dir, "rust-toolchain.toml")), "rust-toolchain.toml")
+		add(firstLine(filepath.Join(dir, "rust-toolchain")), "rust-toolchain")
+	case "ruby":
+		add(strings.TrimPrefix(firstLine(filepath.Join(dir, ".ruby-version")), "ruby-"), ".ruby-version")
+	case "dotnet":
+		add(globalJSONSDK(filepath.Join(dir, "global.json")), "global.json")
+	}
+
+	add(toolVersionsEntry(filepath.Join(dir, ".tool-versions"), tool), ".tool-versions")
+	return pins
+}
+
+func firstLine(path string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return line
+		}
+	}
+	return ""
+}
+
+func packageEngine(path, engine string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Engines map[string]string `json:"engines"`
+	}
+	if json.Unmarshal(data, &pkg) != nil {
+		return ""
+	}
+	return pkg.Engines[engine]
+}
+
+var goDirectivePattern = regexp.MustCompile(`(?m)^go\s+(\d+(?:\.\d+)*)\s*$`)
+
+func goDirective(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if match := goDirectivePattern.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+var rustChannelPattern = regexp.MustCompile(`(?m)^\s*channel\s*=\s*"([^"]+)"`)
+
+func rustChannel(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	if match := rustChannelPattern.FindSubmatch(data); match != nil {
+		return string(match[1])
+	}
+	return ""
+}
+
+// globalJSONSDK turns the SDK pin of a global.json into a constraint that
+// follows its rollForward policy.
+func globalJSONSDK(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	var global struct {
+		SDK struct {
+			Version     string `json:"version"`
+			RollForward string `json:"rollForward"`
+		} `json:"sdk"`
+	}
+	if json.Unmarshal(data, &global) != nil || global.SDK.Version == "" {
+		return ""
+	}
+
+	version := global.SDK.Version
+	parts, ok := parseVersion(version)
+	if !ok || len(parts) < 3 {
+		return version
+	}
+
+	switch global.SDK.RollForward {
+	case "disable":
+		return version
+	case "feature", "latestFeature":
+		return "~" + version
+	case "minor", "latestMinor":
+		return "^" + version
+	case "major", "latestMajor":
+		return ">=" + version
+	default:
+		// patch and latestPatch stay within the feature band, e.g. 8.0.1xx
+		band := parts[2] / 100
+		return fmt.Sprintf(">=%s <%d.%d.%d", version, parts[0], parts[1], (band+1)*100)
+	}
+}
+
+func toolVersionsEntry(path, tool string) string {
+	file, err := os.Open(path)
+	if err != nil {
+		return ""
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if toolVersionNames[fields[0]] == tool {
+			// Later fields are fallbacks; the first is the one used
+			return fields[1]
+		}
+	}
+	return ""
+}
+
+// versionCommands print each tool's version.
+var versionCommands = map[string][][]string{
+	"node":   {{"node", "--version"}},
+	"python": {{"python3", "--version"}, {"python", "--version"}},
+	"go":     {{"go", "version"}},
+	"rust":   {{"rustc", "--version"}},
+	"ruby":   {{"ruby", "--version"}},
+	"dotnet": {{"dotnet", "--version"}},
+}
+
+// LocalVersion runs the tool in dir, where version managers pick their
+// per-project version, and returns the version it reports.
+func LocalVersion(ctx context.Context, tool, dir string) (string, error) {
+	commands, ok := versionCommands[tool]
+	if !ok {
+		return "", fmt.Errorf("unknown tool %s", tool)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var lastErr error
+	for _, args := range commands {
+		if _, err := exec.LookPath(args[0]); err != nil {
+			lastErr = err
+			continue
+		}
+		cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+		cmd.Dir = dir
+		// Report the installed Go rather than downloading the one go.mod asks for
+		cmd.Env = append(os.Environ(), "GOTOOLCHAIN=local")
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			lastErr = fmt.Errorf("%s: %w", strings.Join(args, " "), err)
+			continue
+		}
+		if version := ExtractVersion(string(output)); version != "" {
+			return version, nil
+		}
+		lastErr = fmt.Errorf("%s printed no version: %s", strings.Join(args, " "), strconv.Quote(strings.TrimSpace(string(output))))
+	}
+	return "", lastErr
+}
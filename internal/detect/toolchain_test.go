@@ -0,0 +1,51 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestGlobalJSONSDK(t *testing.T) {
+	tests := []struct {
+		global string
+		want   string
+	}{
+		// patch, the default, stays within the feature band
+		{`{"sdk": {"version": "8.0.100"}}`, ">=8.0.100 <8.0.200"},
+		{`{"sdk": {"version": "8.0.105", "rollForward": "latestPatch"}}`, ">=8.0.105 <8.0.200"},
+		{`{"sdk": {"version": "8.0.204", "rollForward": "patch"}}`, ">=8.0.204 <8.0.300"},
+		{`{"sdk": {"version": "8.0.100", "rollForward": "disable"}}`, "8.0.100"},
+		{`{"sdk": {"version": "8.0.100", "rollForward": "latestFeature"}}`, "~8.0.100"},
+		{`{"sdk": {"version": "8.0.100", "rollForward": "minor"}}`, "^8.0.100"},
+		{`{"sdk": {"version": "8.0.100", "rollForward": "latestMajor"}}`, ">=8.0.100"},
+		{`{"sdk": {"version": "8.0"}}`, "8.0"},
+		{`{"sdk": {}}`, ""},
+		{`not json`, ""},
+	}
+
+	for _, tt := range tests {
+		path := filepath.Join(t.TempDir(), "global.json")
+		if err := os.WriteFile(path, []byte(tt.global), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if got := globalJSONSDK(path); got != tt.want {
+			t.Errorf("globalJSONSDK(%s) = %q, want %q", tt.global, got, tt.want)
+		}
+	}
+}
+
+func TestGlobalJSONSDKBand(t *testing.T) {
+	constraint := ">=8.0.100 <8.0.200"
+	for found, want := range map[string]bool{
+		"8.0.100": true,
+		"8.0.199": true,
+		"8.0.200": false,
+		"8.0.99":  false,
+		"9.0.100": false,
+	} {
+		if ok, _ := MatchVersion(constraint, found); ok != want {
+			t.Errorf("MatchVersion(%q, %q) = %v, want %v", constraint, found, ok, want)
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package detect
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var versionPattern = regexp.MustCompile(`\d+(\.\d+)*`)
+
+// ExtractVersion returns the first dotted version number in s, such as
+// "1.22.1" from "go version go1.22.1 linux/amd64".
+func ExtractVersion(s string) string {
+	return versionPattern.FindString(s)
+}
+
+// parseVersion reads up to three numeric components; "x" or "*" ends the
+// version early. ok is false when there is no leading number.
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimSpace(s)
+	s = strings.TrimLeft(s, "vV=")
+
+	var parts []int
+	for _, field := range strings.SplitN(s, ".", 3) {
+		digits := field
+		if i := strings.IndexFunc(field, func(r rune) bool { return r < '0' || r > '9' }); i >= 0 {
+			digits = field[:i]
+		}
+		if digits == "" {
+			break
+		}
+		n, err := strconv.Atoi(digits)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+		if len(digits) != len(field) {
+			break
+		}
+	}
+	return parts, len(parts) > 0
+}
+
+// compareVersions compares found with the first len(want) components of
+// want, so that "18.17.1" equals "18" and "18.17".
+func compareVersions(found, want []int) int {
+	for i, w := range want {
+		f := 0
+		if i < len(found) {
+			f = found[i]
+		}
+		switch {
+		case f < w:
+			return -1
+		case f > w:
+			return 1
+		}
+	}
+	return 0
+}
+
+// MatchVersion reports whether found satisfies constraint, written as a
+// version ("18", "3.11.4"), an npm-style range (">=18 <21", "^18 || ^20",
+// "~1.2", "16 - 18", "18.x") or a single comparison. known is false when the
+// constraint is not a version at all, such as "lts/*" or "stable".
+func MatchVersion(constraint, found string) (ok, known bool) {
+	foundVersion, hasFound := parseVersion(ExtractVersion(found))
+
+	for _, group := range strings.Split(constraint, "||") {
+		comparators, parsed := parseRange(group)
+		if !parsed {
+			continue
+		}
+		known = true
+		if !hasFound {
+			continue
+		}
+
+		satisfied := true
+		for _, c := range comparators {
+			if !c.match(foundVersion) {
+				satisfied = false
+				break
+			}
+		}
+		if satisfied {
+			return true, true
+		}
+	}
+	return false, known
+}
+
+type comparator struct {
+	op      string
+	version []int
+}
+
+func (c comparator) match(found []int) bool {
+	cmp := compareVersions(found, c.version)
+	switch c.op {
+	case ">=":
+		return cmp >= 0
+	case ">":
+		return cmp > 0
+	case "<=":
+		return cmp <= 0
+	case "<":
+		return cmp < 0
+	case "^":
+		// Same major version, or below 1.0 the same version up to the
+		// first non-zero component: ^0.2.3 is <0.3.0, ^0.0.3 is <0.0.4
+		prefix := c.version
+		for i, n := range c.version {
+			if n != 0 {
+				prefix = c.version[:i+1]
+				break
+			}
+		}
+		return cmp >= 0 && compareVersions(found, prefix) == 0
+	case "~":
+		prefix := c.version
+		if len(prefix) > 2 {
+			prefix = prefix[:2]
+		}
+		return cmp >= 0 && compareVersions(found, prefix) == 0
+	default:
+		return cmp == 0
+	}
+}
+
+// parseRange parses one "||" alternative into comparators that must all hold.
+func parseRange(group string) ([]comparator, bool) {
+	fields := strings.Fields(strings.ReplaceAll(group, ",", " "))
+	if len(fields) == 3 && fields[1] == "-" {
+		low, ok1 := parseVersion(fields[0])
+		high, ok2 := parseVersion(fields[2])
+		if !ok1 || !ok2 {
+			return nil, false
+		}
+		return []comparator{{op: ">=", version: low}, {op: "<=", version: high}}, true
+	}
+
+	var comparators []comparator
+	for i := 0; i < len(fields); i++ {
+		field := fields[i]
+		op := ""
+		for _, candidate := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+			if strings.HasPrefix(field, candidate) {
+				op = candidate
+				field = strings.TrimPrefix(field, candidate)
+				break
+			}
+		}
+		// An operator separated from its version, as in ">= 18"
+		if field == "" && i+1 < len(fields) {
+			i++
+			field = fields[i]
+		}
+
+		version, ok := parseVersion(field)
+		if !ok {
+			return nil, false
+		}
+		comparators = append(comparators, comparator{op: op, version: version})
+	}
+	return comparators, len(comparators) > 0
+}
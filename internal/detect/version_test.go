@@ -0,0 +1,113 @@
+package detect
+
+import "testing"
+
+func TestMatchVersion(t *testing.T) {
+	tests := []struct {
+		constraint string
+		found      string
+		ok         bool
+	}{
+		// Exact and partial versions
+		{"18", "v18.17.1", true},
+		{"18", "v20.1.0", false},
+		{"3.11", "Python 3.11.4", true},
+		{"3.11", "Python 3.12.0", false},
+		{"3.11.4", "3.11.4", true},
+		{"3.11.4", "3.11.5", false},
+		{"=1.22", "go version go1.22.1 linux/amd64", true},
+		{"v18.17", "18.17.0", true},
+		{"V18", "18.0.0", true},
+
+		// Caret
+		{"^18.2", "18.19.0", true},
+		{"^18.2", "18.1.9", false},
+		{"^18.2", "19.0.0", false},
+		{"^0.2.3", "0.2.9", true},
+		{"^0.2.3", "0.3.0", false},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^0.x", "0.9.1", true},
+		{"^0.x", "1.0.0", false},
+		{"^0.0", "0.0.7", true},
+		{"^0.0", "0.1.0", false},
+
+		// Tilde
+		{"~1.2.3", "1.2.9", true},
+		{"~1.2.3", "1.2.2", false},
+		{"~1.2.3", "1.3.0", false},
+		{"~1.2", "1.2.0", true},
+		{"~1", "1.9.0", true},
+		{"~1", "2.0.0", false},
+
+		// Hyphen ranges include the whole upper version
+		{"16 - 18", "16.0.0", true},
+		{"16 - 18", "18.20.1", true},
+		{"16 - 18", "19.0.0", false},
+		{"16 - 18", "15.9.9", false},
+		{"1.2.3 - 2.3", "2.3.9", true},
+		{"1.2.3 - 2.3", "1.2.2", false},
+
+		// Space and comma separated comparators must all hold
+		{">=18 <21", "20.11.0", true},
+		{">=18 <21", "21.0.0", false},
+		{">=18 <21", "17.9.0", false},
+		{">= 18", "18.0.0", true},
+		{">=3.8, <4", "3.12.1", true},
+		// A partial version is a whole range: >3.8 is >=3.9.0
+		{">3.8 <=3.10", "3.9.0", true},
+		{">3.8 <=3.10", "3.8.5", false},
+		{">3.8 <=3.10", "3.10.7", true},
+		{">3.8 <=3.10", "3.11.0", false},
+
+		// Alternatives
+		{"^18 || ^20", "20.3.0", true},
+		{"^18 || ^20", "19.0.0", false},
+		{"14 || >=16 <17 || 20", "16.4.0", true},
+
+		// x wildcards
+		{"18.x", "18.4.0", true},
+		{"18.x", "19.0.0", false},
+		{"1.2.x", "1.2.7", true},
+		{"1.2.x", "1.3.0", false},
+		{"1.x.x", "1.9.9", true},
+		{"18.*", "18.0.1", true},
+	}
+
+	for _, tt := range tests {
+		ok, known := MatchVersion(tt.constraint, tt.found)
+		if !known {
+			t.Errorf("MatchVersion(%q, %q) is unknown", tt.constraint, tt.found)
+		}
+		if ok != tt.ok {
+			t.Errorf("MatchVersion(%q, %q) = %v, want %v", tt.constraint, tt.found, ok, tt.ok)
+		}
+	}
+}
+
+func TestMatchVersionUnknown(t *testing.T) {
+	for _, constraint := range []string{"lts/*", "stable", "latest", "system", ""} {
+		if ok, known := MatchVersion(constraint, "20.1.0"); ok || known {
+			t.Errorf("MatchVersion(%q) = %v, %v, want unknown", constraint, ok, known)
+		}
+	}
+	// A known constraint with no version found does not match
+	if ok, known := MatchVersion("18", "not installed"); ok || !known {
+		t.Errorf("MatchVersion without a version = %v, %v, want a known mismatch", ok, known)
+	}
+}
+
+func TestExtractVersion(t *testing.T) {
+	tests := map[string]string{
+		"go version go1.22.1 linux/amd64":     "1.22.1",
+		"v20.11.0":                            "20.11.0",
+		"Python 3.12.1":                       "3.12.1",
+		"rustc 1.75.0 (82e1608df 2023-12-21)": "1.75.0",
+		"no version":                          "",
+	}
+	for s, want := range tests {
+		if got := ExtractVersion(s); got != want {
+			t.Errorf("ExtractVersion(%q) = %q, want %q", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,211 @@
+package detect
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+)
+
+// DefaultMaxDepth is how many directory levels below the repository root
+// are searched for nested projects.
+const DefaultMaxDepth = 3
+
+// skipDirs are never walked, whether or not they are ignored by git.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// projectDirs returns the root ("") and the subdirectories, relative to the
+// root and slash-separated, that hold a manifest file. Hidden, vendored and
+// git-ignored directories are skipped.
+func (d *Detector) projectDirs() []string {
+	dirs := []string{""}
+	manifests := manifestFiles(d.rules)
+
+	var walk func(rel string, depth int, patterns []gitignore.Pattern)
+	walk = func(rel string, depth int, patterns []gitignore.Pattern) {
+		abs := filepath.Join(d.projectPath, filepath.FromSlash(rel))
+
+		var domain []string
+		if rel != "" {
+			domain = strings.Split(rel, "/")
+		}
+		patterns = append(patterns, readIgnoreFile(filepath.Join(abs, ".gitignore"), domain)...)
+		matcher := gitignore.NewMatcher(patterns)
+
+		entries, err := os.ReadDir(abs)
+		if err != nil {
+			return
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || skipDirs[name] {
+				continue
+			}
+
+			child := name
+			if rel != "" {
+				child = rel + "/" + name
+			}
+			if matcher.Match(strings.Split(child, "/"), true) {
+				continue
+			}
+
+			if hasManifest(filepath.Join(abs, name), manifests) {
+				dirs = append(dirs, child)
+			}
+			if depth+1 < d.maxDepth {
+				walk(child, depth+1, patterns)
+			}
+		}
+	}
+
+	if d.maxDepth > 0 {
+		walk("", 0, nil)
+	}
+
+	sort.Strings(dirs[1:])
+	return dirs
+}
+
+// hasManifest reports whether dir holds one of the detectors' manifests.
+func hasManifest(dir string, manifests []string) bool {
+	for _, pattern := range manifests {
+		if strings.Contains(pattern, "*") {
+			if matches, _ := filepath.Glob(filepath.Join(dir, pattern)); len(matches) > 0 {
+				return true
+			}
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(dir, pattern)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// readIgnoreFile parses a .gitignore whose patterns apply below domain.
+func readIgnoreFile(path string, domain []string) []gitignore.Pattern {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var patterns []gitignore.Pattern
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		patterns = append(patterns, gitignore.ParsePattern(line, domain))
+	}
+	return patterns
+}
+
+// workspaceLanguages returns the languages whose nested projects are managed
+// by a workspace declared in dir, such as npm/yarn/pnpm workspaces or a
+// Cargo workspace. Installing at dir already covers those projects.
+func workspaceLanguages(dir string) []string {
+	var languages []string
+
+	if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil && strings.Contains(string(data), `"workspaces"`) {
+		languages = append(languages, "JavaScript", "TypeScript")
+	} else if _, err := os.Stat(filepath.Join(dir, "pnpm-workspace.yaml")); err == nil {
+		languages = append(languages, "JavaScript", "TypeScript")
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "Cargo.toml")); err == nil && strings.Contains(string(data), "[workspace]") {
+		languages = append(languages, "Rust")
+	}
+
+	return languages
+}
+
+// goWorkModules returns the directories of the modules the go.work in dir
+// uses, relative to the repository root like dir itself.
+func goWorkModules(root, dir string) []string {
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(dir), "go.work"))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var modules []string
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "//")
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+			continue
+		case inBlock && fields[0] == ")":
+			inBlock = false
+			continue
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+			continue
+		case fields[0] == "use" && len(fields) > 1:
+			fields = fields[1:]
+		case !inBlock:
+			continue
+		}
+		module := path.Join(dir, strings.Trim(fields[0], `"`))
+		if module == "." {
+			module = ""
+		}
+		modules = append(modules, module)
+	}
+	return modules
+}
+
+// goWorkDownloads returns a go mod download for each module the go.work in
+// the detector's directory uses, run in the module's directory.
+func (d *Detector) goWorkDownloads() []Command {
+	var commands []Command
+	for _, module := range goWorkModules(d.projectPath, "") {
+		cmd := Command{
+			Name:        "go-mod-download",
+			Command:     "go",
+			Args:        []string{"mod", "download"},
+			Description: "Download dependencies to the module cache",
+			Required:    true,
+			NoScripts:   &NoScripts{},
+			Offline:     &Offline{Env: []string{"GOFLAGS=-mod=mod", "GOPROXY=off"}},
+		}
+		if module != "" {
+			cmd.Args = append([]string{"-C", module}, cmd.Args...)
+			cmd.Description = "Download the dependencies of " + module + " to the module cache"
+		}
+		commands = append(commands, cmd)
+	}
+	return commands
+}
+
+// coveredByWorkspace reports whether a project in dir is part of a workspace
+// declared in one of its parent directories.
+func coveredByWorkspace(dir, language string, workspaces map[string][]string) bool {
+	for parent, languages := range workspaces {
+		if parent != "" && !strings.HasPrefix(dir, parent+"/") {
+			continue
+		}
+		if parent == dir {
+			continue
+		}
+		for _, l := range languages {
+			if l == language {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -3,18 +3,22 @@ package github
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
 
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
 )
 
 type CloneProgress struct {
 	Repository string
+	Path       string
+	Transport  string
 	Status     string
 	Progress   float64
 	Error      error
@@ -28,6 +32,7 @@ type CloneManager struct {
 	progress      chan CloneProgress
 	wg            sync.WaitGroup
 	createSubdirs bool
+	pathTemplate  string
 }
 
 func NewCloneManager(token, targetDir string) *CloneManager {
@@ -48,6 +53,33 @@ func (cm *CloneManager) SetCreateSubdirs(createSubdirs bool) {
 	cm.createSubdirs = createSubdirs
 }
 
+// SetPathTemplate lays out clone paths below the target directory using the
+// {owner} and {name} placeholders. It takes precedence over SetCreateSubdirs.
+func (cm *CloneManager) SetPathTemplate(template string) {
+	cm.pathTemplate = template
+}
+
+// TargetPath returns the directory repo is cloned into.
+func (cm *CloneManager) TargetPath(repo *Repository) string {
+	return ClonePath(cm.targetDir, cm.pathTemplate, cm.createSubdirs, repo)
+}
+
+// ClonePath lays out the clone directory of repo below targetDir, using
+// pathTemplate when set and owner subdirectories when createSubdirs is true.
+func ClonePath(targetDir, pathTemplate string, createSubdirs bool, repo *Repository) string {
+	switch {
+	case pathTemplate != "":
+		rel := strings.NewReplacer("{owner}", repo.Owner, "{name}", repo.Name).Replace(pathTemplate)
+		return filepath.Join(targetDir, filepath.FromSlash(rel))
+	case createSubdirs:
+		// Create subdirectory structure: targetDir/owner/repo
+		return filepath.Join(targetDir, repo.Owner, repo.Name)
+	default:
+		// Just use repo name in target directory
+		return filepath.Join(targetDir, repo.Name)
+	}
+}
+
 func (cm *CloneManager) SetSSHKey(keyPath string) {
 	cm.sshKey = keyPath
 }
@@ -96,21 +128,17 @@ func (cm *CloneManager) cloneWorker(ctx context.Context, repo *Repository) {
 	cm.sendProgress(progress)
 
 	// Determine target path based on configuration
-	var targetPath string
-	if cm.createSubdirs {
-		// Create subdirectory structure: targetDir/owner/repo
-		ownerDir := filepath.Join(cm.targetDir, repo.Owner)
-		if err := os.MkdirAll(ownerDir, 0755); err != nil {
-			progress.Status = "Failed to create owner directory"
-			progress.Error = fmt.Errorf("failed to create directory %s: %w", ownerDir, err)
+	targetPath := cm.TargetPath(repo)
+	progress.Path = targetPath
+
+	if parentDir := filepath.Dir(targetPath); parentDir != cm.targetDir {
+		if err := os.MkdirAll(parentDir, 0755); err != nil {
+			progress.Status = "Failed to create parent directory"
+			progress.Error = fmt.Errorf("failed to create directory %s: %w", parentDir, err)
 			progress.Completed = true
 			cm.sendProgress(progress)
 			return
 		}
-		targetPath = filepath.Join(ownerDir, repo.Name)
-	} else {
-		// Just use repo name in target directory
-		targetPath = filepath.Join(cm.targetDir, repo.Name)
 	}
 
 	// Check if directory already exists
@@ -144,6 +172,11 @@ func (cm *CloneManager) cloneWorker(ctx context.Context, repo *Repository) {
 		}
 	}
 
+	progress.Transport = "ssh"
+	if cloneOptions.URL == repo.CloneURL {
+		progress.Transport = "https"
+	}
+
 	_, err := git.PlainCloneContext(ctx, targetPath, false, cloneOptions)
 
 	progress.Progress = 1.0
@@ -164,31 +197,71 @@ func (cm *CloneManager) cloneWorker(ctx context.Context, repo *Repository) {
 
 func (cm *CloneManager) getSSHAuth() (*ssh.PublicKeys, error) {
 	if cm.sshKey == "" {
-		// Try default SSH key locations
-		homeDir, err := os.UserHomeDir()
+		keyPath, err := defaultSSHKey()
 		if err != nil {
 			return nil, err
 		}
+		cm.sshKey = keyPath
+	}
 
-		keyPaths := []string{
-			filepath.Join(homeDir, ".ssh", "id_rsa"),
-			filepath.Join(homeDir, ".ssh", "id_ed25519"),
-			filepath.Join(homeDir, ".ssh", "id_ecdsa"),
-		}
+	return ssh.NewPublicKeysFromFile("git", cm.sshKey, "")
+}
 
-		for _, path := range keyPaths {
-			if _, err := os.Stat(path); err == nil {
-				cm.sshKey = path
-				break
-			}
+// RemoteAuth picks credentials for a git remote URL: an SSH key for SSH
+// remotes (keyPath, or the first default key in ~/.ssh) and the token for
+// HTTPS remotes on GitHub. It returns nil when no credentials apply, so
+// other hosts use the git credential helper or anonymous access.
+func RemoteAuth(remoteURL, token, keyPath string) transport.AuthMethod {
+	if strings.HasPrefix(remoteURL, "git@") || strings.HasPrefix(remoteURL, "ssh://") {
+		if keyPath == "" {
+			keyPath, _ = defaultSSHKey()
+		}
+		if keyPath == "" {
+			return nil
+		}
+		auth, err := ssh.NewPublicKeysFromFile("git", keyPath, "")
+		if err != nil {
+			return nil
 		}
+		return auth
+	}
+
+	if token != "" && isGitHubHTTPS(remoteURL) {
+		return &http.BasicAuth{Username: "token", Password: token}
+	}
+	return nil
+}
 
-		if cm.sshKey == "" {
-			return nil, fmt.Errorf("no SSH key found")
+// isGitHubHTTPS reports whether remoteURL is an HTTPS URL on github.com, the
+// only host the token is for.
+func isGitHubHTTPS(remoteURL string) bool {
+	u, err := url.Parse(remoteURL)
+	if err != nil || u.Scheme != "https" {
+		return false
+	}
+	return strings.EqualFold(u.Hostname(), "github.com")
+}
+
+// defaultSSHKey returns the first key found in the default ~/.ssh locations
+func defaultSSHKey() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	keyPaths := []string{
+		filepath.Join(homeDir, ".ssh", "id_rsa"),
+		filepath.Join(homeDir, ".ssh", "id_ed25519"),
+		filepath.Join(homeDir, ".ssh", "id_ecdsa"),
+	}
+
+	for _, path := range keyPaths {
+		if _, err := os.Stat(path); err == nil {
+			return path, nil
 		}
 	}
 
-	return ssh.NewPublicKeysFromFile("git", cm.sshKey, "")
+	return "", fmt.Errorf("no SSH key found")
 }
 
 func (cm *CloneManager) sendProgress(progress CloneProgress) {
@@ -0,0 +1,330 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/internal/install"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// FileName is the history file kept in the config directory.
+const FileName = "history.jsonl"
+
+// MaxRuns is the number of runs kept; older runs are dropped on save.
+const MaxRuns = 200
+
+// LogsDirName is the directory in the config directory holding each run's
+// install logs, in a subdirectory named after the run ID.
+const LogsDirName = "logs"
+
+// Run actions
+const (
+	ActionClone   = "clone"
+	ActionInstall = "install"
+)
+
+// Run is one clone or install session.
+type Run struct {
+	ID         string    `json:"id"`
+	Action     string    `json:"action"`
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	TargetDir  string    `json:"target_dir,omitempty"`
+	Entries    []*Entry  `json:"entries"`
+}
+
+// Entry records what happened to one repository during a run.
+type Entry struct {
+	FullName      string             `json:"full_name"`
+	Repository    *github.Repository `json:"repository,omitempty"`
+	Path          string             `json:"path,omitempty"`
+	Transport     string             `json:"transport,omitempty"`
+	CloneDuration time.Duration      `json:"clone_duration,omitempty"`
+	CloneError    string             `json:"clone_error,omitempty"`
+	Skipped       bool               `json:"skipped,omitempty"`
+	Install       *InstallRecord     `json:"install,omitempty"`
+}
+
+// InstallRecord is the stored form of an install.InstallResult.
+type InstallRecord struct {
+	ProjectType string          `json:"project_type,omitempty"`
+	Success     bool            `json:"success"`
+	Duration    time.Duration   `json:"duration"`
+	Error       string          `json:"error,omitempty"`
+	Warnings    []string        `json:"warnings,omitempty"`
+	Commands    []CommandRecord `json:"commands,omitempty"`
+}
+
+// CommandRecord is the stored form of an install.CommandResult.
+type CommandRecord struct {
+	Command   string        `json:"command"`
+	Dir       string        `json:"dir,omitempty"`
+	Success   bool          `json:"success"`
+	ExitCode  int           `json:"exit_code"`
+	Duration  time.Duration `json:"duration"`
+	Error     string        `json:"error,omitempty"`
+	Sandboxed bool          `json:"sandboxed,omitempty"`
+	LogFile   string        `json:"log_file,omitempty"`
+}
+
+// NewRun starts a run record.
+func NewRun(action, targetDir string) *Run {
+	now := time.Now()
+	return &Run{
+		ID:        fmt.Sprintf("%s-%d", action, now.UnixNano()),
+		Action:    action,
+		StartedAt: now,
+		TargetDir: targetDir,
+	}
+}
+
+// NewInstallRecord converts an install result for storage.
+func NewInstallRecord(result install.InstallResult) *InstallRecord {
+	record := &InstallRecord{
+		ProjectType: result.ProjectType,
+		Success:     result.Success,
+		Duration:    result.Duration,
+		Error:       errorString(result.Error),
+		Warnings:    result.Warnings,
+	}
+	for _, cmd := range result.Commands {
+		record.Commands = append(record.Commands, CommandRecord{
+			Command:   cmd.Command,
+			Dir:       cmd.Dir,
+			Success:   cmd.Success,
+			ExitCode:  cmd.ExitCode,
+			Duration:  cmd.Duration,
+			Error:     errorString(cmd.Error),
+			Sandboxed: cmd.Sandboxed,
+			LogFile:   cmd.LogFile,
+		})
+	}
+	return record
+}
+
+// LogDir returns the directory for the install logs of the run with the
+// given ID. It is removed when the run is dropped from the history.
+func LogDir(runID string) (string, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, LogsDirName, runID), nil
+}
+
+// EntryByPath returns the entry cloned to path, if any.
+func (r *Run) EntryByPath(path string) *Entry {
+	for _, entry := range r.Entries {
+		if entry.Path == path {
+			return entry
+		}
+	}
+	return nil
+}
+
+// Failures counts entries whose clone or install failed.
+func (r *Run) Failures() int {
+	failures := 0
+	for _, entry := range r.Entries {
+		if entry.Failed() {
+			failures++
+		}
+	}
+	return failures
+}
+
+// Duration is how long the run took, or zero while it is unfinished.
+func (r *Run) Duration() time.Duration {
+	if r.FinishedAt.IsZero() {
+		return 0
+	}
+	return r.FinishedAt.Sub(r.StartedAt)
+}
+
+// Matches reports whether any repository in the run contains query,
+// ignoring case.
+func (r *Run) Matches(query string) bool {
+	if query == "" {
+		return true
+	}
+	query = strings.ToLower(query)
+	for _, entry := range r.Entries {
+		if strings.Contains(strings.ToLower(entry.FullName), query) {
+			return true
+		}
+	}
+	return false
+}
+
+// Repositories returns the repositories that can be cloned again.
+func (r *Run) Repositories() []*github.Repository {
+	var repos []*github.Repository
+	for _, entry := range r.Entries {
+		if entry.Repository != nil {
+			repos = append(repos, entry.Repository)
+		}
+	}
+	return repos
+}
+
+// Failed reports whether the clone or the install of the entry failed.
+// Repositories without a supported project type are not failures.
+func (e *Entry) Failed() bool {
+	return e.CloneError != "" || (e.Install != nil && !e.Install.Success && e.Install.ProjectType != "")
+}
+
+// Exists reports whether the clone is still on disk.
+func (e *Entry) Exists() bool {
+	if e.Path == "" {
+		return false
+	}
+	_, err := os.Stat(e.Path)
+	return err == nil
+}
+
+// Store keeps runs in a JSON Lines file, one run per line.
+type Store struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewStore opens the history file in the QuikGit config directory.
+func NewStore() (*Store, error) {
+	dir, err := config.GetConfigDir()
+	if err != nil {
+		return nil, err
+	}
+	return &Store{path: filepath.Join(dir, FileName)}, nil
+}
+
+// Path returns the location of the history file.
+func (s *Store) Path() string {
+	return s.path
+}
+
+// List returns the recorded runs, newest first.
+func (s *Store) List() ([]*Run, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runs[i].StartedAt.After(runs[j].StartedAt)
+	})
+	return runs, nil
+}
+
+// Save records run, replacing an earlier version with the same ID.
+func (s *Store) Save(run *Run) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	runs, err := s.read()
+	if err != nil {
+		return err
+	}
+
+	replaced := false
+	for i, existing := range runs {
+		if existing.ID == run.ID {
+			runs[i] = run
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		runs = append(runs, run)
+	}
+	var dropped []*Run
+	if len(runs) > MaxRuns {
+		dropped = runs[:len(runs)-MaxRuns]
+		runs = runs[len(runs)-MaxRuns:]
+	}
+
+	if err := s.write(runs); err != nil {
+		return err
+	}
+	for _, run := range dropped {
+		// IDs come from the file; never remove more than one run's logs
+		if run.ID != filepath.Base(run.ID) || strings.Trim(run.ID, ".") == "" {
+			continue
+		}
+		os.RemoveAll(filepath.Join(filepath.Dir(s.path), LogsDirName, run.ID))
+	}
+	return nil
+}
+
+func (s *Store) read() ([]*Run, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history: %w", err)
+	}
+	defer file.Close()
+
+	var runs []*Run
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+		var run Run
+		if err := json.Unmarshal(line, &run); err != nil {
+			// Skip lines written by a crashed or newer version
+			continue
+		}
+		runs = append(runs, &run)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return runs, nil
+}
+
+func (s *Store) write(runs []*Run) error {
+	tmp := s.path + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	encoder := json.NewEncoder(file)
+	for _, run := range runs {
+		if err := encoder.Encode(run); err != nil {
+			file.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("failed to write history: %w", err)
+		}
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return os.Rename(tmp, s.path)
+}
+
+func errorString(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
@@ -0,0 +1,116 @@
+// Package hooks collects the user-defined commands run at points of the
+// clone and install pipeline: from the configuration, for every repository
+// or those of an owner, and from a repository's own .quikgit/hooks.yaml.
+package hooks
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// Stages, in the order they run.
+const (
+	PostClone   = "post_clone"
+	PreInstall  = "pre_install"
+	PostInstall = "post_install"
+)
+
+// RepoFileNames are the files a repository defines its hooks in, checked in
+// order. They are kept apart from the .quikgit.yaml workspace profile, so a
+// repository's hooks never act as the user's configuration, nor a profile
+// in a parent directory as hooks.
+var RepoFileNames = []string{".quikgit/hooks.yaml", ".quikgit/hooks.yml"}
+
+// Hook is one shell command of a stage.
+type Hook struct {
+	Stage string
+	Run   string
+	// Source is where the hook is defined: "config", "org acme" or the
+	// repository file.
+	Source string
+	// FromRepository is set for hooks defined by the repository itself,
+	// which are held to the trust rules of its install scripts: confirmed
+	// for untrusted repositories, and skipped when scripts are disabled.
+	FromRepository bool
+}
+
+// repoFile is the format of .quikgit/hooks.yaml.
+type repoFile struct {
+	Hooks config.HookStages `yaml:"hooks"`
+}
+
+// Load returns the hooks of stage for the repository at path, whose GitHub
+// name is fullName ("owner/repo", or "" when unknown): the global ones, then
+// those of its owner, then its own.
+func Load(cfg config.HooksConfig, path, fullName, stage string) ([]Hook, error) {
+	var hooks []Hook
+	for _, run := range cfg.Global().Stage(stage) {
+		hooks = append(hooks, Hook{Stage: stage, Run: run, Source: "config"})
+	}
+	if owner, _, found := strings.Cut(fullName, "/"); found {
+		for _, run := range cfg.Owner(owner).Stage(stage) {
+			hooks = append(hooks, Hook{Stage: stage, Run: run, Source: "org " + owner})
+		}
+	}
+
+	stages, name, err := readRepoFile(path)
+	if err != nil {
+		return hooks, err
+	}
+	for _, run := range stages.Stage(stage) {
+		hooks = append(hooks, Hook{Stage: stage, Run: run, Source: name, FromRepository: true})
+	}
+	return hooks, nil
+}
+
+func readRepoFile(path string) (config.HookStages, string, error) {
+	for _, name := range RepoFileNames {
+		data, err := os.ReadFile(filepath.Join(path, filepath.FromSlash(name)))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return config.HookStages{}, name, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+
+		var file repoFile
+		if err := yaml.Unmarshal(data, &file); err != nil {
+			return config.HookStages{}, name, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		return file.Hooks, name, nil
+	}
+	return config.HookStages{}, "", nil
+}
+
+// Command returns the hook as the index-th command of its stage, run by the
+// shell. Hooks from the configuration are the user's own and run even with
+// scripts disabled; the repository's do not.
+func (h Hook) Command(index int) detect.Command {
+	cmd := detect.Command{
+		Name:        fmt.Sprintf("%s-%d", strings.ReplaceAll(h.Stage, "_", "-"), index+1),
+		Command:     "sh",
+		Args:        []string{"-c", h.Run},
+		Description: "Hook from " + h.Source,
+		Required:    true,
+	}
+	if runtime.GOOS == "windows" {
+		cmd.Command, cmd.Args = "cmd", []string{"/C", h.Run}
+	}
+	if !h.FromRepository {
+		cmd.NoScripts = &detect.NoScripts{}
+	}
+	return cmd
+}
+
+// StageName describes a stage, e.g. "post-install hooks".
+func StageName(stage string) string {
+	return strings.ReplaceAll(stage, "_", "-") + " hooks"
+}
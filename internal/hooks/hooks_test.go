@@ -0,0 +1,64 @@
+package hooks
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoad(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, ".quikgit", "hooks.yaml"), "hooks:\n  post_install:\n    - make db-migrate\n")
+	// A workspace profile in the repository is not read as hooks
+	writeFile(t, filepath.Join(repo, config.WorkspaceFileName), "hooks:\n  post_install:\n    - echo profile\n")
+
+	cfg := config.HooksConfig{
+		PostInstall: []string{"echo global"},
+		Orgs:        map[string]config.HookStages{"Acme": {PostInstall: []string{"echo acme"}}},
+	}
+	hooks, err := Load(cfg, repo, "acme/tool", PostInstall)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	want := []Hook{
+		{Stage: PostInstall, Run: "echo global", Source: "config"},
+		{Stage: PostInstall, Run: "echo acme", Source: "org acme"},
+		{Stage: PostInstall, Run: "make db-migrate", Source: ".quikgit/hooks.yaml", FromRepository: true},
+	}
+	if len(hooks) != len(want) {
+		t.Fatalf("hooks = %+v, want %+v", hooks, want)
+	}
+	for i := range want {
+		if hooks[i] != want[i] {
+			t.Errorf("hook %d = %+v, want %+v", i, hooks[i], want[i])
+		}
+	}
+
+	// Repository hooks are held back with scripts disabled
+	if hooks[0].Command(0).NoScripts == nil || hooks[2].Command(2).NoScripts != nil {
+		t.Errorf("only configured hooks should run with scripts disabled")
+	}
+}
+
+func TestLoadWithoutRepositoryFile(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, config.WorkspaceFileName), "hooks:\n  pre_install:\n    - echo profile\n")
+
+	hooks, err := Load(config.HooksConfig{}, repo, "", PreInstall)
+	if err != nil || len(hooks) != 0 {
+		t.Errorf("Load = %+v, %v, want no hooks", hooks, err)
+	}
+}
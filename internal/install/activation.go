@@ -0,0 +1,161 @@
+package install
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// Version manager choices for a tool; any other value names a manager.
+const (
+	VersionManagerAuto = "auto"
+	VersionManagerOff  = "off"
+)
+
+// versionManagers lists, per tool, the managers tried by "auto" in order.
+var versionManagers = map[string][]string{
+	"node":   {"mise", "asdf", "nvm"},
+	"python": {"mise", "asdf", "pyenv"},
+	"go":     {"mise", "asdf"},
+	"rust":   {"mise", "asdf", "rustup"},
+	"ruby":   {"mise", "asdf"},
+}
+
+// asdfPlugins names the asdf plugin of each tool where it differs.
+var asdfPlugins = map[string]string{
+	"node": "nodejs",
+	"go":   "golang",
+}
+
+// Plain versions can be handed to a version manager; ranges such as
+// ">=18 <21" cannot.
+var plainVersion = regexp.MustCompile(`^\d+(\.\d+)*$`)
+
+// Activation runs a step's commands under a version manager with the
+// project's pinned toolchain version.
+type Activation struct {
+	Manager string // mise, asdf, nvm, pyenv or rustup
+	Tool    string
+	Version string
+	Install bool // Install the version first when missing
+}
+
+// activationFor picks the version manager for tool given the user's choice,
+// or returns nil when none is wanted, available or usable with the pins.
+func activationFor(tool, choice string, toolchains []detect.Toolchain, install bool) *Activation {
+	if choice == VersionManagerOff || len(toolchains) == 0 {
+		return nil
+	}
+
+	candidates := versionManagers[tool]
+	if choice != "" && choice != VersionManagerAuto {
+		candidates = []string{choice}
+	}
+
+	for _, manager := range candidates {
+		if !managerAvailable(manager) {
+			continue
+		}
+		for _, toolchain := range toolchains {
+			version := strings.TrimPrefix(toolchain.Required, "v")
+			if plainVersion.MatchString(version) || (manager == "nvm" && strings.HasPrefix(version, "lts/")) {
+				return &Activation{Manager: manager, Tool: tool, Version: version, Install: install}
+			}
+		}
+	}
+	return nil
+}
+
+func managerAvailable(manager string) bool {
+	if manager == "nvm" {
+		_, err := os.Stat(nvmScript())
+		return err == nil
+	}
+	_, err := exec.LookPath(manager)
+	return err == nil
+}
+
+// nvm is a shell function, loaded from nvm.sh in NVM_DIR.
+func nvmScript() string {
+	dir := os.Getenv("NVM_DIR")
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".nvm")
+	}
+	return filepath.Join(dir, "nvm.sh")
+}
+
+const nvmShell = `. "$NVM_DIR/nvm.sh" && nvm "$@"`
+
+func (a *Activation) plugin() string {
+	if plugin, ok := asdfPlugins[a.Tool]; ok && a.Manager == "asdf" {
+		return plugin
+	}
+	return a.Tool
+}
+
+// String describes the activation, e.g. "node 18 via mise".
+func (a *Activation) String() string {
+	return a.Tool + " " + a.Version + " via " + a.Manager
+}
+
+// SetupCommand installs the pinned version, if it is missing and installing
+// is enabled.
+func (a *Activation) SetupCommand() (detect.Command, bool) {
+	cmd := detect.Command{
+		Name:        a.Manager + "-install-" + a.Tool,
+		Description: "Install " + a.Tool + " " + a.Version + " with " + a.Manager,
+		Required:    true,
+		NoScripts:   &detect.NoScripts{},
+	}
+
+	switch a.Manager {
+	case "mise":
+		cmd.Command, cmd.Args = "mise", []string{"install", a.Tool + "@" + a.Version}
+	case "asdf":
+		cmd.Command, cmd.Args = "asdf", []string{"install", a.plugin(), a.Version}
+	case "nvm":
+		cmd.Command, cmd.Args = "bash", []string{"-c", nvmShell, "nvm", "install", a.Version}
+		cmd.Env = []string{"NVM_DIR=" + filepath.Dir(nvmScript())}
+	case "pyenv":
+		cmd.Command, cmd.Args = "pyenv", []string{"install", "--skip-existing", a.Version}
+	case "rustup":
+		cmd.Command, cmd.Args = "rustup", []string{"toolchain", "install", a.Version}
+	default:
+		return cmd, false
+	}
+	return cmd, a.Install
+}
+
+// Wrap returns cmd run through the version manager with the pinned version.
+func (a *Activation) Wrap(cmd detect.Command) detect.Command {
+	wrapped := cmd
+	wrapped.Env = append([]string(nil), cmd.Env...)
+	command := append([]string{cmd.Command}, cmd.Args...)
+
+	switch a.Manager {
+	case "mise":
+		wrapped.Command = "mise"
+		wrapped.Args = append([]string{"exec", a.Tool + "@" + a.Version, "--"}, command...)
+	case "asdf":
+		wrapped.Command = "asdf"
+		wrapped.Args = append([]string{"exec"}, command...)
+		wrapped.Env = append(wrapped.Env, "ASDF_"+strings.ToUpper(a.plugin())+"_VERSION="+a.Version)
+	case "nvm":
+		wrapped.Command = "bash"
+		wrapped.Args = append([]string{"-c", nvmShell, "nvm", "exec", "--silent", a.Version}, command...)
+		wrapped.Env = append(wrapped.Env, "NVM_DIR="+filepath.Dir(nvmScript()))
+	case "pyenv":
+		wrapped.Command = "pyenv"
+		wrapped.Args = append([]string{"exec"}, command...)
+		wrapped.Env = append(wrapped.Env, "PYENV_VERSION="+a.Version)
+	case "rustup":
+		wrapped.Command = "rustup"
+		wrapped.Args = append([]string{"run", a.Version}, command...)
+	}
+	return wrapped
+}
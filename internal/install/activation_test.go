@@ -0,0 +1,147 @@
+package install
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+func TestActivationWrap(t *testing.T) {
+	t.Setenv("NVM_DIR", "/home/user/.nvm")
+	cmd := detect.Command{Name: "npm-ci", Command: "npm", Args: []string{"ci", "--ignore-scripts"}, Env: []string{"CI=1"}}
+
+	tests := []struct {
+		activation Activation
+		command    string
+		env        []string
+	}{
+		{
+			Activation{Manager: "mise", Tool: "node", Version: "20.11.0"},
+			"mise exec node@20.11.0 -- npm ci --ignore-scripts",
+			[]string{"CI=1"},
+		},
+		{
+			Activation{Manager: "asdf", Tool: "node", Version: "20.11.0"},
+			"asdf exec npm ci --ignore-scripts",
+			[]string{"CI=1", "ASDF_NODEJS_VERSION=20.11.0"},
+		},
+		{
+			Activation{Manager: "asdf", Tool: "python", Version: "3.12.1"},
+			"asdf exec npm ci --ignore-scripts",
+			[]string{"CI=1", "ASDF_PYTHON_VERSION=3.12.1"},
+		},
+		{
+			Activation{Manager: "nvm", Tool: "node", Version: "lts/iron"},
+			`bash -c . "$NVM_DIR/nvm.sh" && nvm "$@" nvm exec --silent lts/iron npm ci --ignore-scripts`,
+			[]string{"CI=1", "NVM_DIR=/home/user/.nvm"},
+		},
+		{
+			Activation{Manager: "pyenv", Tool: "python", Version: "3.11.7"},
+			"pyenv exec npm ci --ignore-scripts",
+			[]string{"CI=1", "PYENV_VERSION=3.11.7"},
+		},
+		{
+			Activation{Manager: "rustup", Tool: "rust", Version: "1.75.0"},
+			"rustup run 1.75.0 npm ci --ignore-scripts",
+			[]string{"CI=1"},
+		},
+	}
+
+	for _, tt := range tests {
+		wrapped := tt.activation.Wrap(cmd)
+		if got := commandLine(wrapped); got != tt.command {
+			t.Errorf("%s: command = %s, want %s", &tt.activation, got, tt.command)
+		}
+		if !slices.Equal(wrapped.Env, tt.env) {
+			t.Errorf("%s: env = %q, want %q", &tt.activation, wrapped.Env, tt.env)
+		}
+		if wrapped.Name != cmd.Name || wrapped.NoScripts != cmd.NoScripts {
+			t.Errorf("%s: wrapping changed the command's name or no-scripts form", &tt.activation)
+		}
+	}
+
+	// The wrapped commands' environments are their own
+	if !slices.Equal(cmd.Env, []string{"CI=1"}) {
+		t.Errorf("Wrap changed the original environment: %q", cmd.Env)
+	}
+}
+
+// TestActivationNvmQuoting runs a wrapped command through bash with a stub
+// nvm, checking that arguments reach the command as they are, without being
+// split or expanded by the shell.
+func TestActivationNvmQuoting(t *testing.T) {
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash is not installed")
+	}
+	dir := t.TempDir()
+	stub := "nvm() { [ \"$1\" = exec ] && shift 3; \"$@\"; }\n"
+	if err := os.WriteFile(filepath.Join(dir, "nvm.sh"), []byte(stub), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("NVM_DIR", dir)
+
+	args := []string{"two words", `"quoted"`, "it's", "$HOME", "`id`", "a;b", "*", ""}
+	activation := Activation{Manager: "nvm", Tool: "node", Version: "20"}
+	wrapped := activation.Wrap(detect.Command{Command: "printf", Args: append([]string{`%s\n`}, args...)})
+
+	run := exec.Command(wrapped.Command, wrapped.Args...)
+	run.Env = append(os.Environ(), wrapped.Env...)
+	out, err := run.Output()
+	if err != nil {
+		t.Fatalf("%s: %v", commandLine(wrapped), err)
+	}
+	if got := strings.Split(strings.TrimSuffix(string(out), "\n"), "\n"); !slices.Equal(got, args) {
+		t.Errorf("arguments = %q, want %q", got, args)
+	}
+}
+
+func TestActivationFor(t *testing.T) {
+	// Only the stubbed managers are found
+	bin := t.TempDir()
+	for _, name := range []string{"asdf", "pyenv"} {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin)
+	t.Setenv("NVM_DIR", t.TempDir())
+
+	pins := func(versions ...string) []detect.Toolchain {
+		var toolchains []detect.Toolchain
+		for _, version := range versions {
+			toolchains = append(toolchains, detect.Toolchain{Tool: "node", Required: version})
+		}
+		return toolchains
+	}
+
+	tests := []struct {
+		name       string
+		tool       string
+		choice     string
+		toolchains []detect.Toolchain
+		want       string
+	}{
+		{"auto skips missing mise", "node", VersionManagerAuto, pins("v18.17.1"), "node 18.17.1 via asdf"},
+		{"first plain pin", "python", "", pins(">=3.10", "3.12"), "python 3.12 via asdf"},
+		{"chosen manager", "python", "pyenv", pins("3.12"), "python 3.12 via pyenv"},
+		{"ranges cannot be activated", "node", VersionManagerAuto, pins(">=18 <21"), ""},
+		{"chosen manager missing", "node", "mise", pins("20"), ""},
+		{"off", "node", VersionManagerOff, pins("20"), ""},
+		{"no pins", "node", VersionManagerAuto, nil, ""},
+	}
+	for _, tt := range tests {
+		activation := activationFor(tt.tool, tt.choice, tt.toolchains, true)
+		got := ""
+		if activation != nil {
+			got = activation.String()
+		}
+		if got != tt.want {
+			t.Errorf("%s: activation = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,197 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// Install strategies decide where dependencies are installed: on the host,
+// or in the container a repository defines for development.
+const (
+	StrategyHost      = "host"
+	StrategyContainer = "container"
+	StrategyAuto      = "auto"
+)
+
+// Container runtimes.
+const (
+	RuntimeAuto   = "auto"
+	RuntimeDocker = "docker"
+	RuntimePodman = "podman"
+)
+
+// SetStrategy sets where dependencies are installed: host, container for
+// repositories with a dev container, Compose file or Dockerfile, or auto
+// for the container when a runtime is reachable, which only replaces the
+// host install for a dev container with lifecycle commands. runtime is
+// docker, podman or auto.
+func (m *Manager) SetStrategy(strategy, runtime string) {
+	m.strategy = strategy
+	m.runtime = runtime
+}
+
+// FindContainerRuntime returns the container runtime to use: Docker when
+// its socket is reachable, otherwise Podman, which needs no daemon.
+// preferred restricts it to one of them unless it is auto.
+func FindContainerRuntime(preferred string) (string, error) {
+	if preferred != RuntimePodman {
+		if _, err := exec.LookPath("docker"); err == nil && dockerSocket() {
+			return RuntimeDocker, nil
+		}
+		if preferred == RuntimeDocker {
+			return "", fmt.Errorf("docker not found or its socket is not reachable")
+		}
+	}
+	if _, err := exec.LookPath("podman"); err == nil {
+		return RuntimePodman, nil
+	}
+	if preferred == RuntimePodman {
+		return "", fmt.Errorf("podman not found")
+	}
+	return "", fmt.Errorf("neither a reachable Docker socket nor podman found")
+}
+
+// dockerSocket reports whether the Docker daemon can be reached: through
+// DOCKER_HOST, or one of the usual socket paths.
+func dockerSocket() bool {
+	if os.Getenv("DOCKER_HOST") != "" {
+		return true
+	}
+	sockets := []string{"/var/run/docker.sock"}
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		sockets = append(sockets, filepath.Join(dir, "docker.sock"))
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		sockets = append(sockets, filepath.Join(home, ".docker", "run", "docker.sock"))
+	}
+	for _, socket := range sockets {
+		if info, err := os.Stat(socket); err == nil && info.Mode()&os.ModeSocket != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// containerStep returns the step installing the repository in its
+// container setup, or nil when the strategy installs on the host. With the
+// container strategy, or under auto for a dev container with lifecycle
+// commands, the step replaces the host install: replaces is true and the
+// projects detected are listed as matched. Otherwise the image is built in
+// addition to the host install.
+func (m *Manager) containerStep(plan *Plan, projects []*detect.ProjectType) (step *Step, replaces bool, err error) {
+	if m.strategy != StrategyContainer && m.strategy != StrategyAuto {
+		return nil, false, nil
+	}
+
+	setup, err := detect.FindContainerSetup(plan.Path)
+	if err != nil || setup == nil {
+		return nil, false, err
+	}
+	runtime, err := FindContainerRuntime(m.runtime)
+	if err != nil {
+		if m.strategy == StrategyAuto {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("container strategy: %w", err)
+	}
+
+	step = &Step{
+		Ecosystem: "Container",
+		Project:   setup.Name(),
+		Container: runtime,
+		Commands:  containerCommands(runtime, plan, setup),
+	}
+	replaces = m.strategy == StrategyContainer || setup.ReplacesHost()
+	if !replaces {
+		return step, false, nil
+	}
+	for _, project := range projects {
+		name := project.Name
+		if project.Dir != "" {
+			name = fmt.Sprintf("%s (%s)", project.Name, project.Dir)
+		}
+		step.Matched = append(step.Matched, name)
+	}
+	return step, true, nil
+}
+
+// imageTagInvalid matches what an image name cannot contain.
+var imageTagInvalid = regexp.MustCompile(`[^a-z0-9._-]+`)
+
+// containerCommands builds the setup's image, then runs its lifecycle
+// commands in a container with the repository mounted.
+func containerCommands(runtime string, plan *Plan, setup *detect.ContainerSetup) []detect.Command {
+	tag := "quikgit/" + strings.Trim(imageTagInvalid.ReplaceAllString(strings.ToLower(plan.Repository), "-"), "-.")
+	workspace := setup.WorkspaceFolder
+	if workspace == "" {
+		workspace = path.Join("/workspaces", plan.Repository)
+	}
+
+	var compose []string
+	for _, file := range setup.ComposeFiles {
+		compose = append(compose, "-f", file)
+	}
+
+	var commands []detect.Command
+	image := setup.Image
+	switch {
+	case len(setup.ComposeFiles) > 0:
+		commands = append(commands, detect.Command{
+			Name:        "container-build",
+			Command:     runtime,
+			Args:        append(append([]string{"compose"}, compose...), "build"),
+			Description: "Build the Compose services",
+			Required:    true,
+			Class:       ClassBuild,
+		})
+	case setup.Dockerfile != "":
+		image = tag
+		commands = append(commands, detect.Command{
+			Name:        "container-build",
+			Command:     runtime,
+			Args:        []string{"build", "-t", tag, "-f", setup.Dockerfile, setup.Context},
+			Description: "Build the container image",
+			Required:    true,
+			Class:       ClassBuild,
+		})
+	case image != "":
+		commands = append(commands, detect.Command{
+			Name:        "container-pull",
+			Command:     runtime,
+			Args:        []string{"pull", image},
+			Description: "Pull the container image",
+			Required:    true,
+			Class:       ClassNetwork,
+			NoScripts:   &detect.NoScripts{},
+		})
+	}
+
+	for i, line := range setup.Commands {
+		var args []string
+		if len(setup.ComposeFiles) > 0 {
+			args = append(append([]string{"compose"}, compose...), "run", "--rm")
+			if setup.WorkspaceFolder != "" {
+				args = append(args, "-w", workspace)
+			}
+			args = append(args, setup.Service)
+		} else {
+			args = []string{"run", "--rm", "-v", plan.Path + ":" + workspace, "-w", workspace, image}
+		}
+		commands = append(commands, detect.Command{
+			Name:        fmt.Sprintf("container-setup-%d", i+1),
+			Command:     runtime,
+			Args:        append(args, "sh", "-c", line),
+			Description: "Run the dev container's setup command",
+			Required:    true,
+			Class:       ClassBuild,
+		})
+	}
+	return commands
+}
@@ -3,16 +3,21 @@ package install
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
 	"syscall"
 	"time"
 
 	"github.com/lvcasx1/quikgit/internal/detect"
+	"github.com/lvcasx1/quikgit/internal/hooks"
+	"github.com/lvcasx1/quikgit/internal/trust"
+	"github.com/lvcasx1/quikgit/pkg/config"
 )
 
 type InstallProgress struct {
@@ -24,31 +29,99 @@ type InstallProgress struct {
 	Error       error
 	Completed   bool
 	Duration    time.Duration
+	Sandboxed   bool   // The running command is sandboxed
+	LogFile     string // Log of the running command, if logs are kept
+	Queued      bool   // The command waits for the scheduler
 }
 
+// ErrNoProjects is the error of a repository with nothing to install, which
+// is not a failure.
+var ErrNoProjects = errors.New("no supported project type detected")
+
 type InstallResult struct {
 	Repository  string
 	ProjectType string
 	Success     bool
 	Commands    []CommandResult
+	Projects    []ProjectResult // One per plan step, in order
+	Warnings    []string
+	EnvFiles    []string // Environment files created, relative to the repository root
+	Modified    []string // Tracked files the install changed, relative to the repository root
+	Untracked   []string // Files the install created that git does not ignore
 	Duration    time.Duration
 	Error       error
+
+	// revert holds the file modes and created directories RevertChanges
+	// puts back, and the files it leaves
+	revert revertState
+}
+
+// ProjectResult is the outcome of one install plan step.
+type ProjectResult struct {
+	Name       string
+	Dir        string
+	Ecosystem  string
+	VirtualEnv string // Absolute path of the Python environment, if any
+	Sandboxed  bool   // Every command ran sandboxed
+	Success    bool
+	Commands   []CommandResult
+	Duration   time.Duration
+	Error      error
 }
 
 type CommandResult struct {
-	Command  string
-	Success  bool
-	Output   string
-	Error    error
-	Duration time.Duration
-	ExitCode int
+	Command   string
+	Dir       string // Project directory relative to the repository root
+	Success   bool
+	Output    string
+	Error     error
+	Duration  time.Duration
+	ExitCode  int
+	Sandboxed bool
+	LogFile   string // Full output with timestamps, if logs are kept
 }
 
+// Toolchain policies decide what happens when a project's pinned toolchain
+// version is not the one installed.
+const (
+	ToolchainIgnore = "ignore"
+	ToolchainWarn   = "warn"
+	ToolchainStop   = "stop"
+)
+
+// Untrusted policies decide what happens to repositories outside the user's
+// account, organizations and allowlist.
+const (
+	UntrustedConfirm   = "confirm"
+	UntrustedNoScripts = "no-scripts"
+	UntrustedSkip      = "skip"
+)
+
 type Manager struct {
-	progress    chan InstallProgress
-	concurrent  int
-	timeout     time.Duration
-	skipOnError bool
+	progress        chan InstallProgress
+	timeout         time.Duration
+	skipOnError     bool
+	detectDepth     int
+	toolchainPolicy string
+	versionManagers map[string]string
+	installVersions bool
+	trust           *trust.Checker
+	untrusted       string
+	sandbox         *Sandbox
+	logDir          string
+	scheduler       *Scheduler
+	hooks           config.HooksConfig
+	afterClone      bool
+	envMode         string
+	envValues       map[string]string
+	strategy        string
+	runtime         string
+	verify          bool
+	offline         bool
+	findLinks       string
+
+	versionsMu sync.Mutex
+	versions   map[string]string
 }
 
 func NewManager(concurrent int, timeout time.Duration) *Manager {
@@ -60,9 +133,14 @@ func NewManager(concurrent int, timeout time.Duration) *Manager {
 	}
 
 	return &Manager{
-		progress:   make(chan InstallProgress, 100),
-		concurrent: concurrent,
-		timeout:    timeout,
+		progress:        make(chan InstallProgress, 100),
+		scheduler:       NewScheduler(Limits{Commands: concurrent}),
+		timeout:         timeout,
+		detectDepth:     detect.DefaultMaxDepth,
+		toolchainPolicy: ToolchainWarn,
+		untrusted:       UntrustedConfirm,
+		strategy:        StrategyHost,
+		versions:        make(map[string]string),
 	}
 }
 
@@ -70,61 +148,143 @@ func (m *Manager) SetSkipOnError(skip bool) {
 	m.skipOnError = skip
 }
 
+// SetDetectDepth sets how deep below each repository root nested projects
+// are looked for.
+func (m *Manager) SetDetectDepth(depth int) {
+	m.detectDepth = depth
+}
+
+// SetVersionManagers sets, per tool, the version manager commands run
+// through when a project pins a version: "auto", "off" or a manager name.
+// With install set, missing versions are installed first.
+func (m *Manager) SetVersionManagers(managers map[string]string, install bool) {
+	m.versionManagers = managers
+	m.installVersions = install
+}
+
+// SetToolchainPolicy sets whether toolchain mismatches are ignored, reported
+// as warnings or stop the repository's install.
+func (m *Manager) SetToolchainPolicy(policy string) {
+	m.toolchainPolicy = policy
+}
+
+// SetTrust sets the checker deciding which repositories are trusted, and the
+// policy for the others. Without a checker every repository is trusted.
+func (m *Manager) SetTrust(checker *trust.Checker, untrusted string) {
+	m.trust = checker
+	m.untrusted = untrusted
+}
+
+// SetSandbox runs commands in sandbox, unless it is nil or off.
+func (m *Manager) SetSandbox(sandbox *Sandbox) {
+	m.sandbox = sandbox
+}
+
+// Sandbox returns the sandbox set with SetSandbox, or nil.
+func (m *Manager) Sandbox() *Sandbox {
+	return m.sandbox
+}
+
+// SetLimits sets what commands run at once, across repositories. By
+// default only the number of commands is limited, to the concurrency.
+func (m *Manager) SetLimits(limits Limits) {
+	m.scheduler = NewScheduler(limits)
+}
+
+// Scheduler returns the scheduler deciding when commands run.
+func (m *Manager) Scheduler() *Scheduler {
+	return m.scheduler
+}
+
+// SetHooks sets the configured hooks; the repositories' own are read from
+// their .quikgit/hooks.yaml. Post-clone hooks only run with afterClone set,
+// when the repositories were just cloned.
+func (m *Manager) SetHooks(hooks config.HooksConfig, afterClone bool) {
+	m.hooks = hooks
+	m.afterClone = afterClone
+}
+
+// SetVerify plans the commands that check an install, such as go build.
+func (m *Manager) SetVerify(verify bool) {
+	m.verify = verify
+}
+
+// SetLogDir keeps the output of every command in a log file below dir, one
+// directory per repository. Without it, output is only kept in memory.
+func (m *Manager) SetLogDir(dir string) {
+	m.logDir = dir
+}
+
+// Trust returns the checker set with SetTrust, or nil.
+func (m *Manager) Trust() *trust.Checker {
+	return m.trust
+}
+
 func (m *Manager) GetProgressChannel() <-chan InstallProgress {
 	return m.progress
 }
 
 func (m *Manager) InstallDependencies(ctx context.Context, repositories []string) ([]InstallResult, error) {
-	if len(repositories) == 0 {
+	return m.InstallPlans(ctx, m.Plans(repositories))
+}
+
+// InstallPlans runs previously built install plans, typically after the user
+// has confirmed them.
+func (m *Manager) InstallPlans(ctx context.Context, plans []*Plan) ([]InstallResult, error) {
+	if len(plans) == 0 {
 		close(m.progress)
 		return nil, nil
 	}
 
-	results := make([]InstallResult, len(repositories))
-	semaphore := make(chan struct{}, m.concurrent)
+	// Every repository starts at once; the scheduler decides when each
+	// command runs, quickest repositories first
+	order := make([]int, len(plans))
+	for i := range order {
+		order[i] = i
+	}
+	estimates := make([]int, len(plans))
+	for i, plan := range plans {
+		estimates[i] = plan.Estimate()
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return estimates[a] - estimates[b]
+	})
+
+	results := make([]InstallResult, len(plans))
 	var wg sync.WaitGroup
 
-	for i, repo := range repositories {
+	for priority, index := range order {
 		wg.Add(1)
-		go func(index int, repoPath string) {
+		go func(index, priority int) {
 			defer wg.Done()
-
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
-
-			results[index] = m.installForRepository(ctx, repoPath)
-		}(i, repo)
+			results[index] = m.installPlan(ctx, plans[index], priority)
+		}(index, priority)
 	}
 
 	wg.Wait()
+	m.sandbox.Close()
 	close(m.progress)
 
 	return results, nil
 }
 
 func (m *Manager) InstallForRepository(ctx context.Context, repositoryPath string) InstallResult {
-	return m.installForRepository(ctx, repositoryPath)
+	return m.installPlan(ctx, m.Plan(repositoryPath), 0)
 }
 
-func (m *Manager) installForRepository(ctx context.Context, repositoryPath string) InstallResult {
+// installPlan runs plan; its commands are scheduled with the given
+// priority, lower first.
+func (m *Manager) installPlan(ctx context.Context, plan *Plan, priority int) InstallResult {
 	start := time.Now()
 
-	repoName := filepath.Base(repositoryPath)
+	repoName := plan.Repository
 	result := InstallResult{
 		Repository: repoName,
 		Commands:   []CommandResult{},
 	}
 
-	m.sendProgress(InstallProgress{
-		Repository: repoName,
-		Status:     "Detecting project type",
-	})
-
-	// Detect project type
-	detector := detect.NewDetector(repositoryPath)
-	projectType, err := detector.DetectPrimaryProject()
-	if err != nil {
-		result.Error = fmt.Errorf("failed to detect project type: %w", err)
+	if plan.Error != nil {
+		result.Error = plan.Error
 		result.Duration = time.Since(start)
 
 		m.sendProgress(InstallProgress{
@@ -137,8 +297,20 @@ func (m *Manager) installForRepository(ctx context.Context, repositoryPath strin
 		return result
 	}
 
-	if projectType == nil {
-		result.Error = fmt.Errorf("no supported project type detected")
+	// Only what the install changes in the working tree is reported
+	snapshot, err := snapshotWorktree(plan.Path)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "git status before the install failed: "+err.Error())
+	}
+
+	// Environment files are in place before anything runs, even for
+	// repositories with nothing to install
+	created, envWarnings := m.writeEnvFiles(plan)
+	result.EnvFiles = created
+	result.Warnings = append(result.Warnings, envWarnings...)
+
+	if len(plan.Steps) == 0 {
+		result.Error = ErrNoProjects
 		result.Duration = time.Since(start)
 
 		m.sendProgress(InstallProgress{
@@ -150,33 +322,103 @@ func (m *Manager) installForRepository(ctx context.Context, repositoryPath strin
 		return result
 	}
 
-	result.ProjectType = projectType.Name
+	result.ProjectType = plan.Describe()
+
+	if m.sandbox != nil && m.sandbox.Mode == SandboxRequired {
+		if err := m.sandbox.Available(); err != nil {
+			result.Error = fmt.Errorf("sandbox required: %w", err)
+			result.Duration = time.Since(start)
+
+			m.sendProgress(InstallProgress{
+				Repository:  repoName,
+				ProjectType: result.ProjectType,
+				Status:      "Failed",
+				Error:       result.Error,
+				Completed:   true,
+			})
+
+			return result
+		}
+	}
+
+	if plan.CommandCount() == 0 {
+		result.Error = fmt.Errorf("all commands skipped")
+		result.Duration = time.Since(start)
+
+		m.sendProgress(InstallProgress{
+			Repository:  repoName,
+			ProjectType: result.ProjectType,
+			Status:      "Skipped - all commands skipped",
+			Completed:   true,
+		})
+
+		return result
+	}
+
+	if mismatches := plan.Mismatches(); len(mismatches) > 0 {
+		var warnings []string
+		for _, toolchain := range mismatches {
+			warnings = append(warnings, toolchain.String())
+		}
+		result.Warnings = append(result.Warnings, warnings...)
+
+		if m.toolchainPolicy == ToolchainStop {
+			result.Error = fmt.Errorf("toolchain mismatch: %s", strings.Join(warnings, "; "))
+			result.Duration = time.Since(start)
+
+			m.sendProgress(InstallProgress{
+				Repository:  repoName,
+				ProjectType: result.ProjectType,
+				Status:      "Failed",
+				Error:       result.Error,
+				Completed:   true,
+			})
+
+			return result
+		}
+
+		m.sendProgress(InstallProgress{
+			Repository:  repoName,
+			ProjectType: result.ProjectType,
+			Status:      "Warning: " + strings.Join(warnings, "; "),
+		})
+	}
 
 	m.sendProgress(InstallProgress{
 		Repository:  repoName,
-		ProjectType: projectType.Name,
-		Status:      fmt.Sprintf("Installing dependencies for %s project", projectType.Name),
+		ProjectType: result.ProjectType,
+		Status:      fmt.Sprintf("Installing dependencies for %s", result.ProjectType),
 	})
 
-	// Execute commands
+	// Each step runs in its own directory; a failed required command only
+	// stops the rest of its own step
 	allSuccessful := true
-	for _, cmd := range projectType.Commands {
+	for _, step := range plan.Steps {
 		if ctx.Err() != nil {
 			break
 		}
+		// Every command of the step was skipped
+		if !slices.Contains(step.Skip, false) {
+			continue
+		}
+		if step.Hook == hooks.PostInstall && !allSuccessful {
+			result.Warnings = append(result.Warnings, "post-install hooks skipped after a failed install")
+			continue
+		}
 
-		cmdResult := m.executeCommand(ctx, repositoryPath, repoName, projectType.Name, cmd)
-		result.Commands = append(result.Commands, cmdResult)
-
-		if !cmdResult.Success {
+		projectResult := m.runStep(ctx, plan, step, len(result.Commands)+1, priority)
+		result.Projects = append(result.Projects, projectResult)
+		result.Commands = append(result.Commands, projectResult.Commands...)
+		if !projectResult.Success {
 			allSuccessful = false
-			if cmd.Required && !m.skipOnError {
-				break
-			}
 		}
 	}
 
 	result.Success = allSuccessful && len(result.Commands) > 0
+
+	// Installs are not meant to change tracked files, such as a lockfile
+	// rewritten by a package manager
+	recordChanges(&result, plan.Path, snapshot)
 	result.Duration = time.Since(start)
 
 	status := "Completed"
@@ -186,7 +428,7 @@ func (m *Manager) installForRepository(ctx context.Context, repositoryPath strin
 
 	m.sendProgress(InstallProgress{
 		Repository:  repoName,
-		ProjectType: projectType.Name,
+		ProjectType: result.ProjectType,
 		Status:      status,
 		Completed:   true,
 		Duration:    result.Duration,
@@ -196,31 +438,153 @@ func (m *Manager) installForRepository(ctx context.Context, repositoryPath strin
 	return result
 }
 
-func (m *Manager) executeCommand(ctx context.Context, repoPath, repoName, projectType string, cmd detect.Command) CommandResult {
+// runStep executes one step's commands in the step's directory, each once
+// the scheduler lets it; number is that of its first command within the
+// plan, used to name the logs.
+func (m *Manager) runStep(ctx context.Context, plan *Plan, step Step, number, priority int) ProjectResult {
+	start := time.Now()
+
+	projectResult := ProjectResult{
+		Name:      step.Project,
+		Dir:       step.Dir,
+		Ecosystem: step.Ecosystem,
+		Success:   true,
+		Sandboxed: m.sandbox.Enabled() && step.Container == "",
+	}
+	projectPath := filepath.Join(plan.Path, filepath.FromSlash(step.Dir))
+	if step.VirtualEnv != "" {
+		projectResult.VirtualEnv = filepath.Join(projectPath, step.VirtualEnv)
+	}
+
+	// Offline, a step is not started when the caches lack its packages
+	if m.offline && len(step.Uncached) > 0 {
+		projectResult.Success = false
+		projectResult.Error = fmt.Errorf("cannot install offline: %s", step.DescribeUncached())
+		m.sendProgress(InstallProgress{
+			Repository:  plan.Repository,
+			ProjectType: step.Project,
+			Status:      "Not installable offline",
+			Error:       projectResult.Error,
+		})
+		return projectResult
+	}
+
+	for i, cmd := range step.Commands {
+		if step.Skip[i] {
+			continue
+		}
+		if ctx.Err() != nil {
+			projectResult.Success = false
+			projectResult.Error = ctx.Err()
+			break
+		}
+
+		m.sendProgress(InstallProgress{
+			Repository:  plan.Repository,
+			ProjectType: step.Project,
+			Command:     commandLine(cmd),
+			Status:      fmt.Sprintf("Queued: %s (%s)", commandLine(cmd), Classify(cmd)),
+			Queued:      true,
+		})
+		release, err := m.scheduler.Acquire(ctx, plan.Repository, cmd, priority)
+		if err != nil {
+			projectResult.Success = false
+			projectResult.Error = err
+			break
+		}
+
+		logPath := m.logPath(plan, number+len(projectResult.Commands), cmd)
+		cmdResult := m.executeCommand(ctx, plan, projectPath, step.Project, cmd, logPath, projectResult.Sandboxed)
+		release()
+		cmdResult.Dir = step.Dir
+		projectResult.Commands = append(projectResult.Commands, cmdResult)
+
+		if !cmdResult.Success {
+			projectResult.Success = false
+			if cmd.Required && !m.skipOnError {
+				projectResult.Error = fmt.Errorf("%s failed", cmdResult.Command)
+				break
+			}
+		}
+	}
+
+	projectResult.Duration = time.Since(start)
+	return projectResult
+}
+
+// executeCommand runs cmd in repoPath, a directory of the plan's
+// repository, under the sandbox when sandboxed is set. Its output is
+// written to logPath, unless that is empty.
+func (m *Manager) executeCommand(ctx context.Context, plan *Plan, repoPath, projectType string, cmd detect.Command, logPath string, sandboxed bool) (result CommandResult) {
 	start := time.Now()
 
-	cmdStr := fmt.Sprintf("%s %s", cmd.Command, strings.Join(cmd.Args, " "))
+	root, repoName := plan.Path, plan.Repository
+	cmdStr := commandLine(cmd)
+
+	result = CommandResult{
+		Command:   cmdStr,
+		Sandboxed: sandboxed,
+	}
+
+	output, err := newCommandLog(logPath)
+	if err != nil {
+		// The output is still collected in memory
+		m.sendProgress(InstallProgress{
+			Repository:  repoName,
+			ProjectType: projectType,
+			Status:      "Warning: " + err.Error(),
+		})
+	} else if logPath != "" {
+		result.LogFile = logPath
+	}
+	defer func() {
+		switch {
+		case result.Success:
+			output.Note("finished in %s", result.Duration.Round(time.Millisecond))
+		case result.ExitCode != 0:
+			output.Note("exit status %d after %s", result.ExitCode, result.Duration.Round(time.Millisecond))
+		default:
+			output.Note("failed after %s: %v", result.Duration.Round(time.Millisecond), result.Error)
+		}
+		result.Output = output.String()
+		output.Close()
+	}()
+	output.Note("%s", cmdStr)
+	output.Note("dir: %s", repoPath)
+	output.Note("started: %s", start.Format(time.RFC3339))
+	if sandboxed {
+		output.Note("sandbox: %s", m.sandbox.Describe())
+	}
 
 	m.sendProgress(InstallProgress{
 		Repository:  repoName,
 		ProjectType: projectType,
 		Command:     cmdStr,
 		Status:      fmt.Sprintf("Running: %s", cmdStr),
+		Sandboxed:   sandboxed,
+		LogFile:     result.LogFile,
 	})
 
-	result := CommandResult{
-		Command: cmdStr,
-	}
-
 	// Create command with timeout
 	cmdCtx, cancel := context.WithTimeout(ctx, m.timeout)
 	defer cancel()
 
-	execCmd := exec.CommandContext(cmdCtx, cmd.Command, cmd.Args...)
-	execCmd.Dir = repoPath
+	var execCmd *exec.Cmd
+	if sandboxed {
+		var err error
+		execCmd, err = m.sandbox.Command(cmdCtx, root, repoPath, cmd)
+		if err != nil {
+			result.Error = fmt.Errorf("failed to sandbox command: %w", err)
+			result.Duration = time.Since(start)
+			return result
+		}
+	} else {
+		execCmd = exec.CommandContext(cmdCtx, cmd.Command, cmd.Args...)
+		execCmd.Dir = repoPath
 
-	// Set up environment
-	execCmd.Env = os.Environ()
+		// Set up environment
+		execCmd.Env = append(os.Environ(), cmd.Env...)
+	}
 
 	// Create pipes for stdout and stderr
 	stdout, err := execCmd.StdoutPipe()
@@ -245,7 +609,6 @@ func (m *Manager) executeCommand(ctx context.Context, repoPath, repoName, projec
 	}
 
 	// Read output in separate goroutines
-	var outputBuilder strings.Builder
 	var wg sync.WaitGroup
 
 	wg.Add(2)
@@ -255,7 +618,7 @@ func (m *Manager) executeCommand(ctx context.Context, repoPath, repoName, projec
 		scanner := bufio.NewScanner(stdout)
 		for scanner.Scan() {
 			line := scanner.Text()
-			outputBuilder.WriteString(line + "\n")
+			output.Line(StreamOut, line)
 
 			// Send more detailed progress updates based on output
 			status := "Running"
@@ -286,7 +649,7 @@ func (m *Manager) executeCommand(ctx context.Context, repoPath, repoName, projec
 		scanner := bufio.NewScanner(stderr)
 		for scanner.Scan() {
 			line := scanner.Text()
-			outputBuilder.WriteString("STDERR: " + line + "\n")
+			output.Line(StreamErr, line)
 		}
 	}()
 
@@ -311,12 +674,12 @@ func (m *Manager) executeCommand(ctx context.Context, repoPath, repoName, projec
 		}
 	}()
 
-	// Wait for the command to finish
+	// Wait for the command to finish; the pipes are read to the end first,
+	// since Wait closes them
+	wg.Wait()
 	err = execCmd.Wait()
 	close(done) // Stop the periodic updates
-	wg.Wait()
 
-	result.Output = outputBuilder.String()
 	result.Duration = time.Since(start)
 
 	if err != nil {
@@ -346,11 +709,16 @@ func (m *Manager) sendProgress(progress InstallProgress) {
 	}
 }
 
-// CheckCommandAvailability checks if required commands are available on the system
-func CheckCommandAvailability(projectType *detect.ProjectType) map[string]bool {
+// CheckCommandAvailability checks if required commands are available on the
+// system. Commands given as a path, such as a virtual environment's python,
+// are created by the install itself and not checked.
+func CheckCommandAvailability(commands []detect.Command) map[string]bool {
 	availability := make(map[string]bool)
 
-	for _, cmd := range projectType.Commands {
+	for _, cmd := range commands {
+		if strings.ContainsRune(cmd.Command, filepath.Separator) {
+			continue
+		}
 		_, err := exec.LookPath(cmd.Command)
 		availability[cmd.Command] = err == nil
 	}
@@ -378,6 +746,14 @@ func GetInstallationSuggestions(command string) []string {
 			"npm install -g yarn",
 			"On macOS: brew install yarn",
 		},
+		"pnpm": {
+			"corepack enable pnpm",
+			"npm install -g pnpm",
+		},
+		"bun": {
+			"Visit https://bun.sh/ to install Bun",
+			"curl -fsSL https://bun.sh/install | bash",
+		},
 		"pip": {
 			"pip comes with Python - install Python first",
 			"On macOS: brew install python",
@@ -428,6 +804,72 @@ func GetInstallationSuggestions(command string) []string {
 			"Visit https://flutter.dev/docs/get-started/install",
 			"On macOS: brew install --cask flutter",
 		},
+		"mix": {
+			"Visit https://elixir-lang.org/install.html",
+			"On macOS: brew install elixir",
+			"On Ubuntu/Debian: sudo apt install elixir",
+		},
+		"stack": {
+			"Visit https://www.haskell.org/ghcup/ to install GHCup, which installs Stack",
+			"curl -sSL https://get.haskellstack.org/ | sh",
+		},
+		"cabal": {
+			"Visit https://www.haskell.org/ghcup/ to install GHCup, which installs Cabal",
+			"On macOS: brew install cabal-install",
+		},
+		"zig": {
+			"Visit https://ziglang.org/download/",
+			"On macOS: brew install zig",
+		},
+		"nix": {
+			"Visit https://nixos.org/download/",
+			"sh <(curl -L https://nixos.org/nix/install) --daemon",
+		},
+		"nix-shell": {
+			"nix-shell comes with Nix - visit https://nixos.org/download/",
+		},
+		"deno": {
+			"Visit https://deno.com/ to install Deno",
+			"curl -fsSL https://deno.land/install.sh | sh",
+		},
+		"bazel": {
+			"Install Bazelisk, which provides bazel: https://github.com/bazelbuild/bazelisk",
+			"On macOS: brew install bazelisk",
+		},
+		"opam": {
+			"Visit https://opam.ocaml.org/doc/Install.html",
+			"On macOS: brew install opam",
+			"On Ubuntu/Debian: sudo apt install opam",
+		},
+		"luarocks": {
+			"Visit https://luarocks.org/",
+			"On macOS: brew install luarocks",
+			"On Ubuntu/Debian: sudo apt install luarocks",
+		},
+		"julia": {
+			"Visit https://julialang.org/downloads/",
+			"curl -fsSL https://install.julialang.org | sh",
+		},
+		"shards": {
+			"shards comes with Crystal - visit https://crystal-lang.org/install/",
+		},
+		"nimble": {
+			"nimble comes with Nim - visit https://nim-lang.org/install.html",
+		},
+		"make": {
+			"On macOS: xcode-select --install",
+			"On Ubuntu/Debian: sudo apt install build-essential",
+		},
+		"just": {
+			"Visit https://just.systems/",
+			"On macOS: brew install just",
+			"cargo install just",
+		},
+		"task": {
+			"Visit https://taskfile.dev/installation/",
+			"On macOS: brew install go-task",
+			"go install github.com/go-task/task/v3/cmd/task@latest",
+		},
 	}
 
 	if suggestions, exists := suggestions[command]; exists {
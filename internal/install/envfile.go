@@ -0,0 +1,175 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// Environment file modes decide how missing environment files are created
+// from the templates a repository ships, such as .env from .env.example.
+const (
+	EnvPrompt = "prompt"
+	EnvCreate = "create"
+	EnvOff    = "off"
+)
+
+// EnvFile is an environment file the install creates from its template.
+type EnvFile struct {
+	detect.EnvTemplate
+	// Values holds the values entered or given for the template's
+	// variables, by key; the others keep the template's default.
+	Values map[string]string
+	// Skip leaves the file uncreated, as chosen when the plan is reviewed.
+	Skip bool
+}
+
+// Value returns the value v gets in the created file.
+func (f EnvFile) Value(v detect.EnvVar) string {
+	if value, ok := f.Values[v.Key]; ok {
+		return value
+	}
+	return v.Value
+}
+
+// Unset returns the keys of the variables left without a value.
+func (f EnvFile) Unset() []string {
+	var keys []string
+	for _, v := range f.Vars {
+		if f.Value(v) == "" {
+			keys = append(keys, v.Key)
+		}
+	}
+	return keys
+}
+
+// Describe tells which file is created from which template, and the
+// variables still without a value.
+func (f EnvFile) Describe() string {
+	line := fmt.Sprintf("%s from %s (%d variables)", f.TargetPath(), f.Name, len(f.Vars))
+	if unset := f.Unset(); len(unset) > 0 {
+		line += ", unset: " + strings.Join(unset, ", ")
+	}
+	return line
+}
+
+// LoadEnvValues reads a file of KEY=value lines giving the values of
+// template variables, for installs nobody is prompted in. A leading ~ in
+// path is the home directory.
+func LoadEnvValues(path string) (map[string]string, error) {
+	if rest, found := strings.CutPrefix(path, "~/"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	vars, err := detect.ParseEnvFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read env values: %w", err)
+	}
+	values := make(map[string]string, len(vars))
+	for _, v := range vars {
+		values[v.Key] = v.Value
+	}
+	return values, nil
+}
+
+// SetEnvFiles sets how missing environment files are created, and values
+// for their variables, such as those of a values file. Without a mode, as
+// with off, none are.
+func (m *Manager) SetEnvFiles(mode string, values map[string]string) {
+	m.envMode = mode
+	m.envValues = values
+}
+
+// addEnvFiles adds the environment file templates of the repository root
+// and of each project directory whose target file is missing.
+func (m *Manager) addEnvFiles(plan *Plan) error {
+	if m.envMode == "" || m.envMode == EnvOff {
+		return nil
+	}
+
+	dirs := []string{""}
+	for _, step := range plan.Steps {
+		if step.Hook == "" && !slices.Contains(dirs, step.Dir) {
+			dirs = append(dirs, step.Dir)
+		}
+	}
+
+	for _, dir := range dirs {
+		templates, err := detect.FindEnvTemplates(plan.Path, dir)
+		if err != nil {
+			return fmt.Errorf("failed to read env templates: %w", err)
+		}
+		for _, template := range templates {
+			// Two templates of the same target, the first one wins
+			if slices.ContainsFunc(plan.EnvFiles, func(f EnvFile) bool { return f.TargetPath() == template.TargetPath() }) {
+				continue
+			}
+			file := EnvFile{EnvTemplate: template, Values: make(map[string]string)}
+			for _, v := range template.Vars {
+				if value, ok := m.envValues[v.Key]; ok {
+					file.Values[v.Key] = value
+				}
+			}
+			plan.EnvFiles = append(plan.EnvFiles, file)
+		}
+	}
+	return nil
+}
+
+// writeEnvFiles creates the plan's environment files that are not
+// skipped. Existing files are never replaced, even when created since the
+// plan was built. It returns the files created, relative to the repository
+// root, and warnings for the variables left unset and files not created.
+func (m *Manager) writeEnvFiles(plan *Plan) (created, warnings []string) {
+	for _, file := range plan.EnvFiles {
+		if file.Skip {
+			continue
+		}
+
+		dir := filepath.Join(plan.Path, filepath.FromSlash(file.Dir))
+		values := make(map[string]string, len(file.Vars))
+		for _, v := range file.Vars {
+			values[v.Key] = file.Value(v)
+		}
+		data, err := detect.RenderEnvFile(filepath.Join(dir, file.Name), values)
+		if err == nil {
+			err = writeNewFile(filepath.Join(dir, file.Target), data)
+		}
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("%s not created: %v", file.TargetPath(), err))
+			continue
+		}
+
+		created = append(created, file.TargetPath())
+		m.sendProgress(InstallProgress{
+			Repository: plan.Repository,
+			Status:     fmt.Sprintf("Created %s from %s", file.TargetPath(), file.Name),
+		})
+		if unset := file.Unset(); len(unset) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s has unset variables: %s", file.TargetPath(), strings.Join(unset, ", ")))
+		}
+	}
+	return created, warnings
+}
+
+// writeNewFile writes data to a file that must not exist yet, readable only
+// by the user since environment files hold secrets.
+func writeNewFile(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return fmt.Errorf("%s already exists", filepath.Base(path))
+		}
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
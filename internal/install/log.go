@@ -0,0 +1,125 @@
+package install
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// Log streams, tagged on every line of a command log.
+const (
+	StreamOut = "out"
+	StreamErr = "err"
+)
+
+// LogTimeFormat stamps each line of a command log.
+const LogTimeFormat = "15:04:05.000"
+
+// commandLog collects a command's output. The stdout and stderr readers
+// write to it concurrently; lines keep the order they arrive in. With a log
+// file, every line is also written there as it arrives, stamped with the
+// time and stream:
+//
+//	12:04:05.123 [out] added 312 packages
+//	12:04:05.130 [err] npm warn deprecated inflight@1.0.6
+type commandLog struct {
+	mu     sync.Mutex
+	output strings.Builder
+	file   *os.File
+	err    error // First failure writing the file
+}
+
+// newCommandLog creates the log file at path, or only collects output in
+// memory when path is empty.
+func newCommandLog(path string) (*commandLog, error) {
+	log := &commandLog{}
+	if path == "" {
+		return log, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return log, fmt.Errorf("failed to create log directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return log, fmt.Errorf("failed to create log file: %w", err)
+	}
+	log.file = file
+	return log, nil
+}
+
+// Line records one line of output from stream.
+func (l *commandLog) Line(stream, line string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if stream == StreamErr {
+		l.output.WriteString("STDERR: ")
+	}
+	l.output.WriteString(line + "\n")
+	l.write(fmt.Sprintf("%s [%s] %s\n", time.Now().Format(LogTimeFormat), stream, line))
+}
+
+// Note writes a "# " comment line to the file only, such as the command
+// run or its exit status.
+func (l *commandLog) Note(format string, args ...any) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.write("# " + fmt.Sprintf(format, args...) + "\n")
+}
+
+func (l *commandLog) write(s string) {
+	if l.file == nil || l.err != nil {
+		return
+	}
+	_, l.err = l.file.WriteString(s)
+}
+
+// String returns the output collected so far, stderr lines prefixed with
+// "STDERR: ".
+func (l *commandLog) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.output.String()
+}
+
+// Close closes the log file, reporting the first write error.
+func (l *commandLog) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.file == nil {
+		return nil
+	}
+	if err := l.file.Close(); l.err == nil {
+		l.err = err
+	}
+	l.file = nil
+	return l.err
+}
+
+var unsafeLogName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// logPath names the log of a plan's command: one directory per repository
+// and one file per command, numbered in the order they run and named after
+// the command, e.g. "<dir>/my-app/02-npm-ci.log".
+func (m *Manager) logPath(plan *Plan, number int, cmd detect.Command) string {
+	if m.logDir == "" {
+		return ""
+	}
+	repository := plan.Repository
+	if plan.FullName != "" {
+		repository = strings.ReplaceAll(plan.FullName, "/", "_")
+	}
+	name := cmd.Name
+	if name == "" {
+		name = filepath.Base(cmd.Command)
+	}
+	name = fmt.Sprintf("%02d-%s.log", number, unsafeLogName.ReplaceAllString(name, "_"))
+	return filepath.Join(m.logDir, unsafeLogName.ReplaceAllString(repository, "_"), name)
+}
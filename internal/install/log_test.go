@@ -0,0 +1,79 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+func TestInstallLogFailingStep(t *testing.T) {
+	root := t.TempDir()
+	logDir := t.TempDir()
+
+	manager := NewManager(1, time.Minute)
+	manager.SetLogDir(logDir)
+	go func() {
+		for range manager.GetProgressChannel() {
+		}
+	}()
+
+	failing := detect.Command{
+		Name:     "fail-setup",
+		Command:  "sh",
+		Args:     []string{"-c", "echo resolving packages; echo 'missing: libfoo' >&2; exit 3"},
+		Required: true,
+	}
+	plan := &Plan{
+		Repository: "app",
+		Path:       root,
+		Trusted:    true,
+		Steps: []Step{{
+			Ecosystem: "Shell",
+			Project:   "Setup",
+			Commands:  []detect.Command{failing},
+			Skip:      []bool{false},
+		}},
+	}
+
+	results, _ := manager.InstallPlans(context.Background(), []*Plan{plan})
+	if len(results) != 1 || results[0].Success || len(results[0].Commands) != 1 {
+		t.Fatalf("results = %+v, want one failed command", results)
+	}
+	command := results[0].Commands[0]
+	if command.ExitCode != 3 {
+		t.Errorf("exit code = %d, want 3", command.ExitCode)
+	}
+	if want := filepath.Join(logDir, "app", "01-fail-setup.log"); command.LogFile != want {
+		t.Fatalf("log file = %q, want %q", command.LogFile, want)
+	}
+
+	data, err := os.ReadFile(command.LogFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := string(data)
+	stamp := `\d\d:\d\d:\d\d\.\d{3}`
+	for _, pattern := range []string{
+		`(?m)^# sh -c echo resolving packages; echo 'missing: libfoo' >&2; exit 3$`,
+		`(?m)^# dir: ` + regexp.QuoteMeta(root) + `$`,
+		`(?m)^` + stamp + ` \[out\] resolving packages$`,
+		`(?m)^` + stamp + ` \[err\] missing: libfoo$`,
+		`(?m)^# exit status 3 after \S+$`,
+	} {
+		if !regexp.MustCompile(pattern).MatchString(log) {
+			t.Errorf("log does not match %s:\n%s", pattern, log)
+		}
+	}
+	if !strings.Contains(command.Output, "STDERR: missing: libfoo") {
+		t.Errorf("output = %q, want the stderr line", command.Output)
+	}
+	if info, err := os.Stat(command.LogFile); err == nil && info.Mode().Perm() != 0o600 {
+		t.Errorf("log mode = %v, want 0600", info.Mode().Perm())
+	}
+}
@@ -0,0 +1,406 @@
+package install
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// SetOffline installs from local caches only. Commands that have an
+// offline form run with it, such as cargo build --offline, and project
+// steps whose lockfile the caches cannot satisfy fail without running.
+// findLinks is the directory of wheels and sdists pip installs from; a
+// leading ~ is the home directory.
+func (m *Manager) SetOffline(offline bool, findLinks string) {
+	if rest, found := strings.CutPrefix(findLinks, "~/"); found {
+		if home, err := os.UserHomeDir(); err == nil {
+			findLinks = filepath.Join(home, rest)
+		}
+	}
+	m.offline = offline
+	m.findLinks = findLinks
+}
+
+// Offline reports whether installs run from local caches only.
+func (m *Manager) Offline() bool {
+	return m.offline
+}
+
+// offlineCommand returns the offline form of a command of a step, or the
+// command unchanged when it has none.
+func (m *Manager) offlineCommand(ecosystem string, cmd detect.Command) detect.Command {
+	cached, ok := cmd.WithOffline()
+	if !ok {
+		return cmd
+	}
+	if ecosystem == "Python" && m.findLinks != "" {
+		cached.Env = append(cached.Env, "PIP_FIND_LINKS="+m.findLinks)
+	}
+	return cached
+}
+
+// uncachedPackages returns the packages locked in the step's directory that
+// are missing from the local caches, as name@version. npm and Yarn 1
+// lockfiles, go.mod, Cargo.lock and pinned pip requirements are checked;
+// other package managers are left to fail on their own.
+func (m *Manager) uncachedPackages(projectPath string, step Step) []string {
+	uses := func(command string) bool {
+		return slices.ContainsFunc(step.Commands, func(cmd detect.Command) bool { return cmd.Command == command })
+	}
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(projectPath, name))
+		return err == nil
+	}
+
+	switch {
+	case step.Ecosystem == "Node.js" && uses("npm") && exists("package-lock.json"):
+		return uncachedNpm(filepath.Join(projectPath, "package-lock.json"))
+	case step.Ecosystem == "Node.js" && uses("yarn") && exists("yarn.lock") && !exists(".yarnrc.yml"):
+		return uncachedYarn(filepath.Join(projectPath, "yarn.lock"))
+	case step.Ecosystem == "Go" && exists("go.mod"):
+		return uncachedGo(filepath.Join(projectPath, "go.mod"))
+	case step.Ecosystem == "Rust" && exists("Cargo.lock"):
+		return uncachedCargo(filepath.Join(projectPath, "Cargo.lock"))
+	case step.Ecosystem == "Python" && !uses("uv") && exists("requirements.txt"):
+		return uncachedPip(filepath.Join(projectPath, "requirements.txt"), m.findLinks)
+	}
+	return nil
+}
+
+// uncachedNpm checks the tarballs of package-lock.json against the index
+// of npm's content cache, where each is keyed by its URL.
+func uncachedNpm(lockfile string) []string {
+	data, err := os.ReadFile(lockfile)
+	if err != nil {
+		return nil
+	}
+	type lockedPackage struct {
+		Version      string                   `json:"version"`
+		Resolved     string                   `json:"resolved"`
+		Dependencies map[string]lockedPackage `json:"dependencies"`
+	}
+	// Lockfile versions 2 and 3 key packages by path, with the ranges they
+	// depend on instead of nested packages
+	type installedPackage struct {
+		Version  string `json:"version"`
+		Resolved string `json:"resolved"`
+	}
+	var lock struct {
+		Packages     map[string]installedPackage `json:"packages"`
+		Dependencies map[string]lockedPackage    `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil
+	}
+
+	cache := os.Getenv("npm_config_cache")
+	if cache == "" {
+		cache = userCacheDir("npm-cache", ".npm")
+	}
+	index := filepath.Join(cache, "_cacache", "index-v5")
+
+	var missing []string
+	check := func(name string, pkg installedPackage) {
+		if !strings.HasPrefix(pkg.Resolved, "http") {
+			return
+		}
+		sum := sha256.Sum256([]byte("make-fetch-happen:request-cache:" + pkg.Resolved))
+		key := hex.EncodeToString(sum[:])
+		if _, err := os.Stat(filepath.Join(index, key[:2], key[2:4], key[4:])); err != nil {
+			missing = append(missing, name+"@"+pkg.Version)
+		}
+	}
+
+	if len(lock.Packages) > 0 {
+		for path, pkg := range lock.Packages {
+			// Keyed by install path, such as node_modules/a/node_modules/b
+			name := path
+			if i := strings.LastIndex(path, "node_modules/"); i >= 0 {
+				name = path[i+len("node_modules/"):]
+			}
+			check(name, pkg)
+		}
+	} else {
+		// Lockfile version 1 nests the dependencies
+		var walk func(map[string]lockedPackage)
+		walk = func(deps map[string]lockedPackage) {
+			for name, pkg := range deps {
+				check(name, installedPackage{Version: pkg.Version, Resolved: pkg.Resolved})
+				walk(pkg.Dependencies)
+			}
+		}
+		walk(lock.Dependencies)
+	}
+	return sortedUnique(missing)
+}
+
+var (
+	yarnEntryPattern   = regexp.MustCompile(`^"?(@?[^@"\s]+)@`)
+	yarnVersionPattern = regexp.MustCompile(`^\s+version "([^"]+)"`)
+	yarnResolved       = regexp.MustCompile(`^\s+resolved "(https?://[^"]+)"`)
+)
+
+// uncachedYarn checks the packages of a Yarn 1 yarn.lock against Yarn's
+// cache, which has a directory per package named after it and its version.
+func uncachedYarn(lockfile string) []string {
+	file, err := os.Open(lockfile)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	cache := os.Getenv("YARN_CACHE_FOLDER")
+	if cache == "" {
+		switch runtime.GOOS {
+		case "darwin":
+			cache = userCacheDir("Yarn", "Library/Caches/Yarn")
+		case "windows":
+			cache = userCacheDir(filepath.Join("Yarn", "Cache"), "")
+		default:
+			cache = userCacheDir("yarn", ".cache/yarn")
+		}
+	}
+
+	var missing []string
+	var name, version string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if match := yarnEntryPattern.FindStringSubmatch(line); match != nil && !strings.HasPrefix(line, " ") {
+			name, version = match[1], ""
+			continue
+		}
+		if match := yarnVersionPattern.FindStringSubmatch(line); match != nil {
+			version = match[1]
+			continue
+		}
+		if yarnResolved.MatchString(line) && name != "" {
+			slug := strings.ReplaceAll(name, "/", "-")
+			if entries, _ := filepath.Glob(filepath.Join(cache, "v*", "npm-"+slug+"-"+version+"-*")); len(entries) == 0 {
+				missing = append(missing, name+"@"+version)
+			}
+		}
+	}
+	return sortedUnique(missing)
+}
+
+var (
+	goRequirePattern = regexp.MustCompile(`^(?:require\s+)?([^\s()]+)\s+(v[^\s]+)`)
+	goReplacePattern = regexp.MustCompile(`^(?:replace\s+)?([^\s()]+)(?:\s+v[^\s]+)?\s+=>`)
+)
+
+// uncachedGo checks the modules go.mod requires, which go mod download
+// fetches, against the module cache. Replaced modules are left out.
+func uncachedGo(modfile string) []string {
+	data, err := os.ReadFile(modfile)
+	if err != nil {
+		return nil
+	}
+
+	cache := os.Getenv("GOMODCACHE")
+	if cache == "" {
+		gopath := filepath.SplitList(os.Getenv("GOPATH"))
+		if len(gopath) > 0 && gopath[0] != "" {
+			cache = filepath.Join(gopath[0], "pkg", "mod")
+		} else if home, err := os.UserHomeDir(); err == nil {
+			cache = filepath.Join(home, "go", "pkg", "mod")
+		}
+	}
+
+	required := make(map[string]string)
+	replaced := make(map[string]bool)
+	block := ""
+	for _, line := range strings.Split(string(data), "\n") {
+		line, _, _ = strings.Cut(line, "//")
+		line = strings.TrimSpace(line)
+		switch {
+		case line == ")":
+			block = ""
+		case strings.HasSuffix(line, "("):
+			block = strings.TrimSpace(strings.TrimSuffix(line, "("))
+		case block == "replace" || strings.HasPrefix(line, "replace "):
+			if match := goReplacePattern.FindStringSubmatch(line); match != nil {
+				replaced[match[1]] = true
+			}
+		case block == "require" || strings.HasPrefix(line, "require "):
+			if match := goRequirePattern.FindStringSubmatch(line); match != nil {
+				required[match[1]] = match[2]
+			}
+		}
+	}
+
+	var missing []string
+	for module, version := range required {
+		if replaced[module] {
+			continue
+		}
+		zip := filepath.Join(cache, "cache", "download", filepath.FromSlash(escapeModulePath(module)), "@v", escapeModulePath(version)+".zip")
+		if _, err := os.Stat(zip); err != nil {
+			missing = append(missing, module+"@"+version)
+		}
+	}
+	return sortedUnique(missing)
+}
+
+// escapeModulePath escapes upper-case letters the way the module cache
+// does, as "!" and the lower-case letter.
+func escapeModulePath(path string) string {
+	var b strings.Builder
+	for _, r := range path {
+		if r >= 'A' && r <= 'Z' {
+			b.WriteByte('!')
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+var cargoFieldPattern = regexp.MustCompile(`^(name|version|source) = "([^"]*)"`)
+
+// uncachedCargo checks the registry crates of Cargo.lock against Cargo's
+// download cache.
+func uncachedCargo(lockfile string) []string {
+	data, err := os.ReadFile(lockfile)
+	if err != nil {
+		return nil
+	}
+
+	home := os.Getenv("CARGO_HOME")
+	if home == "" {
+		if userHome, err := os.UserHomeDir(); err == nil {
+			home = filepath.Join(userHome, ".cargo")
+		}
+	}
+
+	var missing []string
+	check := func(pkg map[string]string) {
+		if !strings.HasPrefix(pkg["source"], "registry+") && !strings.HasPrefix(pkg["source"], "sparse+") {
+			return
+		}
+		crate := pkg["name"] + "-" + pkg["version"] + ".crate"
+		if entries, _ := filepath.Glob(filepath.Join(home, "registry", "cache", "*", crate)); len(entries) == 0 {
+			missing = append(missing, pkg["name"]+"@"+pkg["version"])
+		}
+	}
+
+	pkg := map[string]string{}
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "[[package]]" {
+			check(pkg)
+			pkg = map[string]string{}
+			continue
+		}
+		if match := cargoFieldPattern.FindStringSubmatch(line); match != nil {
+			pkg[match[1]] = match[2]
+		}
+	}
+	check(pkg)
+	return sortedUnique(missing)
+}
+
+var (
+	pinnedRequirement = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9._-]*)(\[[^\]]*\])?\s*==\s*([^\s;#]+)`)
+	distSeparators    = regexp.MustCompile(`[-_.]+`)
+)
+
+// uncachedPip checks the pinned requirements of requirements.txt against
+// the wheels and sdists in findLinks. Unpinned ones cannot be checked.
+func uncachedPip(requirements, findLinks string) []string {
+	data, err := os.ReadFile(requirements)
+	if err != nil {
+		return nil
+	}
+
+	dists := make(map[string]bool)
+	if findLinks != "" {
+		if entries, err := os.ReadDir(findLinks); err == nil {
+			for _, entry := range entries {
+				if name, version, ok := distVersion(entry.Name()); ok {
+					dists[normalizeDist(name)+"@"+normalizeDist(version)] = true
+				}
+			}
+		}
+	}
+
+	var missing []string
+	for _, line := range strings.Split(string(data), "\n") {
+		match := pinnedRequirement.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil {
+			continue
+		}
+		if !dists[normalizeDist(match[1])+"@"+normalizeDist(match[3])] {
+			missing = append(missing, match[1]+"@"+match[3])
+		}
+	}
+	return sortedUnique(missing)
+}
+
+// distVersion returns the distribution name and version of a wheel, named
+// name-version-tags.whl, or of an sdist, named name-version.tar.gz or .zip.
+func distVersion(file string) (name, version string, ok bool) {
+	if base, found := strings.CutSuffix(file, ".whl"); found {
+		parts := strings.Split(base, "-")
+		if len(parts) < 3 {
+			return "", "", false
+		}
+		return parts[0], parts[1], true
+	}
+	for _, ext := range []string{".tar.gz", ".zip"} {
+		if base, found := strings.CutSuffix(file, ext); found {
+			i := strings.LastIndex(base, "-")
+			if i <= 0 {
+				return "", "", false
+			}
+			return base[:i], base[i+1:], true
+		}
+	}
+	return "", "", false
+}
+
+// normalizeDist lower-cases a distribution name or version and joins its
+// parts with "_", so they compare whatever separators they use.
+func normalizeDist(name string) string {
+	return distSeparators.ReplaceAllString(strings.ToLower(name), "_")
+}
+
+// userCacheDir returns the cache directory of a tool: name below the local
+// application data on Windows, otherwise home, relative to the home
+// directory.
+func userCacheDir(name, home string) string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("LOCALAPPDATA"); dir != "" {
+			return filepath.Join(dir, name)
+		}
+	}
+	if dir, err := os.UserHomeDir(); err == nil {
+		return filepath.Join(dir, filepath.FromSlash(home))
+	}
+	return ""
+}
+
+func sortedUnique(items []string) []string {
+	slices.Sort(items)
+	return slices.Compact(items)
+}
+
+// DescribeUncached tells how many of the step's packages are missing from
+// the local caches, naming the first ones.
+func (s Step) DescribeUncached() string {
+	const listed = 3
+	if len(s.Uncached) <= listed {
+		return fmt.Sprintf("%d packages not in the local caches: %s", len(s.Uncached), strings.Join(s.Uncached, ", "))
+	}
+	return fmt.Sprintf("%d packages not in the local caches: %s and %d more", len(s.Uncached), strings.Join(s.Uncached[:listed], ", "), len(s.Uncached)-listed)
+}
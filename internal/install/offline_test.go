@@ -0,0 +1,102 @@
+package install
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// touch creates an empty file at path, and its directories.
+func touch(t *testing.T, path string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func checkUncached(t *testing.T, name string, got, want []string) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("%s: uncached = %q, want %q", name, got, want)
+	}
+}
+
+// cacheNpm adds the tarball at url to the index of npm's content cache.
+func cacheNpm(t *testing.T, cache, url string) {
+	t.Helper()
+	sum := sha256.Sum256([]byte("make-fetch-happen:request-cache:" + url))
+	key := hex.EncodeToString(sum[:])
+	touch(t, filepath.Join(cache, "_cacache", "index-v5", key[:2], key[2:4], key[4:]))
+}
+
+func TestUncachedNpm(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("npm_config_cache", cache)
+	cacheNpm(t, cache, "https://registry.npmjs.org/lodash/-/lodash-4.17.21.tgz")
+
+	// Local packages are not fetched, so never missing
+	checkUncached(t, "lockfile v3", uncachedNpm(filepath.Join("testdata", "offline", "package-lock.json")),
+		[]string{"@scope/util@2.0.0", "left-pad@1.3.0"})
+	checkUncached(t, "lockfile v1", uncachedNpm(filepath.Join("testdata", "offline", "package-lock-v1.json")),
+		[]string{"debug@2.6.9", "express@4.18.2"})
+}
+
+func TestUncachedYarn(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("YARN_CACHE_FOLDER", cache)
+	if err := os.MkdirAll(filepath.Join(cache, "v6", "npm-lodash-4.17.21-679591c564c3bffaae8454cf0b3df370c3d6911c-integrity"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(cache, "v6", "npm-@babel-core-7.23.2-ed10df0d580fff67c5f3ee70fd22e2e4c90a9f94-integrity"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	checkUncached(t, "yarn.lock", uncachedYarn(filepath.Join("testdata", "offline", "yarn.lock")),
+		[]string{"left-pad@1.3.0"})
+}
+
+func TestUncachedGo(t *testing.T) {
+	cache := t.TempDir()
+	t.Setenv("GOMODCACHE", cache)
+	download := filepath.Join(cache, "cache", "download")
+	// Upper-case letters are escaped in the cache
+	touch(t, filepath.Join(download, "github.com", "!burnt!sushi", "toml", "@v", "v1.3.2.zip"))
+	touch(t, filepath.Join(download, "github.com", "spf13", "cobra", "@v", "v1.8.0.zip"))
+	// Another version of a required module is not enough
+	touch(t, filepath.Join(download, "github.com", "google", "uuid", "@v", "v1.5.0.zip"))
+
+	// The replaced golang.org/x/sync is not downloaded
+	checkUncached(t, "go.mod", uncachedGo(filepath.Join("testdata", "offline", "go.mod")),
+		[]string{"github.com/google/uuid@v1.6.0"})
+}
+
+func TestUncachedCargo(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("CARGO_HOME", home)
+	touch(t, filepath.Join(home, "registry", "cache", "index.crates.io-6f17d22bba15001f", "serde-1.0.193.crate"))
+
+	// Git and path crates are not in the registry cache
+	checkUncached(t, "Cargo.lock", uncachedCargo(filepath.Join("testdata", "offline", "Cargo.lock")),
+		[]string{"tokio@1.35.0"})
+}
+
+func TestUncachedPip(t *testing.T) {
+	findLinks := t.TempDir()
+	touch(t, filepath.Join(findLinks, "requests-2.31.0-py3-none-any.whl"))
+	touch(t, filepath.Join(findLinks, "PyYAML-6.0.1.tar.gz"))
+	touch(t, filepath.Join(findLinks, "uvicorn-0.24.0.post1-py3-none-any.whl"))
+	touch(t, filepath.Join(findLinks, "flask-2.3.3-py3-none-any.whl"))
+
+	requirements := filepath.Join("testdata", "offline", "requirements.txt")
+	checkUncached(t, "requirements.txt", uncachedPip(requirements, findLinks),
+		[]string{"flask@3.0.0", "uvicorn@0.24.0"})
+	// Without find-links, every pinned requirement is missing
+	checkUncached(t, "no find-links", uncachedPip(requirements, ""),
+		[]string{"PyYAML@6.0.1", "flask@3.0.0", "requests@2.31.0", "uvicorn@0.24.0"})
+}
@@ -0,0 +1,436 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+	"github.com/lvcasx1/quikgit/internal/hooks"
+	"github.com/lvcasx1/quikgit/internal/trust"
+)
+
+// Step installs one ecosystem of one project directory. When several
+// detected project types share an ecosystem, such as React and Vite in the
+// same package.json, the highest-priority one supplies the commands.
+type Step struct {
+	Dir       string // Relative to the repository root, empty for the root
+	Ecosystem string
+	Project   string   // Project type whose commands run
+	Matched   []string // Every project type detected for this step
+	Commands  []detect.Command
+	// Skip[i] leaves Commands[i] out of the install, as chosen when the plan
+	// is reviewed.
+	Skip []bool
+	// Tools reports, per executable the commands run, whether it is on PATH.
+	Tools map[string]bool
+	// Toolchains are the step's version pins, checked against the local
+	// tools unless the toolchain policy is ignore.
+	Toolchains []detect.Toolchain
+	// Activation is the version manager providing the pinned toolchain; the
+	// commands above already run through it.
+	Activation *Activation
+	// VirtualEnv is the Python environment installed into, relative to Dir.
+	VirtualEnv string
+	// Hook is the stage of a step running hooks, empty for project steps.
+	Hook string
+	// Container is the runtime of a step installing in the repository's
+	// container setup, empty for steps run on the host.
+	Container string
+	// Uncached lists the locked packages missing from the local caches, as
+	// name@version; an offline install of the step fails without them.
+	Uncached []string
+}
+
+// Name describes the step as "Project" or "Project (dir)".
+func (s Step) Name() string {
+	if s.Dir == "" {
+		return s.Project
+	}
+	return fmt.Sprintf("%s (%s)", s.Project, s.Dir)
+}
+
+// Missing returns the step's executables that are not on PATH, sorted.
+func (s Step) Missing() []string {
+	var missing []string
+	for tool, available := range s.Tools {
+		if !available {
+			missing = append(missing, tool)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
+
+// Plan lists everything an install runs for one repository.
+type Plan struct {
+	Repository string
+	Path       string
+	Steps      []Step
+	Error      error
+
+	FullName    string // owner/repo of the GitHub origin, if any
+	Trusted     bool
+	TrustReason string
+	// NoScripts runs the commands with the repository's scripts disabled;
+	// commands that cannot avoid running them are skipped.
+	NoScripts bool
+	// EnvFiles are the missing environment files created before the steps
+	// run.
+	EnvFiles []EnvFile
+}
+
+// UnsetEnv reports whether an environment file to create has variables
+// without a value, which the user is prompted for.
+func (p *Plan) UnsetEnv() bool {
+	for _, file := range p.EnvFiles {
+		if !file.Skip && len(file.Unset()) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// NeedsConfirmation reports whether the plan runs scripts from an untrusted
+// repository, which the user has to confirm.
+func (p *Plan) NeedsConfirmation() bool {
+	return !p.Trusted && !p.NoScripts && p.CommandCount() > 0
+}
+
+// CommandCount returns the number of commands across all steps that are
+// not skipped.
+func (p *Plan) CommandCount() int {
+	count := 0
+	for _, step := range p.Steps {
+		for i := range step.Commands {
+			if !step.Skip[i] {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// SkipCommands skips the commands with any of the given names, such as
+// "npm-ci", and returns how many were skipped.
+func (p *Plan) SkipCommands(names ...string) int {
+	skipped := 0
+	for _, step := range p.Steps {
+		for i, cmd := range step.Commands {
+			if slices.Contains(names, cmd.Name) {
+				step.Skip[i] = true
+				skipped++
+			}
+		}
+	}
+	return skipped
+}
+
+// Missing returns the executables, across all steps, that are not on PATH.
+func (p *Plan) Missing() []string {
+	var missing []string
+	for _, step := range p.Steps {
+		for _, tool := range step.Missing() {
+			if !slices.Contains(missing, tool) {
+				missing = append(missing, tool)
+			}
+		}
+	}
+	return missing
+}
+
+// Mismatches returns the checked toolchains that do not satisfy their pin.
+func (p *Plan) Mismatches() []detect.Toolchain {
+	var mismatches []detect.Toolchain
+	for _, step := range p.Steps {
+		for _, toolchain := range step.Toolchains {
+			if toolchain.Checked && !toolchain.Satisfied {
+				mismatches = append(mismatches, toolchain)
+			}
+		}
+	}
+	return mismatches
+}
+
+// Describe names the plan's steps, or reports why there are none.
+func (p *Plan) Describe() string {
+	switch {
+	case p.Error != nil:
+		return p.Error.Error()
+	case len(p.Steps) == 0:
+		return ErrNoProjects.Error()
+	}
+
+	names := make([]string, len(p.Steps))
+	for i, step := range p.Steps {
+		names[i] = step.Name()
+	}
+	return strings.Join(names, ", ")
+}
+
+// Plan detects every project in the repository and builds its install plan:
+// one step per directory and ecosystem, with commands already planned for
+// the same directory left out. Untrusted repositories are planned according
+// to the untrusted policy.
+func (m *Manager) Plan(repositoryPath string) *Plan {
+	return m.plan(repositoryPath, false)
+}
+
+// PlanWithoutScripts builds the install plan with the repository's scripts
+// disabled, whether or not it is trusted.
+func (m *Manager) PlanWithoutScripts(repositoryPath string) *Plan {
+	return m.plan(repositoryPath, true)
+}
+
+func (m *Manager) plan(repositoryPath string, noScripts bool) *Plan {
+	plan := &Plan{
+		Repository: filepath.Base(repositoryPath),
+		Path:       repositoryPath,
+		FullName:   trust.RepositoryName(repositoryPath),
+		Trusted:    true,
+	}
+
+	if m.trust != nil {
+		plan.Trusted, plan.TrustReason = m.trust.Check(plan.FullName)
+	}
+	if !plan.Trusted && !noScripts {
+		switch m.untrusted {
+		case UntrustedSkip:
+			plan.Error = fmt.Errorf("not trusted: %s", plan.TrustReason)
+			return plan
+		case UntrustedNoScripts:
+			noScripts = true
+		}
+	}
+	plan.NoScripts = noScripts
+
+	detector := detect.NewDetector(repositoryPath)
+	detector.SetMaxDepth(m.detectDepth)
+	projects, err := detector.DetectProjects()
+	if err != nil {
+		plan.Error = fmt.Errorf("failed to detect project type: %w", err)
+		return plan
+	}
+
+	// A container setup replaces the host install of every project, or
+	// builds beside it
+	container, replaces, err := m.containerStep(plan, projects)
+	if err != nil {
+		plan.Error = err
+		return plan
+	}
+	if container != nil {
+		container.Skip = make([]bool, len(container.Commands))
+		for i, cmd := range container.Commands {
+			container.Skip[i] = noScripts && cmd.NoScripts == nil
+		}
+		container.Tools = CheckCommandAvailability(container.Commands)
+		plan.Steps = append(plan.Steps, *container)
+		if replaces {
+			projects = nil
+		}
+	}
+
+	var keys []string
+	groups := make(map[string][]*detect.ProjectType)
+	for _, project := range projects {
+		key := project.Dir + "\x00" + project.Ecosystem
+		if _, seen := groups[key]; !seen {
+			keys = append(keys, key)
+		}
+		groups[key] = append(groups[key], project)
+	}
+
+	planned := make(map[string]bool)
+	for _, key := range keys {
+		group := groups[key]
+		primary := detect.Primary(group)
+
+		step := Step{
+			Dir:        primary.Dir,
+			Ecosystem:  primary.Ecosystem,
+			Project:    primary.Name,
+			VirtualEnv: primary.VirtualEnv,
+		}
+		for _, project := range group {
+			step.Matched = append(step.Matched, project.Name)
+		}
+
+		for _, cmd := range primary.Commands {
+			if cmd.Verify && !m.verify {
+				continue
+			}
+			if noScripts {
+				// Commands that always run repository code stay in the plan,
+				// skipped, so the user sees what is left out
+				if safe, ok := cmd.WithoutScripts(); ok {
+					cmd = safe
+				}
+			}
+			if m.offline {
+				cmd = m.offlineCommand(step.Ecosystem, cmd)
+			}
+			line := primary.Dir + "\x00" + cmd.Command + " " + strings.Join(cmd.Args, " ")
+			if planned[line] {
+				continue
+			}
+			planned[line] = true
+			step.Commands = append(step.Commands, cmd)
+		}
+
+		if len(step.Commands) == 0 {
+			continue
+		}
+		if m.offline {
+			step.Uncached = m.uncachedPackages(filepath.Join(repositoryPath, filepath.FromSlash(step.Dir)), step)
+		}
+		m.applyToolchains(repositoryPath, &step)
+		step.Skip = make([]bool, len(step.Commands))
+		for i, cmd := range step.Commands {
+			step.Skip[i] = noScripts && cmd.NoScripts == nil
+		}
+		step.Tools = CheckCommandAvailability(step.Commands)
+		plan.Steps = append(plan.Steps, step)
+	}
+
+	if err := m.addHooks(plan, noScripts); err != nil {
+		plan.Error = err
+	}
+	if err := m.addEnvFiles(plan); err != nil {
+		plan.Error = err
+	}
+	return plan
+}
+
+// addHooks adds a step for each hook stage with commands: post-clone (for
+// repositories just cloned) and pre-install hooks before the projects,
+// post-install hooks after them.
+func (m *Manager) addHooks(plan *Plan, noScripts bool) error {
+	stages := []string{hooks.PreInstall}
+	if m.afterClone {
+		stages = []string{hooks.PostClone, hooks.PreInstall}
+	}
+
+	var before []Step
+	for _, stage := range stages {
+		step, err := m.hookStep(plan, stage, noScripts)
+		if err != nil {
+			return err
+		}
+		if step != nil {
+			before = append(before, *step)
+		}
+	}
+	after, err := m.hookStep(plan, hooks.PostInstall, noScripts)
+	if err != nil {
+		return err
+	}
+
+	plan.Steps = append(before, plan.Steps...)
+	if after != nil {
+		plan.Steps = append(plan.Steps, *after)
+	}
+	return nil
+}
+
+// hookStep returns the step running the hooks of stage in the repository
+// root, or nil when there are none. The repository's own hooks are skipped
+// with its scripts disabled.
+func (m *Manager) hookStep(plan *Plan, stage string, noScripts bool) (*Step, error) {
+	stageHooks, err := hooks.Load(m.hooks, plan.Path, plan.FullName, stage)
+	if err != nil || len(stageHooks) == 0 {
+		return nil, err
+	}
+
+	step := &Step{
+		Ecosystem: "Hooks",
+		Project:   hooks.StageName(stage),
+		Hook:      stage,
+	}
+	for i, hook := range stageHooks {
+		step.Commands = append(step.Commands, hook.Command(i))
+		step.Skip = append(step.Skip, noScripts && hook.FromRepository)
+	}
+	step.Tools = CheckCommandAvailability(step.Commands)
+	return step, nil
+}
+
+// Plans builds the install plan of each repository, in order.
+func (m *Manager) Plans(repositories []string) []*Plan {
+	plans := make([]*Plan, len(repositories))
+	for i, repositoryPath := range repositories {
+		plans[i] = m.Plan(repositoryPath)
+	}
+	return plans
+}
+
+// PlansWithoutScripts builds the install plans of the repositories with
+// their scripts disabled, trusted or not.
+func (m *Manager) PlansWithoutScripts(repositories []string) []*Plan {
+	plans := make([]*Plan, len(repositories))
+	for i, repositoryPath := range repositories {
+		plans[i] = m.PlanWithoutScripts(repositoryPath)
+	}
+	return plans
+}
+
+// applyToolchains reads the version pins that apply to the step. When a
+// version manager can provide the pinned version, the step's commands are
+// rewritten to install it and run through it; otherwise the pins are
+// compared with the tools found in the step's directory.
+func (m *Manager) applyToolchains(repositoryPath string, step *Step) {
+	tool := detect.ToolFor(step.Ecosystem)
+	if tool == "" {
+		return
+	}
+
+	projectPath := filepath.Join(repositoryPath, filepath.FromSlash(step.Dir))
+	toolchains := detect.FindToolchains(repositoryPath, projectPath, tool)
+
+	step.Activation = activationFor(tool, m.versionManagers[tool], toolchains, m.installVersions)
+	if activation := step.Activation; activation != nil {
+		commands := make([]detect.Command, 0, len(step.Commands)+1)
+		if setup, ok := activation.SetupCommand(); ok {
+			commands = append(commands, setup)
+		}
+		for _, cmd := range step.Commands {
+			commands = append(commands, activation.Wrap(cmd))
+		}
+		step.Commands = commands
+
+		for i := range toolchains {
+			toolchains[i].Manager = activation.Manager
+			toolchains[i].Checked = true
+			toolchains[i].Satisfied = true
+		}
+		step.Toolchains = toolchains
+		return
+	}
+
+	if m.toolchainPolicy == ToolchainIgnore {
+		return
+	}
+	for i := range toolchains {
+		toolchains[i].Check(m.localVersion(tool, projectPath))
+	}
+	step.Toolchains = toolchains
+}
+
+// localVersion caches tool versions by directory, since version managers
+// may select a different one per project.
+func (m *Manager) localVersion(tool, dir string) string {
+	key := tool + "\x00" + dir
+
+	m.versionsMu.Lock()
+	defer m.versionsMu.Unlock()
+
+	if version, ok := m.versions[key]; ok {
+		return version
+	}
+	version, _ := detect.LocalVersion(context.Background(), tool, dir)
+	m.versions[key] = version
+	return version
+}
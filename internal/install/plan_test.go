@@ -0,0 +1,63 @@
+package install
+
+import (
+	"cmp"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPlanDeduplicates(t *testing.T) {
+	root := t.TempDir()
+	// React, Vite and Node.js share the root package.json, beside a Ruby
+	// Gemfile; web is a Node.js project of its own
+	writeFile(t, root, "package.json", `{"dependencies": {"react": "^18.2.0"}}`, 0o644)
+	writeFile(t, root, "package-lock.json", `{"lockfileVersion": 3, "packages": {}}`, 0o644)
+	writeFile(t, root, "vite.config.js", "export default {}\n", 0o644)
+	writeFile(t, root, "Gemfile", "source 'https://rubygems.org'\n", 0o644)
+	writeFile(t, root, "web/package.json", `{"name": "web"}`, 0o644)
+
+	manager := NewManager(1, time.Minute)
+	plan := manager.Plan(root)
+	if plan.Error != nil {
+		t.Fatalf("Plan: %v", plan.Error)
+	}
+
+	type key struct{ dir, ecosystem string }
+	var keys []key
+	steps := make(map[key]Step)
+	for _, step := range plan.Steps {
+		k := key{step.Dir, step.Ecosystem}
+		if _, seen := steps[k]; seen {
+			t.Errorf("two steps for %s in %q", step.Ecosystem, step.Dir)
+		}
+		keys = append(keys, k)
+		steps[k] = step
+	}
+	want := []key{{"", "Node.js"}, {"", "Ruby"}, {"web", "Node.js"}}
+	slices.SortFunc(keys, func(a, b key) int {
+		return cmp.Or(cmp.Compare(a.dir, b.dir), cmp.Compare(a.ecosystem, b.ecosystem))
+	})
+	if !slices.Equal(keys, want) {
+		t.Fatalf("steps = %v, want %v", keys, want)
+	}
+
+	node := steps[key{"", "Node.js"}]
+	if node.Project != "React (Package)" {
+		t.Errorf("root Node.js step runs %s's commands, want React (Package)", node.Project)
+	}
+	for _, name := range []string{"React (Package)", "Vite", "Node.js"} {
+		if !slices.Contains(node.Matched, name) {
+			t.Errorf("root Node.js step matched %q, missing %s", node.Matched, name)
+		}
+	}
+	installs := 0
+	for _, cmd := range node.Commands {
+		if cmd.Name == "npm-ci" || cmd.Name == "npm-install" {
+			installs++
+		}
+	}
+	if installs != 1 {
+		t.Errorf("root Node.js step installs %d times: %v", installs, node.Commands)
+	}
+}
@@ -0,0 +1,205 @@
+package install
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// BridgeCommand is the hidden quikgit subcommand that runs inside the
+// sandbox's network namespace, see RunBridge.
+const BridgeCommand = "__sandbox-bridge"
+
+// hostProxy is an HTTP proxy that only connects to allowed hosts. It
+// listens on a unix socket that is the only way out of a sandbox with
+// network allowlist: the sandbox keeps its own network namespace, and a
+// bridge inside it relays loopback connections to the socket.
+type hostProxy struct {
+	allowed  []string
+	ports    []string
+	dir      string
+	listener net.Listener
+	server   *http.Server
+}
+
+// startProxy listens on a unix socket in a private temporary directory. An
+// allowed entry matches the host itself, or any subdomain when written as
+// ".example.com".
+func startProxy(allowed []string) (*hostProxy, error) {
+	dir, err := os.MkdirTemp("", "quikgit-proxy-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the network allowlist proxy: %w", err)
+	}
+	listener, err := net.Listen("unix", filepath.Join(dir, "proxy.sock"))
+	if err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed to start the network allowlist proxy: %w", err)
+	}
+
+	proxy := &hostProxy{allowed: allowed, ports: []string{"80", "443"}, dir: dir, listener: listener}
+	proxy.server = &http.Server{Handler: proxy, ReadHeaderTimeout: 30 * time.Second}
+	go proxy.server.Serve(listener)
+	return proxy, nil
+}
+
+// Dir is the directory holding the proxy's socket, bound into the sandbox.
+func (p *hostProxy) Dir() string {
+	return p.dir
+}
+
+// Socket is the path of the proxy's unix socket.
+func (p *hostProxy) Socket() string {
+	return p.listener.Addr().String()
+}
+
+func (p *hostProxy) Close() {
+	p.server.Close()
+	os.RemoveAll(p.dir)
+}
+
+func (p *hostProxy) allows(host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	for _, allowed := range p.allowed {
+		allowed = strings.ToLower(strings.TrimPrefix(allowed, "*"))
+		if host == allowed || (strings.HasPrefix(allowed, ".") && (strings.HasSuffix(host, allowed) || host == allowed[1:])) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *hostProxy) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	host, port := r.URL.Hostname(), r.URL.Port()
+	if r.Method == http.MethodConnect {
+		host, port, _ = net.SplitHostPort(r.Host)
+	}
+	if !p.allows(host) {
+		http.Error(w, fmt.Sprintf("quikgit sandbox: %s is not an allowed host", host), http.StatusForbidden)
+		return
+	}
+	// Registries are reached over HTTP and HTTPS only, not whatever else an
+	// allowed host listens on
+	if port == "" && r.Method != http.MethodConnect {
+		port = "80"
+	}
+	if !slices.Contains(p.ports, port) {
+		http.Error(w, fmt.Sprintf("quikgit sandbox: port %s is not allowed", port), http.StatusForbidden)
+		return
+	}
+
+	if r.Method == http.MethodConnect {
+		p.tunnel(w, r)
+		return
+	}
+
+	r.RequestURI = ""
+	r.Header.Del("Proxy-Connection")
+	r.Header.Del("Proxy-Authorization")
+	resp, err := http.DefaultTransport.RoundTrip(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	defer resp.Body.Close()
+
+	for key, values := range resp.Header {
+		for _, value := range values {
+			w.Header().Add(key, value)
+		}
+	}
+	w.WriteHeader(resp.StatusCode)
+	io.Copy(w, resp.Body)
+}
+
+// tunnel relays a CONNECT request, used for HTTPS.
+func (p *hostProxy) tunnel(w http.ResponseWriter, r *http.Request) {
+	upstream, err := net.DialTimeout("tcp", r.Host, 30*time.Second)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		upstream.Close()
+		http.Error(w, "tunneling not supported", http.StatusInternalServerError)
+		return
+	}
+	client, _, err := hijacker.Hijack()
+	if err != nil {
+		upstream.Close()
+		return
+	}
+	client.Write([]byte("HTTP/1.1 200 Connection Established\r\n\r\n"))
+
+	go func() {
+		io.Copy(upstream, client)
+		upstream.Close()
+	}()
+	io.Copy(client, upstream)
+	client.Close()
+}
+
+// RunBridge runs inside the sandbox: it listens on loopback in the
+// sandbox's network namespace, relays every connection to the proxy socket,
+// and runs args with HTTP(S)_PROXY pointing at itself. It returns the
+// command's exit code.
+func RunBridge(socket string, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "quikgit sandbox: no command to run")
+		return 2
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "quikgit sandbox: failed to start the proxy bridge: %v\n", err)
+		return 1
+	}
+	defer listener.Close()
+	go relay(listener, socket)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.Env = withProxy(os.Environ(), "http://"+listener.Addr().String())
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+		fmt.Fprintf(os.Stderr, "quikgit sandbox: %v\n", err)
+		return 127
+	}
+	return 0
+}
+
+// relay copies each connection accepted on listener to and from socket.
+func relay(listener net.Listener, socket string) {
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer client.Close()
+			upstream, err := net.Dial("unix", socket)
+			if err != nil {
+				return
+			}
+			defer upstream.Close()
+
+			go func() {
+				io.Copy(upstream, client)
+				if conn, ok := upstream.(*net.UnixConn); ok {
+					conn.CloseWrite()
+				}
+			}()
+			io.Copy(client, upstream)
+		}()
+	}
+}
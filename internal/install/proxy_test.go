@@ -0,0 +1,93 @@
+package install
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestProxyBridge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "ok")
+	}))
+	defer server.Close()
+
+	proxy, err := startProxy([]string{"127.0.0.1"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer proxy.Close()
+	serverURL, _ := url.Parse(server.URL)
+	proxy.ports = append(proxy.ports, serverURL.Port())
+
+	// The bridge side, as RunBridge sets it up inside the sandbox
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go relay(listener, proxy.Socket())
+
+	proxyURL, _ := url.Parse("http://" + listener.Addr().String())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(proxyURL)}}
+
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("allowed host: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || string(body) != "ok" {
+		t.Errorf("allowed host = %d %q, want 200 \"ok\"", resp.StatusCode, body)
+	}
+
+	for _, blocked := range []string{"http://example.com/", "http://127.0.0.1:22/"} {
+		resp, err = client.Get(blocked)
+		if err != nil {
+			t.Fatalf("%s: %v", blocked, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusForbidden {
+			t.Errorf("%s = %d, want 403", blocked, resp.StatusCode)
+		}
+	}
+
+	// CONNECT only reaches the HTTP and HTTPS ports of an allowed host
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "CONNECT 127.0.0.1:22 HTTP/1.1\r\nHost: 127.0.0.1:22\r\n\r\n")
+	resp, err = http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Errorf("CONNECT to port 22 = %d, want 403", resp.StatusCode)
+	}
+}
+
+func TestProxyAllows(t *testing.T) {
+	proxy := &hostProxy{allowed: []string{"registry.npmjs.org", ".pypi.org"}}
+	tests := map[string]bool{
+		"registry.npmjs.org":      true,
+		"REGISTRY.npmjs.org.":     true,
+		"pypi.org":                true,
+		"files.pypi.org":          true,
+		"evil.registry.npmjs.org": false,
+		"notpypi.org":             false,
+		"example.com":             false,
+	}
+	for host, want := range tests {
+		if got := proxy.allows(host); got != want {
+			t.Errorf("allows(%q) = %v, want %v", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,321 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// Sandbox modes decide whether install commands run under bubblewrap.
+const (
+	SandboxOff      = "off"
+	SandboxAuto     = "auto"
+	SandboxRequired = "required"
+)
+
+// Network access inside the sandbox.
+const (
+	NetworkFull      = "full"
+	NetworkNone      = "none"
+	NetworkAllowlist = "allowlist"
+)
+
+// Sandbox runs install commands under bubblewrap: the filesystem is
+// read-only except for the repository and existing package caches,
+// credentials are hidden and scrubbed from the environment, and network
+// access can be limited to allowed hosts. With the allowlist the command
+// has no network of its own, only a filtering proxy.
+type Sandbox struct {
+	Mode         string
+	Network      string
+	AllowedHosts []string
+
+	probeOnce sync.Once
+	bwrap     string
+	err       error // Why bubblewrap cannot be used
+
+	proxyOnce sync.Once
+	proxy     *hostProxy
+	proxyErr  error
+}
+
+// NewSandbox creates a sandbox; nothing is checked until it is used.
+func NewSandbox(mode, network string, allowedHosts []string) *Sandbox {
+	return &Sandbox{Mode: mode, Network: network, AllowedHosts: allowedHosts}
+}
+
+// Available reports why bubblewrap cannot be used, or nil when it can. It
+// probes once, since user namespaces may be disabled even when bwrap is
+// installed.
+func (s *Sandbox) Available() error {
+	s.probeOnce.Do(func() {
+		if runtime.GOOS != "linux" {
+			s.err = fmt.Errorf("sandboxing needs Linux")
+			return
+		}
+		bwrap, err := exec.LookPath("bwrap")
+		if err != nil {
+			s.err = fmt.Errorf("bwrap (bubblewrap) not found")
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		output, err := exec.CommandContext(ctx, bwrap, "--ro-bind", "/", "/", "--dev", "/dev", "--unshare-all", "true").CombinedOutput()
+		if err != nil {
+			s.err = fmt.Errorf("bwrap does not work here: %s", strings.TrimSpace(string(output)))
+			return
+		}
+		s.bwrap = bwrap
+	})
+	return s.err
+}
+
+// Enabled reports whether commands run sandboxed.
+func (s *Sandbox) Enabled() bool {
+	return s != nil && s.Mode != SandboxOff && s.Available() == nil
+}
+
+// Describe summarizes the sandbox, e.g. "bubblewrap, network: none".
+func (s *Sandbox) Describe() string {
+	network := s.Network
+	if network == NetworkAllowlist {
+		network = fmt.Sprintf("%d allowed hosts", len(s.AllowedHosts))
+	}
+	return "bubblewrap, network: " + network
+}
+
+// Command returns cmd run in dir under bubblewrap, with write access to the
+// repository at root.
+func (s *Sandbox) Command(ctx context.Context, root, dir string, cmd detect.Command) (*exec.Cmd, error) {
+	if err := s.Available(); err != nil {
+		return nil, err
+	}
+
+	args := s.mounts(root)
+	args = append(args, "--chdir", dir, "--")
+
+	env := append(scrubEnv(os.Environ()), cmd.Env...)
+	if s.Network == NetworkAllowlist {
+		proxy, err := s.startProxy()
+		if err != nil {
+			return nil, err
+		}
+		executable, err := os.Executable()
+		if err != nil {
+			return nil, fmt.Errorf("failed to locate quikgit for the proxy bridge: %w", err)
+		}
+		// The socket's directory is bound after the /tmp tmpfs
+		args = slices.Insert(args, len(args)-1, "--bind", proxy.Dir(), proxy.Dir())
+		args = append(args, executable, BridgeCommand, proxy.Socket(), "--")
+	}
+	args = append(args, cmd.Command)
+	args = append(args, cmd.Args...)
+
+	execCmd := exec.CommandContext(ctx, s.bwrap, args...)
+	execCmd.Dir = dir
+	execCmd.Env = env
+	return execCmd, nil
+}
+
+// mounts returns the bubblewrap options up to the command: everything is
+// read-only except the repository at root and the package caches, and
+// nothing written there may run on the host later. Toolchains stay
+// read-only even below a cache, credentials are masked, and root's .git
+// is read-only so that hooks and git configuration cannot be planted.
+func (s *Sandbox) mounts(root string) []string {
+	args := []string{
+		"--ro-bind", "/", "/",
+		"--dev", "/dev",
+		"--proc", "/proc",
+		"--tmpfs", "/tmp",
+		"--unshare-all",
+		"--die-with-parent",
+		"--new-session",
+	}
+	// Only full access shares the host network; with the allowlist the
+	// proxy socket is the way out
+	if s.Network == NetworkFull {
+		args = append(args, "--share-net")
+	}
+
+	// Caches are bound first so that toolchains and credentials inside
+	// them stay protected
+	home, _ := os.UserHomeDir()
+	for _, path := range cacheDirs(home) {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			args = append(args, "--bind", path, path)
+		}
+	}
+	for _, path := range toolchainDirs(home) {
+		if _, err := os.Stat(path); err == nil {
+			args = append(args, "--ro-bind", path, path)
+		}
+	}
+	for _, path := range secretPaths(home) {
+		info, err := os.Stat(path)
+		switch {
+		case err != nil:
+		case info.IsDir():
+			args = append(args, "--tmpfs", path)
+		default:
+			args = append(args, "--ro-bind", os.DevNull, path)
+		}
+	}
+
+	args = append(args, "--bind", root, root)
+	if gitDir := filepath.Join(root, ".git"); exists(gitDir) {
+		args = append(args, "--ro-bind", gitDir, gitDir)
+	}
+	return args
+}
+
+// Close stops the network allowlist proxy, if it was started.
+func (s *Sandbox) Close() {
+	if s != nil && s.proxy != nil {
+		s.proxy.Close()
+	}
+}
+
+func (s *Sandbox) startProxy() (*hostProxy, error) {
+	s.proxyOnce.Do(func() {
+		s.proxy, s.proxyErr = startProxy(s.AllowedHosts)
+	})
+	return s.proxy, s.proxyErr
+}
+
+// secretPaths are credentials hidden from sandboxed commands, including the
+// registry tokens package managers keep in their configuration.
+func secretPaths(home string) []string {
+	if home == "" {
+		return nil
+	}
+	var paths []string
+	for _, path := range []string{
+		".ssh", ".gnupg", ".aws", ".azure", ".kube", ".docker",
+		".config/gh", ".config/gcloud", ".quikgit",
+		".netrc", ".git-credentials",
+		".npmrc", ".yarnrc", ".pypirc", ".gem/credentials", ".bundle/config",
+		".m2/settings.xml", ".m2/settings-security.xml", ".gradle/gradle.properties",
+		".hex/hex.config", ".luarocks/upload_config.lua",
+	} {
+		paths = append(paths, filepath.Join(home, path))
+	}
+
+	cargoHome := envOr("CARGO_HOME", filepath.Join(home, ".cargo"))
+	configHome := envOr("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	return append(paths,
+		filepath.Join(cargoHome, "credentials"),
+		filepath.Join(cargoHome, "credentials.toml"),
+		filepath.Join(configHome, "git", "credentials"),
+	)
+}
+
+// cacheDirs are the package caches sandboxed commands may write to. They
+// hold downloads rather than programs the host runs, and where a tool keeps
+// credentials, binaries or toolchains beside its cache, only the cache
+// subdirectories are listed.
+func cacheDirs(home string) []string {
+	if home == "" {
+		return nil
+	}
+	cacheHome := envOr("XDG_CACHE_HOME", filepath.Join(home, ".cache"))
+	dirs := []string{
+		envOr("npm_config_cache", filepath.Join(home, ".npm")),
+		filepath.Join(envOr("GOPATH", filepath.Join(home, "go")), "pkg", "mod"),
+		filepath.Join(envOr("CARGO_HOME", filepath.Join(home, ".cargo")), "registry", "cache"),
+	}
+	for _, path := range []string{"pip", "uv", "yarn", "pypoetry", "composer", "pnpm"} {
+		dirs = append(dirs, filepath.Join(cacheHome, path))
+	}
+	for _, path := range []string{
+		".pnpm-store", ".local/share/pnpm/store", ".bun/install/cache", ".yarn/berry/cache",
+		".gem/specs", ".bundle/cache", ".m2/repository", ".gradle/caches",
+		".nuget/packages", ".pub-cache/hosted", ".hex/packages", ".stack/pantry",
+		".cabal/packages", ".nimble/pkgs2", ".julia/packages", ".julia/registries",
+		".luarocks/lib", ".luarocks/share",
+	} {
+		dirs = append(dirs, filepath.Join(home, path))
+	}
+	return dirs
+}
+
+// toolchainDirs hold programs the host runs, such as the interpreters and
+// compilers of version managers. They are bound read-only, after the
+// caches, so that a sandboxed command cannot replace them.
+func toolchainDirs(home string) []string {
+	if home == "" {
+		return nil
+	}
+	cargoHome := envOr("CARGO_HOME", filepath.Join(home, ".cargo"))
+	dirs := []string{
+		filepath.Join(cargoHome, "bin"),
+		envOr("RUSTUP_HOME", filepath.Join(home, ".rustup")),
+		filepath.Join(envOr("GOPATH", filepath.Join(home, "go")), "bin"),
+		filepath.Join(envOr("XDG_CACHE_HOME", filepath.Join(home, ".cache")), "go-build"),
+	}
+	for _, path := range []string{
+		".local/share/mise", ".local/share/uv", ".local/bin", ".asdf", ".nvm",
+		".pyenv", ".volta", ".bun/bin", ".gem/ruby", ".gradle/wrapper",
+		".pub-cache/bin", ".mix", ".cabal/bin", ".cabal/store", ".nimble/bin",
+		".opam", ".julia/bin", ".luarocks/bin", ".stack/programs",
+	} {
+		dirs = append(dirs, filepath.Join(home, path))
+	}
+	return dirs
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func envOr(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// sensitiveEnv matches variables likely to hold credentials, such as
+// GITHUB_TOKEN, NPM_TOKEN, AWS_SECRET_ACCESS_KEY or SSH_AUTH_SOCK.
+var sensitiveEnv = regexp.MustCompile(`(?i)(TOKEN|SECRET|PASSWORD|PASSWD|CREDENTIAL|(^|_)(API_?)?KEY(_|$)|(^|_)AUTH(_|$)|^AWS_|^AZURE_)`)
+
+// scrubEnv drops the credential-bearing variables from env.
+func scrubEnv(env []string) []string {
+	scrubbed := make([]string, 0, len(env))
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		if !sensitiveEnv.MatchString(name) {
+			scrubbed = append(scrubbed, entry)
+		}
+	}
+	return scrubbed
+}
+
+// withProxy points env at the allowlist proxy, replacing any proxy settings.
+func withProxy(env []string, proxyURL string) []string {
+	proxied := make([]string, 0, len(env)+4)
+	for _, entry := range env {
+		name, _, _ := strings.Cut(entry, "=")
+		switch strings.ToUpper(name) {
+		case "HTTP_PROXY", "HTTPS_PROXY", "ALL_PROXY", "NO_PROXY":
+			continue
+		}
+		proxied = append(proxied, entry)
+	}
+	for _, name := range []string{"HTTP_PROXY", "HTTPS_PROXY", "http_proxy", "https_proxy"} {
+		proxied = append(proxied, name+"="+proxyURL)
+	}
+	return proxied
+}
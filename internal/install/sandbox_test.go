@@ -0,0 +1,132 @@
+package install
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+func TestSandboxCredentials(t *testing.T) {
+	t.Setenv("CARGO_HOME", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+	home := "/home/user"
+	secrets := secretPaths(home)
+
+	for _, path := range []string{
+		".npmrc", ".pypirc", ".cargo/credentials", ".cargo/credentials.toml",
+		".gem/credentials", ".m2/settings.xml", ".gradle/gradle.properties",
+		".config/git/credentials",
+	} {
+		if !slices.Contains(secrets, filepath.Join(home, path)) {
+			t.Errorf("~/%s is not hidden", path)
+		}
+	}
+
+	// A writable cache must not hold credentials or toolchains
+	protected := append(secrets, toolchainDirs(home)...)
+	for _, cache := range cacheDirs(home) {
+		for _, path := range protected {
+			if path == cache || strings.HasPrefix(path, cache+string(filepath.Separator)) {
+				t.Errorf("cache %s exposes %s", cache, path)
+			}
+		}
+	}
+}
+
+// sandboxHome creates a home in base with a toolchain, a package cache and
+// another cache directory, and a repository in it.
+func sandboxHome(t *testing.T, base string) (home, root string) {
+	t.Helper()
+	home = filepath.Join(base, "home")
+	t.Setenv("HOME", home)
+	for _, key := range []string{"XDG_CACHE_HOME", "XDG_CONFIG_HOME", "CARGO_HOME", "RUSTUP_HOME", "GOPATH", "npm_config_cache"} {
+		t.Setenv(key, "")
+	}
+	for _, dir := range []string{".nvm/versions/node/bin", ".npm", ".cache/pip", ".cache/other", "repo/.git/hooks"} {
+		if err := os.MkdirAll(filepath.Join(home, dir), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return home, filepath.Join(home, "repo")
+}
+
+func TestSandboxMounts(t *testing.T) {
+	home, root := sandboxHome(t, t.TempDir())
+	args := strings.Join(NewSandbox(SandboxAuto, NetworkNone, nil).mounts(root), " ")
+
+	for _, want := range []string{
+		"--bind " + filepath.Join(home, ".npm"),
+		"--bind " + filepath.Join(home, ".cache", "pip"),
+		"--ro-bind " + filepath.Join(home, ".nvm"),
+		"--bind " + root + " " + root + " --ro-bind " + filepath.Join(root, ".git"),
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("mounts lack %q:\n%s", want, args)
+		}
+	}
+	for _, unwanted := range []string{
+		"--bind " + filepath.Join(home, ".nvm"),
+		"--bind " + filepath.Join(home, ".cache") + " ",
+		"--bind " + filepath.Join(home, ".cache", "other"),
+		"--share-net",
+	} {
+		if strings.Contains(args, unwanted) {
+			t.Errorf("mounts hold %q:\n%s", unwanted, args)
+		}
+	}
+}
+
+// TestSandboxWrites runs commands under bubblewrap, where it works
+func TestSandboxWrites(t *testing.T) {
+	sandbox := NewSandbox(SandboxRequired, NetworkNone, nil)
+	if err := sandbox.Available(); err != nil {
+		t.Skipf("sandbox unavailable: %v", err)
+	}
+
+	// Not below /tmp, which the sandbox replaces with a tmpfs
+	base, err := os.MkdirTemp(".", "sandbox-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(base) })
+	if base, err = filepath.Abs(base); err != nil {
+		t.Fatal(err)
+	}
+	home, root := sandboxHome(t, base)
+
+	write := func(path string) error {
+		cmd, err := sandbox.Command(context.Background(), root, root, detect.Command{
+			Command: "sh",
+			Args:    []string{"-c", `echo planted > "$0"`, path},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return cmd.Run()
+	}
+
+	for _, path := range []string{
+		filepath.Join(root, "node_modules.txt"),
+		filepath.Join(home, ".npm", "entry"),
+	} {
+		if err := write(path); err != nil {
+			t.Errorf("writing %s failed: %v", path, err)
+		}
+	}
+	for _, path := range []string{
+		filepath.Join(home, ".nvm", "versions", "node", "bin", "node"),
+		filepath.Join(home, ".cache", "other", "entry"),
+		filepath.Join(root, ".git", "hooks", "post-checkout"),
+		filepath.Join(root, ".git", "config"),
+	} {
+		if err := write(path); err == nil {
+			t.Errorf("writing %s succeeded", path)
+		}
+	}
+}
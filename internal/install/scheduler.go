@@ -0,0 +1,314 @@
+package install
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+// Command classes, limited separately by the scheduler.
+const (
+	ClassNetwork = "network" // Mostly downloads, such as npm install
+	ClassBuild   = "build"   // Mostly compiles, such as cargo build
+)
+
+// Limits bound what install commands run at once. Zero values are replaced
+// with defaults by NewScheduler.
+type Limits struct {
+	Commands int // Commands across all classes; 0 for no limit
+	Network  int // Network-bound commands; 0 for no limit
+	Build    int // CPU-bound commands; 0 for half the CPUs
+	CPUs     int // CPU budget; 0 for every CPU
+	MemoryMB int // Memory budget in MiB; 0 for half the system memory, if known
+}
+
+// cost is what one running command is expected to use.
+type cost struct {
+	cpus     int
+	memoryMB int
+}
+
+// commandCost estimates a command's use by its class; JVM builds are the
+// hungriest.
+func commandCost(cmd detect.Command, class string) cost {
+	if class == ClassNetwork {
+		return cost{cpus: 1, memoryMB: 512}
+	}
+	switch strings.TrimSuffix(filepath.Base(cmd.Command), ".bat") {
+	case "mvn", "mvnw", "gradle", "gradlew", "sbt":
+		return cost{cpus: 2, memoryMB: 3072}
+	}
+	return cost{cpus: 2, memoryMB: 1536}
+}
+
+// buildTools always compile, whatever their arguments.
+var buildTools = []string{"make", "gmake", "cmake", "ninja", "mvn", "mvnw", "gradle", "gradlew", "sbt", "opam", "dune", "msbuild"}
+
+// Classify returns the command's class: the one its detector declares, or
+// ClassBuild for build tools and "build" or "compile" subcommands, and
+// ClassNetwork for everything else, which mostly downloads.
+func Classify(cmd detect.Command) string {
+	if cmd.Class != "" {
+		return cmd.Class
+	}
+	if slices.Contains(buildTools, strings.TrimSuffix(filepath.Base(cmd.Command), ".bat")) {
+		return ClassBuild
+	}
+	if slices.Contains(cmd.Args, "--fetch") || slices.Contains(cmd.Args, "fetch") {
+		return ClassNetwork
+	}
+	for _, arg := range cmd.Args {
+		if arg == "build" || arg == "compile" {
+			return ClassBuild
+		}
+	}
+	return ClassNetwork
+}
+
+// classWeight orders plans so quick installs run first: builds take far
+// longer than downloads.
+var classWeight = map[string]int{ClassNetwork: 1, ClassBuild: 5}
+
+// Estimate is the plan's expected length in arbitrary units, used to
+// install quick repositories first.
+func (p *Plan) Estimate() int {
+	estimate := 0
+	for _, step := range p.Steps {
+		for i, cmd := range step.Commands {
+			if !step.Skip[i] {
+				estimate += classWeight[Classify(cmd)]
+			}
+		}
+	}
+	return estimate
+}
+
+// QueueEntry is a command the scheduler runs or holds back.
+type QueueEntry struct {
+	Repository string
+	Command    string
+	Class      string
+	Running    bool
+	Waiting    string // What a held command waits for, e.g. "a build slot"
+}
+
+// Scheduler hands out slots to install commands: each class has its own
+// limit, every command counts against the CPU and memory budgets, and
+// waiting commands are started shortest repository first.
+type Scheduler struct {
+	limits Limits
+
+	mu      sync.Mutex
+	waiting []*ticket
+	running []*ticket
+	seq     int
+	cpus    int // In use
+	memory  int // In use, in MiB
+	classes map[string]int
+}
+
+type ticket struct {
+	repository string
+	command    string
+	class      string
+	priority   int // Lower runs first
+	seq        int
+	cost       cost
+	waiting    string
+	ready      chan struct{}
+}
+
+// NewScheduler creates a scheduler with limits, zero values replaced by
+// their defaults.
+func NewScheduler(limits Limits) *Scheduler {
+	if limits.Build <= 0 {
+		limits.Build = max(1, runtime.NumCPU()/2)
+	}
+	if limits.CPUs <= 0 {
+		limits.CPUs = runtime.NumCPU()
+	}
+	if limits.MemoryMB <= 0 {
+		limits.MemoryMB = systemMemoryMB() / 2
+	}
+	return &Scheduler{limits: limits, classes: make(map[string]int)}
+}
+
+// Limits returns the limits in effect.
+func (s *Scheduler) Limits() Limits {
+	return s.limits
+}
+
+// Acquire waits until cmd may run and returns the function that gives its
+// slot back. Commands with a lower priority run first.
+func (s *Scheduler) Acquire(ctx context.Context, repository string, cmd detect.Command, priority int) (release func(), err error) {
+	class := Classify(cmd)
+	c := commandCost(cmd, class)
+	// A command needing more than the whole budget still runs, alone
+	c.cpus = min(c.cpus, s.limits.CPUs)
+	if s.limits.MemoryMB > 0 {
+		c.memoryMB = min(c.memoryMB, s.limits.MemoryMB)
+	}
+
+	s.mu.Lock()
+	s.seq++
+	t := &ticket{
+		repository: repository,
+		command:    commandLine(cmd),
+		class:      class,
+		priority:   priority,
+		seq:        s.seq,
+		cost:       c,
+		ready:      make(chan struct{}),
+	}
+	s.waiting = append(s.waiting, t)
+	s.dispatch()
+	s.mu.Unlock()
+
+	release = func() {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if i := slices.Index(s.running, t); i >= 0 {
+			s.running = slices.Delete(s.running, i, i+1)
+			s.cpus -= t.cost.cpus
+			s.memory -= t.cost.memoryMB
+			s.classes[t.class]--
+			s.dispatch()
+		}
+	}
+
+	select {
+	case <-t.ready:
+		return release, nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if i := slices.Index(s.waiting, t); i >= 0 {
+			s.waiting = slices.Delete(s.waiting, i, i+1)
+			s.mu.Unlock()
+		} else {
+			// Granted while cancelling
+			s.mu.Unlock()
+			release()
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// dispatch starts the waiting commands that fit, in priority order. A
+// command held back by the CPU or memory budget holds back the later ones
+// of its class, so small commands cannot starve a large build. Callers hold
+// s.mu.
+func (s *Scheduler) dispatch() {
+	slices.SortStableFunc(s.waiting, func(a, b *ticket) int {
+		if a.priority != b.priority {
+			return a.priority - b.priority
+		}
+		return a.seq - b.seq
+	})
+
+	held := make(map[string]bool)
+	var still []*ticket
+	for _, t := range s.waiting {
+		t.waiting = s.blocker(t, held)
+		if t.waiting != "" {
+			still = append(still, t)
+			continue
+		}
+		s.running = append(s.running, t)
+		s.cpus += t.cost.cpus
+		s.memory += t.cost.memoryMB
+		s.classes[t.class]++
+		close(t.ready)
+	}
+	s.waiting = still
+}
+
+// blocker returns what keeps t from starting, or "" when it can start.
+func (s *Scheduler) blocker(t *ticket, held map[string]bool) string {
+	limit := s.limits.Network
+	if t.class == ClassBuild {
+		limit = s.limits.Build
+	}
+
+	switch {
+	case s.limits.Commands > 0 && len(s.running) >= s.limits.Commands:
+		return "a free slot"
+	case limit > 0 && s.classes[t.class] >= limit:
+		return "a " + t.class + " slot"
+	case held[t.class]:
+		return "an earlier " + t.class + " command"
+	case s.cpus+t.cost.cpus > s.limits.CPUs:
+		held[t.class] = true
+		return "CPU"
+	case s.limits.MemoryMB > 0 && s.memory+t.cost.memoryMB > s.limits.MemoryMB:
+		held[t.class] = true
+		return "memory"
+	}
+	return ""
+}
+
+// Queue lists the running commands, then the waiting ones in the order they
+// will start.
+func (s *Scheduler) Queue() []QueueEntry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	queue := make([]QueueEntry, 0, len(s.running)+len(s.waiting))
+	for _, t := range s.running {
+		queue = append(queue, QueueEntry{Repository: t.repository, Command: t.command, Class: t.class, Running: true})
+	}
+	for _, t := range s.waiting {
+		queue = append(queue, QueueEntry{Repository: t.repository, Command: t.command, Class: t.class, Waiting: t.waiting})
+	}
+	return queue
+}
+
+// Describe summarizes the limits, e.g. "4 network, 2 build, 8 CPUs, 7.8 GiB".
+func (s *Scheduler) Describe() string {
+	parts := []string{}
+	if s.limits.Commands > 0 {
+		parts = append(parts, fmt.Sprintf("%d commands", s.limits.Commands))
+	}
+	network := "unlimited"
+	if s.limits.Network > 0 {
+		network = strconv.Itoa(s.limits.Network)
+	}
+	parts = append(parts, network+" network", fmt.Sprintf("%d build", s.limits.Build), fmt.Sprintf("%d CPUs", s.limits.CPUs))
+	if s.limits.MemoryMB > 0 {
+		parts = append(parts, fmt.Sprintf("%.1f GiB", float64(s.limits.MemoryMB)/1024))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// commandLine is the command as shown in progress and results.
+func commandLine(cmd detect.Command) string {
+	return strings.TrimSpace(cmd.Command + " " + strings.Join(cmd.Args, " "))
+}
+
+// systemMemoryMB reads the total memory from /proc/meminfo, or returns 0
+// where there is none.
+func systemMemoryMB() int {
+	file, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, _ := strconv.Atoi(fields[1])
+			return kb / 1024
+		}
+	}
+	return 0
+}
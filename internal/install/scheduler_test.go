@@ -0,0 +1,170 @@
+package install
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lvcasx1/quikgit/internal/detect"
+)
+
+var (
+	networkCommand = detect.Command{Command: "npm", Args: []string{"ci"}}
+	buildCommand   = detect.Command{Command: "cargo", Args: []string{"build"}}
+	mavenCommand   = detect.Command{Command: "mvn", Args: []string{"install"}}
+)
+
+func TestSchedulerClassLimits(t *testing.T) {
+	limits := Limits{Commands: 3, Network: 2, Build: 1, CPUs: 64, MemoryMB: 1 << 20}
+	scheduler := NewScheduler(limits)
+
+	var running, peak struct {
+		sync.Mutex
+		classes map[string]int
+		total   int
+	}
+	running.classes = make(map[string]int)
+	peak.classes = make(map[string]int)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 24; i++ {
+		cmd := networkCommand
+		if i%3 == 0 {
+			cmd = buildCommand
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			release, err := scheduler.Acquire(context.Background(), fmt.Sprintf("repo%d", i), cmd, i%4)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			class := Classify(cmd)
+
+			running.Lock()
+			running.classes[class]++
+			running.total++
+			peak.Lock()
+			peak.classes[class] = max(peak.classes[class], running.classes[class])
+			peak.total = max(peak.total, running.total)
+			peak.Unlock()
+			running.Unlock()
+
+			time.Sleep(2 * time.Millisecond)
+
+			running.Lock()
+			running.classes[class]--
+			running.total--
+			running.Unlock()
+			release()
+		}()
+	}
+	wg.Wait()
+
+	if peak.classes[ClassNetwork] > limits.Network {
+		t.Errorf("%d network commands ran at once, limit %d", peak.classes[ClassNetwork], limits.Network)
+	}
+	if peak.classes[ClassBuild] > limits.Build {
+		t.Errorf("%d build commands ran at once, limit %d", peak.classes[ClassBuild], limits.Build)
+	}
+	if peak.total > limits.Commands {
+		t.Errorf("%d commands ran at once, limit %d", peak.total, limits.Commands)
+	}
+	if peak.classes[ClassNetwork] < 2 {
+		t.Errorf("network commands never ran side by side")
+	}
+	if queue := scheduler.Queue(); len(queue) != 0 {
+		t.Errorf("queue not empty after every release: %+v", queue)
+	}
+}
+
+// acquireWithin acquires a slot for cmd, failing the test when it is not
+// granted in time.
+func acquireWithin(t *testing.T, scheduler *Scheduler, repository string, cmd detect.Command) func() {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	release, err := scheduler.Acquire(ctx, repository, cmd, 0)
+	if err != nil {
+		t.Fatalf("%s: %s was not started: %v", repository, commandLine(cmd), err)
+	}
+	return release
+}
+
+// waitQueued waits until n commands wait in the scheduler's queue.
+func waitQueued(t *testing.T, scheduler *Scheduler, n int) []QueueEntry {
+	t.Helper()
+	for deadline := time.Now().Add(2 * time.Second); time.Now().Before(deadline); time.Sleep(time.Millisecond) {
+		var waiting []QueueEntry
+		for _, entry := range scheduler.Queue() {
+			if !entry.Running {
+				waiting = append(waiting, entry)
+			}
+		}
+		if len(waiting) == n {
+			return waiting
+		}
+	}
+	t.Fatalf("%d commands never waited: %+v", n, scheduler.Queue())
+	return nil
+}
+
+func TestSchedulerBuildDoesNotStarveNetwork(t *testing.T) {
+	scheduler := NewScheduler(Limits{Network: 2, Build: 1, CPUs: 8, MemoryMB: 1 << 20})
+
+	build := acquireWithin(t, scheduler, "a", buildCommand)
+	var started atomic.Bool
+	go func() {
+		release, err := scheduler.Acquire(context.Background(), "b", buildCommand, 0)
+		if err == nil {
+			started.Store(true)
+			release()
+		}
+	}()
+	if waiting := waitQueued(t, scheduler, 1); waiting[0].Waiting != "a build slot" {
+		t.Errorf("second build waits for %q, want a build slot", waiting[0].Waiting)
+	}
+
+	// Network commands start while the build slot is taken
+	first := acquireWithin(t, scheduler, "c", networkCommand)
+	second := acquireWithin(t, scheduler, "d", networkCommand)
+	first()
+	second()
+
+	if started.Load() {
+		t.Error("second build started before the first was done")
+	}
+	build()
+	for deadline := time.Now().Add(2 * time.Second); !started.Load(); time.Sleep(time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("second build never started once the slot was free")
+		}
+	}
+}
+
+func TestSchedulerMemoryHoldsOnlyItsClass(t *testing.T) {
+	// A running build leaves too little memory for a Maven build, which
+	// holds back later builds but not downloads
+	scheduler := NewScheduler(Limits{Build: 2, CPUs: 8, MemoryMB: 4096})
+
+	build := acquireWithin(t, scheduler, "a", buildCommand)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go scheduler.Acquire(ctx, "b", mavenCommand, 0)
+	waitQueued(t, scheduler, 1)
+	// Queued after the Maven build, so held back by it
+	go scheduler.Acquire(ctx, "c", buildCommand, 1)
+
+	waiting := waitQueued(t, scheduler, 2)
+	if waiting[0].Waiting != "memory" || waiting[1].Waiting != "an earlier build command" {
+		t.Errorf("waiting = %+v, want the Maven build waiting for memory and the build behind it", waiting)
+	}
+
+	release := acquireWithin(t, scheduler, "d", networkCommand)
+	release()
+	build()
+}
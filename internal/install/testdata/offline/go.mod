@@ -0,0 +1,13 @@
+module example.com/demo
+
+go 1.22
+
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/google/uuid v1.6.0 // indirect
+	golang.org/x/sync v0.7.0
+)
+
+require github.com/spf13/cobra v1.8.0
+
+replace golang.org/x/sync => ../sync
@@ -0,0 +1,265 @@
+package install
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+)
+
+// worktreeSnapshot is a repository's git status before an install, so
+// that only the changes the install makes are reported, and reverted.
+// File contents are left to git: tracked files are restored from the last
+// commit, and the files the install created are removed.
+type worktreeSnapshot struct {
+	status map[string]git.FileStatus
+	// files holds the state of the files already changed, which the
+	// install may change further
+	files map[string]fileState
+	// modes holds the mode of every tracked file, put back on revert
+	modes map[string]os.FileMode
+	// dirs holds the directories of the tracked and already changed files,
+	// which the install did not create
+	dirs map[string]bool
+}
+
+// fileState tells a file apart from its later versions without reading
+// it.
+type fileState struct {
+	exists  bool
+	mode    os.FileMode
+	size    int64
+	modTime time.Time
+}
+
+func statFile(root, file string) fileState {
+	info, err := os.Lstat(filepath.Join(root, filepath.FromSlash(file)))
+	if err != nil {
+		return fileState{}
+	}
+	return fileState{exists: true, mode: info.Mode(), size: info.Size(), modTime: info.ModTime()}
+}
+
+// revertState is what RevertChanges needs besides the files an install
+// modified and created.
+type revertState struct {
+	modes   map[string]os.FileMode // Mode before the install of the modified files
+	dirs    []string               // Directories the install created, deepest first
+	changed []string               // Files changed before the install, and since
+}
+
+// snapshotWorktree reads the status of the repository at root. It returns
+// nil for a directory that is not a git repository.
+func snapshotWorktree(root string) (*worktreeSnapshot, error) {
+	repo, err := openRepository(root)
+	if err != nil || repo == nil {
+		return nil, err
+	}
+	status, err := worktreeStatus(repo)
+	if err != nil {
+		return nil, err
+	}
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := &worktreeSnapshot{
+		status: status,
+		files:  make(map[string]fileState, len(status)),
+		modes:  make(map[string]os.FileMode, len(index.Entries)),
+		dirs:   make(map[string]bool),
+	}
+	for _, entry := range index.Entries {
+		if state := statFile(root, entry.Name); state.exists {
+			snapshot.modes[entry.Name] = state.mode
+		}
+		snapshot.addDirs(entry.Name)
+	}
+	for file := range status {
+		snapshot.files[file] = statFile(root, file)
+		snapshot.addDirs(file)
+	}
+	return snapshot, nil
+}
+
+// addDirs adds the directories file is in.
+func (s *worktreeSnapshot) addDirs(file string) {
+	for dir := path.Dir(file); dir != "." && !s.dirs[dir]; dir = path.Dir(dir) {
+		s.dirs[dir] = true
+	}
+}
+
+// changes compares the repository's status now with the snapshot. It
+// returns the tracked files changed since, and the files created that git
+// does not ignore, both sorted, with what reverting them needs.
+func (s *worktreeSnapshot) changes(root string) (modified, untracked []string, revert revertState, err error) {
+	repo, err := openRepository(root)
+	if err != nil {
+		return nil, nil, revert, err
+	}
+	status, err := worktreeStatus(repo)
+	if err != nil {
+		return nil, nil, revert, err
+	}
+
+	revert.modes = make(map[string]os.FileMode)
+	created := make(map[string]bool)
+	for file, after := range status {
+		before, changedBefore := s.status[file]
+		if changedBefore {
+			// Already changed: only a further change is the install's
+			if before == after && statFile(root, file) == s.files[file] {
+				continue
+			}
+			revert.changed = append(revert.changed, file)
+		}
+		if mode, ok := s.modes[file]; ok {
+			revert.modes[file] = mode
+		}
+		if after.Worktree != git.Untracked {
+			modified = append(modified, file)
+			continue
+		}
+		untracked = append(untracked, file)
+		for dir := path.Dir(file); dir != "." && !s.dirs[dir]; dir = path.Dir(dir) {
+			created[dir] = true
+		}
+	}
+
+	for dir := range created {
+		revert.dirs = append(revert.dirs, dir)
+	}
+	// Deepest first, so that directories are empty when removed
+	sort.Slice(revert.dirs, func(i, j int) bool {
+		if a, b := strings.Count(revert.dirs[i], "/"), strings.Count(revert.dirs[j], "/"); a != b {
+			return a > b
+		}
+		return revert.dirs[i] < revert.dirs[j]
+	})
+	sort.Strings(modified)
+	sort.Strings(untracked)
+	sort.Strings(revert.changed)
+	return modified, untracked, revert, nil
+}
+
+// openRepository opens the repository at path, or returns nil when path is
+// not a repository.
+func openRepository(path string) (*git.Repository, error) {
+	repo, err := git.PlainOpen(path)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, nil
+	}
+	return repo, err
+}
+
+// worktreeStatus returns the status of the files of repo that differ from
+// its last commit.
+func worktreeStatus(repo *git.Repository) (map[string]git.FileStatus, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]git.FileStatus, len(status))
+	for file, fileStatus := range status {
+		if fileStatus.Worktree != git.Unmodified || fileStatus.Staging != git.Unmodified {
+			files[file] = *fileStatus
+		}
+	}
+	return files, nil
+}
+
+// RevertChanges undoes what the install of result changed in the
+// repository at path. The tracked files it modified are restored from the
+// last commit, in the index too, with the mode they had, the untracked
+// files it created are removed, and so are the directories it created once
+// empty. Files that were already changed before the install are left as
+// they are, since only git's version of them is known, and reported in the
+// error.
+func RevertChanges(path string, result InstallResult) error {
+	state := result.revert
+	var restore []string
+	for _, file := range result.Modified {
+		if !slices.Contains(state.changed, file) {
+			restore = append(restore, file)
+		}
+	}
+	for _, file := range result.Untracked {
+		if slices.Contains(state.changed, file) {
+			continue
+		}
+		if err := os.Remove(filepath.Join(path, filepath.FromSlash(file))); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+
+	if len(restore) > 0 {
+		repo, err := git.PlainOpen(path)
+		if err != nil {
+			return err
+		}
+		worktree, err := repo.Worktree()
+		if err != nil {
+			return err
+		}
+		if err := worktree.Restore(&git.RestoreOptions{Staged: true, Worktree: true, Files: restore}); err != nil {
+			return fmt.Errorf("failed to restore %d files: %w", len(restore), err)
+		}
+		for _, file := range restore {
+			mode, ok := state.modes[file]
+			if !ok || mode&os.ModeSymlink != 0 {
+				continue
+			}
+			if err := os.Chmod(filepath.Join(path, filepath.FromSlash(file)), mode.Perm()); err != nil {
+				return err
+			}
+		}
+	}
+
+	// Directories the install also put ignored files in are kept
+	for _, dir := range state.dirs {
+		os.Remove(filepath.Join(path, filepath.FromSlash(dir)))
+	}
+
+	if len(state.changed) > 0 {
+		return fmt.Errorf("left %s as they were, they were already changed before the install", strings.Join(state.changed, ", "))
+	}
+	return nil
+}
+
+// recordChanges sets the result's modified and untracked files since
+// snapshot, leaving out the environment files created on purpose, and
+// warns about them.
+func recordChanges(result *InstallResult, path string, snapshot *worktreeSnapshot) {
+	if snapshot == nil {
+		return
+	}
+	modified, untracked, revert, err := snapshot.changes(path)
+	if err != nil {
+		result.Warnings = append(result.Warnings, "git status after the install failed: "+err.Error())
+		return
+	}
+	untracked = slices.DeleteFunc(untracked, func(file string) bool {
+		return slices.Contains(result.EnvFiles, file)
+	})
+
+	result.Modified, result.Untracked, result.revert = modified, untracked, revert
+	if len(modified) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("install modified tracked files: %s", strings.Join(modified, ", ")))
+	}
+	if len(untracked) > 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("install created files git does not ignore: %s", strings.Join(untracked, ", ")))
+	}
+}
@@ -0,0 +1,131 @@
+package install
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// setupWorktree returns a repository with a committed script, executable,
+// and README.
+func setupWorktree(t *testing.T) string {
+	t.Helper()
+	root := t.TempDir()
+	repo, err := git.PlainInit(root, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, root, "run.sh", "#!/bin/sh\necho run\n", 0o755)
+	writeFile(t, root, "README.md", "# demo\n", 0o644)
+	writeFile(t, root, "src/main.js", "console.log(1)\n", 0o644)
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.AddGlob("."); err != nil {
+		t.Fatal(err)
+	}
+	_, err = worktree.Commit("initial", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return root
+}
+
+func writeFile(t *testing.T, root, name, data string, mode os.FileMode) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(name))
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(data), mode); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(path, mode); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRevertChanges(t *testing.T) {
+	root := setupWorktree(t)
+	// Already changed before the install, and left alone by it
+	writeFile(t, root, "README.md", "# demo, edited\n", 0o644)
+
+	snapshot, err := snapshotWorktree(root)
+	if err != nil || snapshot == nil {
+		t.Fatalf("snapshotWorktree = %v, %v", snapshot, err)
+	}
+
+	// The install rewrites the executable script without its mode, creates
+	// a file and a new directory tree
+	writeFile(t, root, "run.sh", "#!/bin/sh\necho rewritten\n", 0o644)
+	writeFile(t, root, "notes.txt", "notes\n", 0o644)
+	writeFile(t, root, "generated/types/index.d.ts", "export {}\n", 0o644)
+	writeFile(t, root, "src/generated.js", "\n", 0o644)
+
+	var result InstallResult
+	recordChanges(&result, root, snapshot)
+	if want := []string{"run.sh"}; !slices.Equal(result.Modified, want) {
+		t.Errorf("Modified = %q, want %q", result.Modified, want)
+	}
+	if want := []string{"generated/types/index.d.ts", "notes.txt", "src/generated.js"}; !slices.Equal(result.Untracked, want) {
+		t.Errorf("Untracked = %q, want %q", result.Untracked, want)
+	}
+
+	if err := RevertChanges(root, result); err != nil {
+		t.Fatalf("RevertChanges: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(root, "run.sh"))
+	if err != nil || string(data) != "#!/bin/sh\necho run\n" {
+		t.Errorf("run.sh = %q, %v", data, err)
+	}
+	if info, err := os.Stat(filepath.Join(root, "run.sh")); err != nil || info.Mode().Perm() != 0o755 {
+		t.Errorf("run.sh mode = %v, %v, want 0755", info.Mode(), err)
+	}
+	for _, name := range []string{"notes.txt", "src/generated.js", "generated"} {
+		if _, err := os.Lstat(filepath.Join(root, filepath.FromSlash(name))); !os.IsNotExist(err) {
+			t.Errorf("%s is left: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(root, "src")); err != nil {
+		t.Errorf("src, tracked, was removed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "README.md")); string(data) != "# demo, edited\n" {
+		t.Errorf("README.md, changed before the install, = %q", data)
+	}
+}
+
+func TestRevertChangesChangedBefore(t *testing.T) {
+	root := setupWorktree(t)
+	writeFile(t, root, "README.md", "# demo, edited\n", 0o644)
+	snapshot, err := snapshotWorktree(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The install changes further a file already changed
+	writeFile(t, root, "README.md", "# demo, edited and installed\n", 0o644)
+	var result InstallResult
+	recordChanges(&result, root, snapshot)
+	if want := []string{"README.md"}; !slices.Equal(result.Modified, want) {
+		t.Fatalf("Modified = %q, want %q", result.Modified, want)
+	}
+
+	err = RevertChanges(root, result)
+	if err == nil || !strings.Contains(err.Error(), "README.md") {
+		t.Errorf("RevertChanges = %v, want README.md reported as left", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "README.md")); string(data) != "# demo, edited and installed\n" {
+		t.Errorf("README.md = %q, want it left as it is", data)
+	}
+}
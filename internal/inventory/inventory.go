@@ -0,0 +1,322 @@
+package inventory
+
+import (
+	"container/heap"
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// MaxDepth is how many directory levels below a root are searched, enough
+// for the root/owner/name layout.
+const MaxDepth = 3
+
+// maxWalk bounds the commits read while counting ahead and behind so a
+// huge divergence cannot stall the scan.
+const maxWalk = 5000
+
+// fetchMarker is touched inside .git after each successful fetch.
+const fetchMarker = "QUIKGIT_FETCHED"
+
+// skipDirs are never searched for repositories.
+var skipDirs = map[string]bool{
+	"node_modules": true,
+	"vendor":       true,
+}
+
+// Repository is a local clone and its git status.
+type Repository struct {
+	Name      string
+	Path      string
+	Root      string
+	Branch    string
+	Upstream  string
+	Ahead     int
+	Behind    int
+	Dirty     bool
+	LastFetch time.Time
+	RemoteURL string
+	Error     error
+}
+
+// Find returns the git repositories below roots, sorted by path.
+func Find(roots []string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+
+	var walk func(dir string, depth int)
+	walk = func(dir string, depth int) {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			if !seen[dir] {
+				seen[dir] = true
+				paths = append(paths, dir)
+			}
+			return
+		}
+		if depth >= MaxDepth {
+			return
+		}
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			name := entry.Name()
+			if !entry.IsDir() || strings.HasPrefix(name, ".") || skipDirs[name] {
+				continue
+			}
+			walk(filepath.Join(dir, name), depth+1)
+		}
+	}
+
+	for _, root := range roots {
+		walk(root, 0)
+	}
+
+	sort.Strings(paths)
+	return paths
+}
+
+// Scan finds the repositories below roots and reads their status, using
+// up to concurrent workers.
+func Scan(ctx context.Context, roots []string, concurrent int) []*Repository {
+	if concurrent <= 0 {
+		concurrent = 3
+	}
+
+	paths := Find(roots)
+	repos := make([]*Repository, len(paths))
+	semaphore := make(chan struct{}, concurrent)
+	var wg sync.WaitGroup
+
+	for i, path := range paths {
+		wg.Add(1)
+		go func(index int, repoPath string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			repo := Status(ctx, repoPath)
+			repo.Root = rootOf(roots, repoPath)
+			repos[index] = repo
+		}(i, path)
+	}
+
+	wg.Wait()
+	return repos
+}
+
+// Status reads the branch, upstream divergence, worktree state and last
+// fetch time of the repository at path.
+func Status(ctx context.Context, path string) *Repository {
+	repo := &Repository{
+		Name: filepath.Base(path),
+		Path: path,
+	}
+
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		repo.Error = err
+		return repo
+	}
+
+	if remote, err := r.Remote("origin"); err == nil && len(remote.Config().URLs) > 0 {
+		repo.RemoteURL = remote.Config().URLs[0]
+	}
+
+	repo.LastFetch = lastFetch(path)
+
+	head, err := r.Head()
+	if err != nil {
+		repo.Error = err
+		return repo
+	}
+
+	if head.Name().IsBranch() {
+		repo.Branch = head.Name().Short()
+	} else {
+		repo.Branch = "detached@" + head.Hash().String()[:7]
+	}
+
+	if upstream := upstreamRef(r, repo.Branch); upstream != "" {
+		if ref, err := r.Reference(upstream, true); err == nil {
+			repo.Upstream = upstream.Short()
+			repo.Ahead, repo.Behind = divergence(r, head.Hash(), ref.Hash())
+		}
+	}
+
+	if ctx.Err() != nil {
+		repo.Error = ctx.Err()
+		return repo
+	}
+
+	if worktree, err := r.Worktree(); err == nil {
+		if status, err := worktree.Status(); err == nil {
+			repo.Dirty = !status.IsClean()
+		}
+	}
+
+	return repo
+}
+
+// upstreamRef returns the remote-tracking ref configured for branch.
+func upstreamRef(r *git.Repository, branch string) plumbing.ReferenceName {
+	remote, merge, err := trackedBranch(r, branch)
+	if err != nil {
+		return ""
+	}
+	return plumbing.NewRemoteReferenceName(remote, merge.Short())
+}
+
+// trackedBranch returns the remote and the branch on it that branch is
+// configured to track. Fresh clones without branch config still track the
+// branch of the same name on origin.
+func trackedBranch(r *git.Repository, branch string) (string, plumbing.ReferenceName, error) {
+	cfg, err := r.Config()
+	if err != nil {
+		return "", "", err
+	}
+
+	remote, merge := "origin", plumbing.NewBranchReferenceName(branch)
+	if b, ok := cfg.Branches[branch]; ok {
+		if b.Remote != "" {
+			remote = b.Remote
+		}
+		if b.Merge != "" {
+			merge = b.Merge
+		}
+	}
+	return remote, merge, nil
+}
+
+// Sides of the divergence walk a commit is reachable from.
+const (
+	localSide uint8 = 1 << iota
+	upstreamSide
+	bothSides = localSide | upstreamSide
+)
+
+// divergence counts the commits only reachable from local (ahead) and only
+// reachable from upstream (behind), like git rev-list --left-right --count.
+// Both tips are walked together, newest commit first, marking each commit
+// with the sides that reach it; the walk stops once every queued commit is
+// reachable from both, so shared history below the merge bases is not
+// read.
+func divergence(r *git.Repository, local, upstream plumbing.Hash) (int, int) {
+	if local == upstream {
+		return 0, 0
+	}
+
+	localCommit, err := r.CommitObject(local)
+	if err != nil {
+		return 0, 0
+	}
+	upstreamCommit, err := r.CommitObject(upstream)
+	if err != nil {
+		return 0, 0
+	}
+
+	sides := map[plumbing.Hash]uint8{local: localSide, upstream: upstreamSide}
+	expanded := make(map[plumbing.Hash]uint8)
+	queue := &commitQueue{localCommit, upstreamCommit}
+	heap.Init(queue)
+
+	for walked := 0; walked < maxWalk && queue.pending(sides, expanded); {
+		commit := heap.Pop(queue).(*object.Commit)
+		side := sides[commit.Hash]
+		if previous, ok := expanded[commit.Hash]; ok && previous == side {
+			continue
+		}
+		expanded[commit.Hash] = side
+		walked++
+
+		_ = commit.Parents().ForEach(func(parent *object.Commit) error {
+			if sides[parent.Hash]|side != sides[parent.Hash] {
+				sides[parent.Hash] |= side
+				heap.Push(queue, parent)
+			}
+			return nil
+		})
+	}
+
+	ahead, behind := 0, 0
+	for _, side := range sides {
+		switch side {
+		case localSide:
+			ahead++
+		case upstreamSide:
+			behind++
+		}
+	}
+	return ahead, behind
+}
+
+// commitQueue is a heap of commits, newest committer time first.
+type commitQueue []*object.Commit
+
+func (q commitQueue) Len() int { return len(q) }
+func (q commitQueue) Less(i, j int) bool {
+	return q[i].Committer.When.After(q[j].Committer.When)
+}
+func (q commitQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+func (q *commitQueue) Push(x any)   { *q = append(*q, x.(*object.Commit)) }
+func (q *commitQueue) Pop() any {
+	old := *q
+	commit := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return commit
+}
+
+// pending reports whether a queued commit is reachable from one side only,
+// or was reached from another side after its parents were marked. Commit
+// times only order the walk roughly, so the latter must still be passed on.
+func (q commitQueue) pending(sides, expanded map[plumbing.Hash]uint8) bool {
+	for _, commit := range q {
+		side := sides[commit.Hash]
+		if previous, ok := expanded[commit.Hash]; side != bothSides || (ok && previous != side) {
+			return true
+		}
+	}
+	return false
+}
+
+// lastFetch is the newer of FETCH_HEAD, which git rewrites on every fetch,
+// and the marker left by fetches made here, since go-git does not write
+// FETCH_HEAD.
+func lastFetch(path string) time.Time {
+	var latest time.Time
+	for _, name := range []string{"FETCH_HEAD", fetchMarker} {
+		if info, err := os.Stat(filepath.Join(path, ".git", name)); err == nil && info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest
+}
+
+// markFetched records a successful fetch for lastFetch.
+func markFetched(path string) {
+	marker := filepath.Join(path, ".git", fetchMarker)
+	now := time.Now()
+	if err := os.Chtimes(marker, now, now); os.IsNotExist(err) {
+		os.WriteFile(marker, nil, 0644)
+	}
+}
+
+func rootOf(roots []string, path string) string {
+	for _, root := range roots {
+		if path == root || strings.HasPrefix(path, root+string(filepath.Separator)) {
+			return root
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package inventory
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+func TestDivergence(t *testing.T) {
+	r, err := git.PlainInit(filepath.Join(t.TempDir(), "repo"), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	worktree, _ := r.Worktree()
+	checkout := func(branch string, create bool) {
+		t.Helper()
+		if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName(branch), Create: create}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Shared history, then two commits only on master and three only on
+	// upstream
+	for _, name := range []string{"c1", "c2", "c3"} {
+		commitFile(t, r, name)
+	}
+	checkout("upstream", true)
+	checkout("master", false)
+	commitFile(t, r, "l1")
+	local := commitFile(t, r, "l2")
+	checkout("upstream", false)
+	u1 := commitFile(t, r, "u1")
+	commitFile(t, r, "u2")
+	upstream := commitFile(t, r, "u3")
+
+	if ahead, behind := divergence(r, local, upstream); ahead != 2 || behind != 3 {
+		t.Errorf("divergence = %d ahead, %d behind, want 2 ahead, 3 behind", ahead, behind)
+	}
+	if ahead, behind := divergence(r, upstream, upstream); ahead != 0 || behind != 0 {
+		t.Errorf("divergence of a commit with itself = %d, %d, want 0, 0", ahead, behind)
+	}
+
+	// Merging u1 into master shares it and its history
+	checkout("master", false)
+	merge, err := worktree.Commit("merge", &git.CommitOptions{
+		Author:            &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Parents:           []plumbing.Hash{local, u1},
+		AllowEmptyCommits: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ahead, behind := divergence(r, merge, upstream); ahead != 3 || behind != 2 {
+		t.Errorf("divergence after merge = %d ahead, %d behind, want 3 ahead, 2 behind", ahead, behind)
+	}
+}
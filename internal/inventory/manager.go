@@ -0,0 +1,194 @@
+package inventory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+
+	"github.com/lvcasx1/quikgit/internal/github"
+)
+
+// Action is a bulk operation run against local clones.
+type Action string
+
+const (
+	ActionFetch Action = "fetch"
+	ActionPull  Action = "pull"
+)
+
+// ActionProgress reports the outcome of an action on one repository.
+type ActionProgress struct {
+	Path      string
+	Action    Action
+	Status    string
+	Error     error
+	Completed bool
+}
+
+// Manager runs fetch and fast-forward pulls over many clones with a
+// bounded worker pool.
+type Manager struct {
+	token    string
+	sshKey   string
+	progress chan ActionProgress
+	wg       sync.WaitGroup
+}
+
+func NewManager(token, sshKey string) *Manager {
+	return &Manager{
+		token:    token,
+		sshKey:   sshKey,
+		progress: make(chan ActionProgress, 100),
+	}
+}
+
+func (m *Manager) GetProgressChannel() <-chan ActionProgress {
+	return m.progress
+}
+
+// Run starts action on every path, at most concurrent at a time.
+func (m *Manager) Run(ctx context.Context, action Action, paths []string, concurrent int) {
+	if concurrent <= 0 {
+		concurrent = 3
+	}
+
+	semaphore := make(chan struct{}, concurrent)
+
+	for _, path := range paths {
+		m.wg.Add(1)
+		go func(p string) {
+			semaphore <- struct{}{}
+			defer func() {
+				<-semaphore
+				m.wg.Done()
+			}()
+			m.worker(ctx, action, p)
+		}(path)
+	}
+}
+
+// Wait blocks until every started action finishes and closes the progress
+// channel.
+func (m *Manager) Wait() {
+	m.wg.Wait()
+	close(m.progress)
+}
+
+func (m *Manager) worker(ctx context.Context, action Action, path string) {
+	progress := ActionProgress{Path: path, Action: action, Status: "Running"}
+	m.send(ctx, progress)
+
+	var err error
+	switch action {
+	case ActionFetch:
+		err = m.fetch(ctx, path)
+	case ActionPull:
+		err = m.pull(ctx, path)
+	default:
+		err = fmt.Errorf("unknown action %q", action)
+	}
+
+	progress.Completed = true
+	switch {
+	case errors.Is(err, git.NoErrAlreadyUpToDate):
+		progress.Status = "Up to date"
+	case err != nil:
+		progress.Status = "Failed"
+		progress.Error = err
+	default:
+		progress.Status = "Done"
+	}
+
+	if err == nil || errors.Is(err, git.NoErrAlreadyUpToDate) {
+		markFetched(path)
+	}
+
+	// Completion is never dropped so callers can count finished repositories
+	m.send(ctx, progress)
+}
+
+// send blocks until the update is read or ctx is cancelled
+func (m *Manager) send(ctx context.Context, progress ActionProgress) {
+	select {
+	case m.progress <- progress:
+	case <-ctx.Done():
+	}
+}
+
+func (m *Manager) fetch(ctx context.Context, path string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	remote, err := r.Remote("origin")
+	if err != nil {
+		return err
+	}
+
+	return remote.FetchContext(ctx, &git.FetchOptions{
+		Auth: m.auth(remote),
+	})
+}
+
+// auth returns credentials for the first URL of remote
+func (m *Manager) auth(remote *git.Remote) transport.AuthMethod {
+	urls := remote.Config().URLs
+	if len(urls) == 0 {
+		return nil
+	}
+	return github.RemoteAuth(urls[0], m.token, m.sshKey)
+}
+
+// pull fast-forwards the current branch to its upstream; go-git refuses
+// anything else.
+func (m *Manager) pull(ctx context.Context, path string) error {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return err
+	}
+
+	head, err := r.Head()
+	if err != nil {
+		return err
+	}
+	if !head.Name().IsBranch() {
+		return fmt.Errorf("HEAD is detached; check out a branch to pull")
+	}
+
+	worktree, err := r.Worktree()
+	if err != nil {
+		return err
+	}
+
+	status, err := worktree.Status()
+	if err != nil {
+		return err
+	}
+	if !status.IsClean() {
+		return fmt.Errorf("working tree has local changes")
+	}
+
+	remoteName, merge, err := trackedBranch(r, head.Name().Short())
+	if err != nil {
+		return err
+	}
+	remote, err := r.Remote(remoteName)
+	if err != nil {
+		return err
+	}
+
+	err = worktree.PullContext(ctx, &git.PullOptions{
+		RemoteName:    remoteName,
+		ReferenceName: merge,
+		Auth:          m.auth(remote),
+	})
+	if errors.Is(err, git.ErrNonFastForwardUpdate) {
+		return fmt.Errorf("branch has diverged; cannot fast-forward")
+	}
+	return err
+}
@@ -0,0 +1,128 @@
+package inventory
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// commitFile writes name into the worktree of r and commits it.
+func commitFile(t *testing.T, r *git.Repository, name string) plumbing.Hash {
+	t.Helper()
+	worktree, err := r.Worktree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(worktree.Filesystem.Root(), name), []byte(name), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := worktree.Add(name); err != nil {
+		t.Fatal(err)
+	}
+	hash, err := worktree.Commit(name, &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return hash
+}
+
+// setupPull returns an origin with main and feature branches, and a clone
+// with feature checked out and tracking origin/feature. Both branches move
+// on origin after the clone, and origin's HEAD is left on master.
+func setupPull(t *testing.T) (origin *git.Repository, clone *git.Repository, clonePath string) {
+	t.Helper()
+	originPath := filepath.Join(t.TempDir(), "origin")
+	origin, err := git.PlainInit(originPath, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, origin, "a")
+
+	worktree, _ := origin.Worktree()
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}); err != nil {
+		t.Fatal(err)
+	}
+
+	clonePath = filepath.Join(t.TempDir(), "clone")
+	clone, err = git.PlainClone(clonePath, false, &git.CloneOptions{URL: originPath})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tracking, err := clone.Reference(plumbing.NewRemoteReferenceName("origin", "feature"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cloneTree, _ := clone.Worktree()
+	if err := cloneTree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature"), Hash: tracking.Hash(), Create: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := clone.CreateBranch(&config.Branch{
+		Name:   "feature",
+		Remote: "origin",
+		Merge:  plumbing.NewBranchReferenceName("feature"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.NewBranchReferenceName("feature")}); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, origin, "feature-only")
+	if err := worktree.Checkout(&git.CheckoutOptions{Branch: plumbing.Master}); err != nil {
+		t.Fatal(err)
+	}
+	commitFile(t, origin, "master-only")
+	return origin, clone, clonePath
+}
+
+func TestPullUsesUpstream(t *testing.T) {
+	origin, clone, clonePath := setupPull(t)
+
+	if err := NewManager("", "").pull(context.Background(), clonePath); err != nil {
+		t.Fatalf("pull: %v", err)
+	}
+
+	want, err := origin.Reference(plumbing.NewBranchReferenceName("feature"), true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	head, err := clone.Head()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Name() != plumbing.NewBranchReferenceName("feature") {
+		t.Errorf("HEAD = %s, want refs/heads/feature", head.Name())
+	}
+	if head.Hash() != want.Hash() {
+		t.Errorf("feature = %s, want origin's feature %s", head.Hash(), want.Hash())
+	}
+}
+
+func TestPullDetachedHead(t *testing.T) {
+	_, clone, clonePath := setupPull(t)
+
+	head, _ := clone.Head()
+	worktree, _ := clone.Worktree()
+	if err := worktree.Checkout(&git.CheckoutOptions{Hash: head.Hash()}); err != nil {
+		t.Fatal(err)
+	}
+
+	err := NewManager("", "").pull(context.Background(), clonePath)
+	if err == nil || !strings.Contains(err.Error(), "detached") {
+		t.Errorf("pull on a detached HEAD = %v, want a detached HEAD error", err)
+	}
+}
@@ -0,0 +1,242 @@
+// Package trust decides whether a cloned repository's install commands may
+// run its scripts: repositories of the authenticated user, their
+// organizations and the allowlist are trusted, others need confirmation.
+package trust
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/go-git/go-git/v5"
+	"gopkg.in/yaml.v3"
+
+	"github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// FileName is the allowlist file kept beside the configuration file.
+const FileName = "trusted.yaml"
+
+// Allowlist holds the owners ("acme") and repositories ("acme/tool")
+// trusted in addition to the user's own.
+type Allowlist struct {
+	path    string
+	mu      sync.Mutex
+	entries []string
+}
+
+type allowlistFile struct {
+	Trusted []string `yaml:"trusted"`
+}
+
+// LoadAllowlist reads the allowlist beside the configuration file. A missing
+// file is an empty allowlist.
+func LoadAllowlist(cfg *config.Config) (*Allowlist, error) {
+	dir := filepath.Dir(cfg.ConfigPath)
+	if cfg.ConfigPath == "" {
+		var err error
+		if dir, err = config.GetConfigDir(); err != nil {
+			return nil, err
+		}
+	}
+
+	allowlist := &Allowlist{path: filepath.Join(dir, FileName)}
+	data, err := os.ReadFile(allowlist.path)
+	if os.IsNotExist(err) {
+		return allowlist, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read allowlist: %w", err)
+	}
+
+	var file allowlistFile
+	if err := yaml.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid allowlist %s: %w", allowlist.path, err)
+	}
+	for _, entry := range file.Trusted {
+		if entry = normalize(entry); entry != "" && !slices.Contains(allowlist.entries, entry) {
+			allowlist.entries = append(allowlist.entries, entry)
+		}
+	}
+	return allowlist, nil
+}
+
+// Path returns the location of the allowlist file.
+func (a *Allowlist) Path() string {
+	return a.path
+}
+
+// Entries returns the trusted owners and repositories, in the order added.
+func (a *Allowlist) Entries() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Clone(a.entries)
+}
+
+// Contains reports whether fullName ("owner/repo") or its owner is listed.
+func (a *Allowlist) Contains(fullName string) bool {
+	fullName = normalize(fullName)
+	owner, _, _ := strings.Cut(fullName, "/")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return slices.Contains(a.entries, fullName) || slices.Contains(a.entries, owner)
+}
+
+// Add lists an owner or repository and saves the allowlist.
+func (a *Allowlist) Add(entry string) error {
+	entry = normalize(entry)
+	if entry == "" || strings.Count(entry, "/") > 1 {
+		return fmt.Errorf("invalid entry %q, expected OWNER or OWNER/REPO", entry)
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if slices.Contains(a.entries, entry) {
+		return nil
+	}
+	a.entries = append(a.entries, entry)
+	return a.save()
+}
+
+// Remove unlists an owner or repository and saves the allowlist. It reports
+// whether the entry was listed.
+func (a *Allowlist) Remove(entry string) (bool, error) {
+	entry = normalize(entry)
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	index := slices.Index(a.entries, entry)
+	if index < 0 {
+		return false, nil
+	}
+	a.entries = slices.Delete(a.entries, index, index+1)
+	return true, a.save()
+}
+
+func (a *Allowlist) save() error {
+	data, err := yaml.Marshal(allowlistFile{Trusted: a.entries})
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(a.path), 0755); err != nil {
+		return err
+	}
+	header := []byte("# Repositories whose install scripts QuikGit runs without asking\n")
+	return os.WriteFile(a.path, append(header, data...), 0644)
+}
+
+// GitHub names are case-insensitive
+func normalize(name string) string {
+	return strings.ToLower(strings.Trim(strings.TrimSpace(name), "/"))
+}
+
+// Checker decides whether repositories are trusted.
+type Checker struct {
+	owners    []string
+	allowlist *Allowlist
+}
+
+// NewChecker trusts repositories of owners, the authenticated user and
+// their organizations, and those on the allowlist, which may be nil.
+func NewChecker(owners []string, allowlist *Allowlist) *Checker {
+	checker := &Checker{allowlist: allowlist}
+	for _, owner := range owners {
+		checker.owners = append(checker.owners, normalize(owner))
+	}
+	return checker
+}
+
+// Allowlist returns the checker's allowlist, or nil.
+func (c *Checker) Allowlist() *Allowlist {
+	return c.allowlist
+}
+
+// Check reports whether the repository fullName ("owner/repo") is trusted,
+// and why.
+func (c *Checker) Check(fullName string) (trusted bool, reason string) {
+	if fullName == "" {
+		return false, "no GitHub origin remote"
+	}
+
+	owner, _, _ := strings.Cut(normalize(fullName), "/")
+	switch {
+	case slices.Contains(c.owners, owner):
+		return true, "owned by you or your organization"
+	case c.allowlist != nil && c.allowlist.Contains(fullName):
+		return true, "on your allowlist"
+	default:
+		return false, owner + " is not you, your organization or on your allowlist"
+	}
+}
+
+// Owners returns the authenticated user's login and organizations.
+func Owners(ctx context.Context, client *github.Client) ([]string, error) {
+	user, err := client.GetAuthenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owners := []string{user.GetLogin()}
+
+	orgs, err := client.GetUserOrganizations(ctx)
+	if err != nil {
+		return owners, err
+	}
+	for _, org := range orgs {
+		owners = append(owners, org.GetLogin())
+	}
+	return owners, nil
+}
+
+// RepositoryName returns "owner/repo" of the repository at path, read from
+// its GitHub origin remote, or "" when it has none.
+func RepositoryName(path string) string {
+	r, err := git.PlainOpen(path)
+	if err != nil {
+		return ""
+	}
+	remote, err := r.Remote("origin")
+	if err != nil || len(remote.Config().URLs) == 0 {
+		return ""
+	}
+	return remoteName(remote.Config().URLs[0])
+}
+
+// remoteName parses https://github.com/owner/repo(.git),
+// git@github.com:owner/repo(.git) and ssh://git@github.com/owner/repo. Any
+// other host, including lookalikes such as notgithub.com, yields "".
+func remoteName(remoteURL string) string {
+	var host, path string
+	if strings.Contains(remoteURL, "://") {
+		u, err := url.Parse(remoteURL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "ssh") {
+			return ""
+		}
+		host, path = u.Hostname(), u.Path
+	} else {
+		// scp-like syntax: [user@]host:owner/repo
+		address, rest, found := strings.Cut(remoteURL, ":")
+		if !found || strings.Contains(address, "/") {
+			return ""
+		}
+		if _, h, ok := strings.Cut(address, "@"); ok {
+			address = h
+		}
+		host, path = address, rest
+	}
+	if !strings.EqualFold(host, "github.com") {
+		return ""
+	}
+
+	parts := strings.Split(strings.TrimSuffix(strings.Trim(path, "/"), ".git"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
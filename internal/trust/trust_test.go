@@ -0,0 +1,35 @@
+package trust
+
+import "testing"
+
+func TestRemoteName(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://github.com/acme/tool", "acme/tool"},
+		{"https://github.com/acme/tool.git", "acme/tool"},
+		{"https://GitHub.com/acme/tool/", "acme/tool"},
+		{"git@github.com:acme/tool.git", "acme/tool"},
+		{"ssh://git@github.com/acme/tool.git", "acme/tool"},
+		{"ssh://git@github.com:22/acme/tool", "acme/tool"},
+
+		// Only github.com itself is GitHub
+		{"https://notgithub.com/acme/tool", ""},
+		{"https://evil.example/github.com/acme/tool", ""},
+		{"https://github.com.evil.example/acme/tool", ""},
+		{"git@evil.example:github.com/acme/tool", ""},
+		{"git@notgithub.com:acme/tool.git", ""},
+		{"http://github.com/acme/tool", ""},
+		{"/srv/git/github.com/acme/tool", ""},
+
+		{"https://github.com/acme", ""},
+		{"https://github.com/acme/tool/extra", ""},
+	}
+
+	for _, tt := range tests {
+		if got := remoteName(tt.url); got != tt.want {
+			t.Errorf("remoteName(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package bubbletea
 
 import (
 	"context"
+	"log"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -9,6 +10,7 @@ import (
 
 	"github.com/lvcasx1/quikgit/internal/auth"
 	ghClient "github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/internal/history"
 	"github.com/lvcasx1/quikgit/pkg/config"
 )
 
@@ -26,6 +28,9 @@ const (
 	StateCloning
 	StateInstalling
 	StateQuickClone
+	StateWorkspaceSync
+	StateHistory
+	StateMyWorkspace
 )
 
 // SearchSession holds search filter state that persists during the session
@@ -62,6 +67,10 @@ type Application struct {
 	selectedIndices map[int]bool
 	clonedPaths     []string // Paths of successfully cloned repositories
 
+	// Run history (nil if the history file is unavailable)
+	history    *history.Store
+	currentRun *history.Run // Run being recorded by the clone/install screens
+
 	// Session state for search filters (preserved during session)
 	searchSession *SearchSession
 
@@ -109,6 +118,10 @@ func NewApplication(cfg *config.Config) *Application {
 		app.isAuthenticated = false
 	}
 
+	if store, err := history.NewStore(); err == nil {
+		app.history = store
+	}
+
 	// Always start with splash screen regardless of authentication status
 	app.currentView = NewSplashModel(app)
 
@@ -233,6 +246,12 @@ func (a *Application) handleStateChange(msg StateChangeMsg) (tea.Model, tea.Cmd)
 		a.currentView = NewInstallationModel(a)
 	case StateQuickClone:
 		a.currentView = NewQuickCloneModel(a)
+	case StateWorkspaceSync:
+		a.currentView = NewWorkspaceSyncModel(a)
+	case StateHistory:
+		a.currentView = NewHistoryModel(a)
+	case StateMyWorkspace:
+		a.currentView = NewMyWorkspaceModel(a)
 	}
 
 	if a.currentView != nil {
@@ -274,7 +293,7 @@ func (a *Application) NavigateTo(state AppState) tea.Cmd {
 // requiresAuthentication checks if a state requires a valid GitHub token
 func (a *Application) requiresAuthentication(state AppState) bool {
 	switch state {
-	case StateSplash, StateFirstStartup, StateAuth, StateAuthRequired:
+	case StateSplash, StateFirstStartup, StateAuth, StateAuthRequired, StateHistory, StateMyWorkspace:
 		return false // These states don't require authentication
 	default:
 		return true // All other states require valid authentication
@@ -298,6 +317,16 @@ func (a *Application) isTokenValid() bool {
 	return true
 }
 
+// saveRun writes run to the history file, if there is one.
+func (a *Application) saveRun(run *history.Run) {
+	if a.history == nil || run == nil {
+		return
+	}
+	if err := a.history.Save(run); err != nil {
+		log.Printf("failed to save history: %v", err)
+	}
+}
+
 // UpdateAuthStatus notifies the app when authentication status changes
 func (a *Application) UpdateAuthStatus(authenticated bool) tea.Cmd {
 	return func() tea.Msg {
@@ -341,6 +370,10 @@ var (
 			Foreground(lipgloss.Color("39")).
 			Bold(true)
 
+	WarningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Bold(true)
+
 	// Progress styles
 	ProgressBarStyle = lipgloss.NewStyle().
 				Width(50).
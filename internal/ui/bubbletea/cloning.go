@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
 	"time"
 
@@ -14,6 +13,7 @@ import (
 
 	"github.com/lvcasx1/quikgit/internal/github"
 	ghClient "github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/internal/history"
 )
 
 type CloningModel struct {
@@ -43,6 +43,11 @@ type CloningModel struct {
 	minDuration       time.Duration
 	animationTickers  map[string]*time.Ticker
 	animationProgress map[string]float64
+
+	// History recording
+	run         *history.Run
+	runEntries  map[string]*history.Entry
+	cloneStarts map[string]time.Time
 }
 
 func NewCloningModel(app *Application) *CloningModel {
@@ -63,6 +68,8 @@ func NewCloningModel(app *Application) *CloningModel {
 		minDuration:       2 * time.Second, // Minimum 2 seconds for rich experience
 		animationTickers:  make(map[string]*time.Ticker),
 		animationProgress: make(map[string]float64),
+		runEntries:        make(map[string]*history.Entry),
+		cloneStarts:       make(map[string]time.Time),
 	}
 
 	// Initialize progress bars for each repository
@@ -91,16 +98,20 @@ func (m *CloningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "ctrl+c":
 			if !m.allCompleted {
 				m.cancel()
+				m.finishRun()
 				return m, m.app.NavigateTo(StateMainMenu)
 			}
 		case "enter":
 			if m.allCompleted {
-				if m.successCount > 0 {
+				if m.successCount > 0 && m.app.config.Install.Enabled {
 					// Set successful paths for installation
 					m.app.clonedPaths = m.getSuccessfullyClonedPaths()
 					m.app.message = fmt.Sprintf("Successfully cloned %d repositories", m.successCount)
 					return m, m.app.NavigateTo(StateInstalling)
 				} else {
+					if m.successCount > 0 {
+						m.app.message = fmt.Sprintf("Successfully cloned %d repositories", m.successCount)
+					}
 					return m, m.app.NavigateTo(StateMainMenu)
 				}
 			}
@@ -115,12 +126,15 @@ func (m *CloningModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case CloneCompleteMsg:
 		m.allCompleted = true
+		m.finishRun()
 		return m, nil
 
 	case CloneStartMsg:
 		if !m.started {
 			m.started = true
 			m.startTime = time.Now() // Record start time for minimum duration
+			// The install screen records its results in the same run
+			m.app.currentRun = m.run
 			return m, tea.Batch(m.monitorProgress(), m.startAnimationTickers())
 		}
 		// Ignore duplicate start messages
@@ -317,7 +331,12 @@ func (m *CloningModel) renderSummary() string {
 		summaryParts = append(summaryParts, summaryStyle.Render(summary))
 
 		// Instructions
-		if m.successCount > 0 {
+		if m.successCount > 0 && !m.app.config.Install.Enabled {
+			instructionStyle := SuccessStyle.Copy().
+				Align(lipgloss.Center).
+				MarginTop(1)
+			summaryParts = append(summaryParts, instructionStyle.Render("󰄬 Dependency installation disabled. Press Enter to continue."))
+		} else if m.successCount > 0 {
 			instructionStyle := SuccessStyle.Copy().
 				Align(lipgloss.Center).
 				MarginTop(1)
@@ -408,6 +427,19 @@ func (m *CloningModel) startCloningProcess() tea.Cmd {
 		// Create clone manager
 		m.cloneManager = github.NewCloneManager(token, targetDir)
 		m.cloneManager.SetCreateSubdirs(createSubdirs)
+		m.cloneManager.SetPathTemplate(m.app.config.Clone.PathTemplate)
+
+		// Start recording this run for the history screen
+		m.run = history.NewRun(history.ActionClone, targetDir)
+		for _, repo := range m.repositories {
+			entry := &history.Entry{
+				FullName:   repo.FullName,
+				Repository: repo,
+				Path:       m.cloneManager.TargetPath(repo),
+			}
+			m.run.Entries = append(m.run.Entries, entry)
+			m.runEntries[repo.FullName] = entry
+		}
 
 		return CloneStartMsg{}
 	}
@@ -418,7 +450,7 @@ func (m *CloningModel) monitorProgress() tea.Cmd {
 		// Start the actual cloning in a separate goroutine only once
 		if m.cloneManager != nil && !m.cloneStarted {
 			m.cloneStarted = true
-			go m.cloneManager.CloneRepositories(m.ctx, m.repositories, 3)
+			go m.cloneManager.CloneRepositories(m.ctx, m.repositories, m.app.config.Clone.Concurrent)
 		}
 
 		// Get the progress channel
@@ -436,6 +468,7 @@ func (m *CloningModel) monitorProgress() tea.Cmd {
 				return CloneProgressMsg{
 					Repository: progress.Repository,
 					Status:     progress.Status,
+					Transport:  progress.Transport,
 					Progress:   progress.Progress,
 					Error:      progress.Error,
 					Completed:  progress.Completed,
@@ -462,6 +495,13 @@ func (m *CloningModel) handleProgressUpdate(msg CloneProgressMsg) (tea.Model, te
 	// Update status
 	if msg.Repository != "system" {
 		m.statuses[msg.Repository] = msg.Status
+		if _, ok := m.cloneStarts[msg.Repository]; !ok {
+			m.cloneStarts[msg.Repository] = time.Now()
+		}
+	}
+
+	if msg.Completed {
+		m.recordClone(msg)
 	}
 
 	// Handle completion
@@ -519,19 +559,44 @@ func (m *CloningModel) getSuccessfullyClonedPaths() []string {
 
 	for _, repo := range m.repositories {
 		if m.completed[repo.FullName] && m.errors[repo.FullName] == nil {
-			var repoPath string
-			if m.app.config.Clone.CreateSubdirs {
-				repoPath = filepath.Join(m.targetDir, repo.Owner, repo.Name)
-			} else {
-				repoPath = filepath.Join(m.targetDir, repo.Name)
-			}
-			paths = append(paths, repoPath)
+			paths = append(paths, m.cloneManager.TargetPath(repo))
 		}
 	}
 
 	return paths
 }
 
+// recordClone stores the outcome of one repository in the run history
+func (m *CloningModel) recordClone(msg CloneProgressMsg) {
+	entry, ok := m.runEntries[msg.Repository]
+	if !ok {
+		return
+	}
+
+	if start, ok := m.cloneStarts[msg.Repository]; ok {
+		entry.CloneDuration = time.Since(start)
+	}
+	if msg.Transport != "" {
+		entry.Transport = msg.Transport
+	}
+	if msg.Error != nil {
+		if strings.Contains(msg.Error.Error(), "already exists") {
+			entry.Skipped = true
+		} else {
+			entry.CloneError = msg.Error.Error()
+		}
+	}
+}
+
+// finishRun saves the clone run once cloning is over
+func (m *CloningModel) finishRun() {
+	if m.run == nil || !m.run.FinishedAt.IsZero() {
+		return
+	}
+	m.run.FinishedAt = time.Now()
+	m.app.saveRun(m.run)
+}
+
 // startAnimationTickers starts smooth animation tickers for each repository
 func (m *CloningModel) startAnimationTickers() tea.Cmd {
 	var cmds []tea.Cmd
@@ -645,6 +710,7 @@ type CloneStartMsg struct{}
 type CloneProgressMsg struct {
 	Repository string
 	Status     string
+	Transport  string
 	Progress   float64
 	Error      error
 	Completed  bool
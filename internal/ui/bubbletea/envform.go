@@ -0,0 +1,221 @@
+package bubbletea
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/lvcasx1/quikgit/internal/install"
+)
+
+// secretKeyWords mark variables whose values are hidden while typed
+var secretKeyWords = []string{"SECRET", "PASS", "TOKEN", "KEY"}
+
+// envFormFile is an environment file of an install plan to fill in
+type envFormFile struct {
+	repository string
+	file       *install.EnvFile
+}
+
+// EnvFormModel asks for the variables of the environment files an install
+// creates, one file at a time, with the templates' defaults prefilled. It
+// opens on top of the installation screen once the plan is confirmed.
+type EnvFormModel struct {
+	app    *Application
+	files  []envFormFile
+	file   int // Index into files
+	field  int // Index into inputs
+	inputs []textinput.Model
+	done   bool
+}
+
+// NewEnvFormModel asks for the files of plans with variables left unset;
+// the others are created as they are.
+func NewEnvFormModel(app *Application, plans []*install.Plan) *EnvFormModel {
+	m := &EnvFormModel{app: app}
+	for _, plan := range plans {
+		if plan.Error != nil {
+			continue
+		}
+		for i := range plan.EnvFiles {
+			file := &plan.EnvFiles[i]
+			if !file.Skip && len(file.Unset()) > 0 {
+				m.files = append(m.files, envFormFile{repository: plan.Repository, file: file})
+			}
+		}
+	}
+	m.openFile()
+	return m
+}
+
+func (m *EnvFormModel) Init() tea.Cmd {
+	return textinput.Blink
+}
+
+// Done reports whether every file was filled in or skipped.
+func (m *EnvFormModel) Done() bool {
+	return m.done
+}
+
+// openFile creates the inputs of the current file, focused on its first
+// variable without a value
+func (m *EnvFormModel) openFile() {
+	if m.file >= len(m.files) {
+		m.done = true
+		return
+	}
+
+	file := m.files[m.file].file
+	m.inputs = make([]textinput.Model, len(file.Vars))
+	m.field = -1
+	for i, v := range file.Vars {
+		input := textinput.New()
+		input.Placeholder = "value"
+		input.CharLimit = 1000
+		input.Width = 50
+		input.SetValue(file.Value(v))
+		if isSecretKey(v.Key) {
+			input.EchoMode = textinput.EchoPassword
+			input.EchoCharacter = '*'
+		}
+		m.inputs[i] = input
+		if m.field < 0 && input.Value() == "" {
+			m.field = i
+		}
+	}
+	m.field = max(m.field, 0)
+	m.inputs[m.field].Focus()
+}
+
+// isSecretKey reports whether a variable's name suggests it holds a secret
+func isSecretKey(key string) bool {
+	key = strings.ToUpper(key)
+	for _, word := range secretKeyWords {
+		if strings.Contains(key, word) {
+			return true
+		}
+	}
+	return false
+}
+
+// focus moves the cursor to the input at index
+func (m *EnvFormModel) focus(index int) {
+	m.inputs[m.field].Blur()
+	m.field = index
+	m.inputs[m.field].Focus()
+}
+
+// nextFile keeps the values of the current file, unless it is skipped, and
+// moves on to the next one
+func (m *EnvFormModel) nextFile(skip bool) {
+	file := m.files[m.file].file
+	if skip {
+		file.Skip = true
+	} else {
+		for i, v := range file.Vars {
+			file.Values[v.Key] = m.inputs[i].Value()
+		}
+	}
+	m.file++
+	m.openFile()
+}
+
+// Update handles the form's messages; the screen showing it returns the
+// command.
+func (m *EnvFormModel) Update(msg tea.Msg) tea.Cmd {
+	if m.done {
+		return nil
+	}
+
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "up", "shift+tab":
+			if m.field > 0 {
+				m.focus(m.field - 1)
+			}
+			return nil
+		case "down", "tab":
+			if m.field < len(m.inputs)-1 {
+				m.focus(m.field + 1)
+			}
+			return nil
+		case "enter":
+			if m.field < len(m.inputs)-1 {
+				m.focus(m.field + 1)
+				return nil
+			}
+			m.nextFile(false)
+			return textinput.Blink
+		case "ctrl+s":
+			m.nextFile(false)
+			return textinput.Blink
+		case "esc":
+			m.nextFile(true)
+			return textinput.Blink
+		}
+	}
+
+	var cmd tea.Cmd
+	m.inputs[m.field], cmd = m.inputs[m.field].Update(msg)
+	return cmd
+}
+
+func (m *EnvFormModel) View() string {
+	width := m.app.width
+	height := m.app.height - 3
+	if width == 0 {
+		width = 120
+	}
+	if height <= 0 {
+		height = 30
+	}
+	if m.done {
+		return ""
+	}
+
+	current := m.files[m.file]
+	file := current.file
+	title := fmt.Sprintf("󰈙 %s: %s (%d/%d)", current.repository, file.TargetPath(), m.file+1, len(m.files))
+
+	keyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86")).Bold(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+	boxStyle := lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(width - 20)
+
+	// Each variable takes two or three lines; keep the focused one in view
+	rows := max((height-12)/3, 1)
+	first := max(min(m.field-rows/2, len(file.Vars)-rows), 0)
+
+	lines := []string{dimStyle.Render(fmt.Sprintf("Created from %s; empty values stay empty", file.Name)), ""}
+	for i := first; i < min(first+rows, len(file.Vars)); i++ {
+		v := file.Vars[i]
+		label := keyStyle.Render(v.Key)
+		if v.Value == "" {
+			label += WarningStyle.Render(" (no default)")
+		}
+		lines = append(lines, label)
+		if v.Comment != "" {
+			lines = append(lines, dimStyle.Render("  "+v.Comment))
+		}
+		lines = append(lines, "  "+m.inputs[i].View())
+	}
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true).
+		Align(lipgloss.Center).
+		MarginTop(1)
+
+	content := lipgloss.JoinVertical(lipgloss.Center,
+		TitleStyle.Width(width-20).Render(title),
+		boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		helpStyle.Render("Enter next • ↑/↓ to move • Ctrl+S save file • Esc don't create this file"),
+	)
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center, content)
+}
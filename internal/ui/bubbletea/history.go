@@ -0,0 +1,526 @@
+package bubbletea
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/lvcasx1/quikgit/internal/history"
+)
+
+// historyStatusFilters are cycled with Tab on the history screen
+var historyStatusFilters = []string{"All", "Failed", "Succeeded"}
+
+// HistoryModel lists past clone and install runs and can repeat them.
+type HistoryModel struct {
+	app     *Application
+	runs    []*history.Run
+	loaded  bool
+	err     error
+	message string
+
+	// List state
+	cursor       int
+	filterInput  textinput.Model
+	filtering    bool
+	statusFilter int
+
+	// Detail state
+	detail      *history.Run
+	entryCursor int
+	logViewer   *LogViewerModel
+}
+
+// HistoryLoadedMsg carries the runs read from the history file
+type HistoryLoadedMsg struct {
+	Runs  []*history.Run
+	Error error
+}
+
+func NewHistoryModel(app *Application) *HistoryModel {
+	filterInput := textinput.New()
+	filterInput.Placeholder = "Filter by repository"
+	filterInput.CharLimit = 100
+	filterInput.Width = 40
+
+	return &HistoryModel{
+		app:         app,
+		filterInput: filterInput,
+	}
+}
+
+func (m *HistoryModel) Init() tea.Cmd {
+	return func() tea.Msg {
+		if m.app.history == nil {
+			return HistoryLoadedMsg{Error: fmt.Errorf("history is unavailable")}
+		}
+		runs, err := m.app.history.List()
+		return HistoryLoadedMsg{Runs: runs, Error: err}
+	}
+}
+
+func (m *HistoryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.logViewer != nil {
+		switch msg.(type) {
+		case tea.KeyMsg, logLoadedMsg, logFollowMsg:
+			cmd := m.logViewer.Update(msg)
+			if m.logViewer.Closed() {
+				m.logViewer = nil
+			}
+			return m, cmd
+		}
+	}
+
+	switch msg := msg.(type) {
+	case HistoryLoadedMsg:
+		m.loaded = true
+		m.runs = msg.Runs
+		m.err = msg.Error
+		return m, nil
+
+	case tea.KeyMsg:
+		m.message = ""
+		if m.filtering {
+			return m.updateFilter(msg)
+		}
+		if m.detail != nil {
+			return m.updateDetail(msg)
+		}
+		return m.updateList(msg)
+	}
+
+	return m, nil
+}
+
+func (m *HistoryModel) updateFilter(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.filterInput.SetValue("")
+		fallthrough
+	case "enter":
+		m.filtering = false
+		m.filterInput.Blur()
+		m.cursor = 0
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.filterInput, cmd = m.filterInput.Update(msg)
+	m.cursor = 0
+	return m, cmd
+}
+
+func (m *HistoryModel) updateList(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	runs := m.visibleRuns()
+
+	switch msg.String() {
+	case "esc":
+		return m, m.app.NavigateTo(StateMainMenu)
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(runs)-1 {
+			m.cursor++
+		}
+	case "/":
+		m.filtering = true
+		return m, m.filterInput.Focus()
+	case "tab":
+		m.statusFilter = (m.statusFilter + 1) % len(historyStatusFilters)
+		m.cursor = 0
+	case "enter":
+		if m.cursor < len(runs) {
+			m.detail = runs[m.cursor]
+			m.entryCursor = 0
+		}
+	case "r":
+		if m.cursor < len(runs) {
+			return m.rerun(runs[m.cursor])
+		}
+	}
+
+	return m, nil
+}
+
+func (m *HistoryModel) updateDetail(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	entries := m.detail.Entries
+
+	switch msg.String() {
+	case "esc":
+		m.detail = nil
+	case "up", "k":
+		if m.entryCursor > 0 {
+			m.entryCursor--
+		}
+	case "down", "j":
+		if m.entryCursor < len(entries)-1 {
+			m.entryCursor++
+		}
+	case "r":
+		return m.rerun(m.detail)
+	case "i":
+		if m.entryCursor < len(entries) {
+			return m.reinstall(entries[m.entryCursor])
+		}
+	case "o":
+		if m.entryCursor < len(entries) {
+			entry := entries[m.entryCursor]
+			if !entry.Exists() {
+				m.message = "Clone no longer exists at " + entry.Path
+			} else if err := openExternal(entry.Path); err != nil {
+				m.message = "Failed to open: " + err.Error()
+			}
+		}
+	case "l":
+		if m.entryCursor < len(entries) {
+			return m.openLog(entries[m.entryCursor])
+		}
+	}
+
+	return m, nil
+}
+
+// openLog shows the log of the entry's failed install command, or of its
+// last command when none failed
+func (m *HistoryModel) openLog(entry *history.Entry) (tea.Model, tea.Cmd) {
+	if entry.Install == nil {
+		m.message = "Nothing was installed for " + entry.FullName
+		return m, nil
+	}
+
+	var logged *history.CommandRecord
+	for i := range entry.Install.Commands {
+		cmd := &entry.Install.Commands[i]
+		if cmd.LogFile == "" {
+			continue
+		}
+		logged = cmd
+		if !cmd.Success {
+			break
+		}
+	}
+	if logged == nil {
+		m.message = "No command logs were kept for " + entry.FullName
+		return m, nil
+	}
+	if _, err := os.Stat(logged.LogFile); err != nil {
+		m.message = "Log no longer exists at " + logged.LogFile
+		return m, nil
+	}
+
+	title := fmt.Sprintf("%s: %s", entry.FullName, logged.Command)
+	m.logViewer = NewLogViewerModel(m.app, title, logged.LogFile, false)
+	return m, m.logViewer.Init()
+}
+
+// rerun clones the repositories of run again; existing clones are skipped
+func (m *HistoryModel) rerun(run *history.Run) (tea.Model, tea.Cmd) {
+	repos := run.Repositories()
+	if len(repos) == 0 {
+		m.message = "This run has no repositories to clone"
+		return m, nil
+	}
+
+	m.app.selectedRepos = repos
+	return m, m.app.NavigateTo(StateCloning)
+}
+
+// reinstall installs the dependencies of a past clone again
+func (m *HistoryModel) reinstall(entry *history.Entry) (tea.Model, tea.Cmd) {
+	if !entry.Exists() {
+		m.message = "Clone no longer exists at " + entry.Path
+		return m, nil
+	}
+
+	run := history.NewRun(history.ActionInstall, m.detail.TargetDir)
+	run.Entries = []*history.Entry{{
+		FullName:   entry.FullName,
+		Repository: entry.Repository,
+		Path:       entry.Path,
+	}}
+	m.app.currentRun = run
+	m.app.clonedPaths = []string{entry.Path}
+	return m, m.app.NavigateTo(StateInstalling)
+}
+
+// visibleRuns applies the text and status filters
+func (m *HistoryModel) visibleRuns() []*history.Run {
+	query := strings.TrimSpace(m.filterInput.Value())
+
+	var runs []*history.Run
+	for _, run := range m.runs {
+		if !run.Matches(query) {
+			continue
+		}
+		switch historyStatusFilters[m.statusFilter] {
+		case "Failed":
+			if run.Failures() == 0 {
+				continue
+			}
+		case "Succeeded":
+			if run.Failures() > 0 {
+				continue
+			}
+		}
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+func (m *HistoryModel) View() string {
+	// Use full screen dimensions with fallback
+	width := m.app.width
+	height := m.app.height - 3
+	if width == 0 {
+		width = 120
+	}
+	if height <= 0 {
+		height = 30
+	}
+
+	if m.logViewer != nil {
+		return m.logViewer.View()
+	}
+
+	var sections []string
+
+	title := "󰋚 History"
+	if m.detail != nil {
+		title = fmt.Sprintf("󰋚 History: %s run, %s", m.detail.Action, m.detail.StartedAt.Format("2006-01-02 15:04"))
+	}
+	sections = append(sections, TitleStyle.Width(width-20).Render(title))
+
+	contentStyle := lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(width - 20)
+
+	// Leave room for the title, filter line, instructions and borders
+	rows := height - 14
+	if rows < 5 {
+		rows = 5
+	}
+
+	var content string
+	switch {
+	case !m.loaded:
+		content = InfoStyle.Render("󰔟 Loading history...")
+	case m.err != nil:
+		content = ErrorStyle.Render("󰅖 " + m.err.Error())
+	case m.detail != nil:
+		content = m.renderDetail(rows)
+	default:
+		sections = append(sections, m.renderFilter())
+		content = m.renderList(rows)
+	}
+	sections = append(sections, contentStyle.Render(content))
+
+	if m.message != "" {
+		sections = append(sections, InfoStyle.Copy().MarginTop(1).Render(m.message))
+	}
+
+	var instructions string
+	switch {
+	case m.filtering:
+		instructions = "Type to filter • Enter: apply • Esc: clear"
+	case m.detail != nil:
+		instructions = "↑/↓: navigate • r: re-run • i: re-install • o: open • l: log • Esc: back"
+	default:
+		instructions = "↑/↓: navigate • Enter: details • /: filter • Tab: status • r: re-run • Esc: back"
+	}
+	instructionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true).
+		MarginTop(1).
+		Align(lipgloss.Center)
+	sections = append(sections, instructionStyle.Render(instructions))
+
+	return lipgloss.Place(
+		width,
+		height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, sections...),
+	)
+}
+
+func (m *HistoryModel) renderFilter() string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	filter := dimStyle.Render("/ to filter")
+	if m.filtering || m.filterInput.Value() != "" {
+		filter = m.filterInput.View()
+	}
+
+	status := dimStyle.Render("Status: ") + InfoStyle.Render(historyStatusFilters[m.statusFilter])
+	return lipgloss.NewStyle().MarginBottom(1).Render(filter + "    " + status)
+}
+
+func (m *HistoryModel) renderList(rows int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+
+	runs := m.visibleRuns()
+	if len(runs) == 0 {
+		if len(m.runs) == 0 {
+			return dimStyle.Render("No clone or install runs recorded yet")
+		}
+		return dimStyle.Render("No runs match the filter")
+	}
+
+	start, end := scrollWindow(m.cursor, len(runs), rows)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		run := runs[i]
+
+		status := SuccessStyle.Render("󰄬")
+		if failures := run.Failures(); failures > 0 {
+			status = ErrorStyle.Render(fmt.Sprintf("󰅖 %d failed", failures))
+		}
+
+		names := make([]string, 0, len(run.Entries))
+		for _, entry := range run.Entries {
+			names = append(names, entry.FullName)
+		}
+		summary := strings.Join(names, ", ")
+		if len(summary) > 60 {
+			summary = summary[:57] + "..."
+		}
+
+		line := fmt.Sprintf("%s  %-7s  %2d repos  %s", run.StartedAt.Format("2006-01-02 15:04"), run.Action, len(run.Entries), summary)
+		if i == m.cursor {
+			line = selectedStyle.Render("▶ " + line)
+		} else {
+			line = "  " + line
+		}
+		if d := run.Duration(); d > 0 {
+			line += dimStyle.Render("  " + d.Round(time.Second).String())
+		}
+		lines = append(lines, line+"  "+status)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *HistoryModel) renderDetail(rows int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+
+	var lines []string
+	if m.detail.TargetDir != "" {
+		lines = append(lines, dimStyle.Render("Target: "+m.detail.TargetDir), "")
+	}
+
+	// Each entry takes up to three lines
+	start, end := scrollWindow(m.entryCursor, len(m.detail.Entries), rows/3)
+
+	for i := start; i < end; i++ {
+		entry := m.detail.Entries[i]
+
+		icon := SuccessStyle.Render("󰄬")
+		if entry.Failed() {
+			icon = ErrorStyle.Render("󰅖")
+		}
+		name := entry.FullName
+		if i == m.entryCursor {
+			name = selectedStyle.Render("▶ " + name)
+		} else {
+			name = "  " + name
+		}
+		lines = append(lines, icon+" "+name)
+
+		var clone []string
+		if entry.Path != "" {
+			path := entry.Path
+			if !entry.Exists() {
+				path += " (removed)"
+			}
+			clone = append(clone, path)
+		}
+		if entry.Transport != "" {
+			clone = append(clone, entry.Transport)
+		}
+		if entry.CloneDuration > 0 {
+			clone = append(clone, entry.CloneDuration.Round(100*time.Millisecond).String())
+		}
+		if entry.Skipped {
+			clone = append(clone, "already existed")
+		}
+		lines = append(lines, dimStyle.Render("     "+strings.Join(clone, " • ")))
+
+		switch {
+		case entry.CloneError != "":
+			lines = append(lines, ErrorStyle.Render("     clone: "+entry.CloneError))
+		case entry.Install != nil:
+			lines = append(lines, "     "+renderInstallRecord(entry.Install))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func renderInstallRecord(record *history.InstallRecord) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	if record.ProjectType == "" {
+		return dimStyle.Render("install: " + record.Error)
+	}
+
+	summary := fmt.Sprintf("install: %s, %d commands, %s", record.ProjectType, len(record.Commands), record.Duration.Round(time.Second))
+	if record.Success {
+		return SuccessStyle.Render(summary)
+	}
+
+	for _, cmd := range record.Commands {
+		if !cmd.Success {
+			command := cmd.Command
+			if cmd.Dir != "" {
+				command = fmt.Sprintf("%s in %s", cmd.Command, cmd.Dir)
+			}
+			summary += fmt.Sprintf(" • %s failed (exit %d)", command, cmd.ExitCode)
+			break
+		}
+	}
+	return ErrorStyle.Render(summary)
+}
+
+// scrollWindow returns the range of at most size items that keeps cursor visible
+func scrollWindow(cursor, total, size int) (int, int) {
+	if size < 1 {
+		size = 1
+	}
+	start := 0
+	if cursor >= size {
+		start = cursor - size + 1
+	}
+	end := start + size
+	if end > total {
+		end = total
+	}
+	return start, end
+}
+
+// openExternal opens a URL or path with the platform's default handler
+func openExternal(target string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", target)
+	case "darwin":
+		cmd = exec.Command("open", target)
+	default: // linux and others
+		cmd = exec.Command("xdg-open", target)
+	}
+	return cmd.Start()
+}
@@ -11,7 +11,10 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/lvcasx1/quikgit/internal/detect"
+	"github.com/lvcasx1/quikgit/internal/history"
 	"github.com/lvcasx1/quikgit/internal/install"
+	"github.com/lvcasx1/quikgit/internal/trust"
 )
 
 type InstallationModel struct {
@@ -22,6 +25,31 @@ type InstallationModel struct {
 	ctx          context.Context
 	cancel       context.CancelFunc
 
+	// Install plan awaiting confirmation
+	plans      []*install.Plan
+	confirming bool
+	planOffset int
+	planCursor int // Index into planCommands
+	planNotice string
+	resultsCh  chan []install.InstallResult
+	results    []install.InstallResult
+
+	// History run the results are recorded in, taken over from the cloning
+	// or history screen, or a new install run
+	run *history.Run
+
+	// Variables of the environment files to create, asked once the plan is
+	// confirmed
+	envForm *EnvFormModel
+
+	// Command logs
+	logViewer  *LogViewerModel
+	lastLog    string // Log of the command started last
+	failedLogs int    // Failed command logs opened so far, to cycle through them
+
+	// Outcome of reverting the changes installs made to working trees
+	revertNotice string
+
 	// Progress tracking
 	completed    map[string]bool
 	errors       map[string]error
@@ -47,8 +75,14 @@ func NewInstallationModel(app *Application) *InstallationModel {
 		completed:    make(map[string]bool),
 		errors:       make(map[string]error),
 		statuses:     make(map[string]string),
+		resultsCh:    make(chan []install.InstallResult, 1),
+		run:          app.currentRun,
 		started:      false,
 	}
+	app.currentRun = nil
+	if model.run == nil {
+		model.run = history.NewRun(history.ActionInstall, "")
+	}
 
 	// Initialize progress bars for each repository
 	for _, repoPath := range model.repositories {
@@ -70,12 +104,46 @@ func (m *InstallationModel) Init() tea.Cmd {
 }
 
 func (m *InstallationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.logViewer != nil {
+		switch msg.(type) {
+		case tea.KeyMsg, logLoadedMsg, logFollowMsg:
+			cmd := m.logViewer.Update(msg)
+			if m.logViewer.Closed() {
+				m.logViewer = nil
+			}
+			return m, cmd
+		}
+	}
+
+	if m.envForm != nil {
+		if key, ok := msg.(tea.KeyMsg); ok && key.String() == "ctrl+c" {
+			m.cancel()
+			m.app.message = "Installation cancelled"
+			return m, m.app.NavigateTo(StateMainMenu)
+		}
+		cmd := m.envForm.Update(msg)
+		if m.envForm.Done() {
+			m.envForm = nil
+			return m, func() tea.Msg { return InstallStartMsg{} }
+		}
+		return m, cmd
+	}
+
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.confirming {
+			return m.updatePlan(msg)
+		}
+
 		switch msg.String() {
 		case "ctrl+c":
 			if !m.allCompleted {
 				m.cancel()
+				// Results arrive after this screen is gone; keep the run
+				// without them
+				if m.started {
+					m.recordResults(nil)
+				}
 				return m, m.app.NavigateTo(StateMainMenu)
 			}
 		case "enter":
@@ -88,6 +156,12 @@ func (m *InstallationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			if m.allCompleted {
 				return m, m.app.NavigateTo(StateMainMenu)
 			}
+		case "l":
+			return m, m.openLog()
+		case "r":
+			if m.allCompleted {
+				m.revertChanges()
+			}
 		}
 
 	case InstallProgressMsg:
@@ -97,10 +171,32 @@ func (m *InstallationModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.allCompleted = true
 		return m, nil
 
+	case InstallPlanMsg:
+		m.installMgr = msg.Manager
+		m.plans = msg.Plans
+		if msg.Notice != "" {
+			m.planNotice = msg.Notice
+		}
+		// Untrusted repositories are confirmed even with auto_install
+		needsConfirmation := false
+		for _, plan := range m.plans {
+			needsConfirmation = needsConfirmation || plan.NeedsConfirmation()
+		}
+		if m.app.config.Install.AutoInstall && !needsConfirmation {
+			return m, m.startInstall()
+		}
+		m.confirming = true
+		return m, nil
+
+	case InstallResultsMsg:
+		m.results = msg.Results
+		m.recordResults(msg.Results)
+		return m, nil
+
 	case InstallStartMsg:
 		if !m.started {
 			m.started = true
-			return m, tea.Batch(m.startActualInstallation(), m.monitorProgress())
+			return m, tea.Batch(m.startActualInstallation(), m.monitorProgress(), m.waitForResults())
 		}
 	}
 
@@ -132,8 +228,31 @@ func (m *InstallationModel) View() string {
 		height = 30
 	}
 
+	if m.logViewer != nil {
+		return m.logViewer.View()
+	}
+	if m.envForm != nil {
+		return m.envForm.View()
+	}
+
 	var sections []string
 
+	if m.confirming {
+		title := fmt.Sprintf("󰏖 Install Plan (%d repositories)", len(m.repositories))
+		sections = append(sections, TitleStyle.Width(width-20).Render(title))
+		sections = append(sections, m.renderPlan(width, height-12))
+
+		helpStyle := lipgloss.NewStyle().
+			Foreground(lipgloss.Color("241")).
+			Italic(true).
+			Align(lipgloss.Center).
+			MarginTop(1)
+		sections = append(sections, helpStyle.Render("Enter to install • ↑/↓ to select • Space to skip a command • s scripts on/off • t trust • Esc to cancel"))
+
+		return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+			lipgloss.JoinVertical(lipgloss.Center, sections...))
+	}
+
 	// Title
 	title := fmt.Sprintf("󰏖 Installing Dependencies (%d repositories)", len(m.repositories))
 	titleStyle := TitleStyle.Width(width - 20)
@@ -143,6 +262,13 @@ func (m *InstallationModel) View() string {
 	progressSection := m.renderProgress(width)
 	sections = append(sections, progressSection)
 
+	// Commands running and waiting for the scheduler
+	if m.started && !m.allCompleted {
+		if queue := m.renderQueue(width); queue != "" {
+			sections = append(sections, queue)
+		}
+	}
+
 	// Summary and instructions
 	summarySection := m.renderSummary(width)
 	sections = append(sections, summarySection)
@@ -176,7 +302,7 @@ func (m *InstallationModel) renderProgress(width int) string {
 
 	var progressItems []string
 
-	for _, repoPath := range m.repositories {
+	for i, repoPath := range m.repositories {
 		repoName := filepath.Base(repoPath)
 		var itemParts []string
 
@@ -215,7 +341,7 @@ func (m *InstallationModel) renderProgress(width int) string {
 					progressPercent = 0.4
 				case strings.Contains(status, "Completing"):
 					progressPercent = 0.9
-				case strings.Contains(status, "Waiting"):
+				case strings.Contains(status, "Waiting"), strings.Contains(status, "Queued"):
 					progressPercent = 0.0
 				case strings.Contains(status, "Failed"):
 					progressPercent = 0.0
@@ -241,6 +367,29 @@ func (m *InstallationModel) renderProgress(width int) string {
 			itemParts = append(itemParts, statusStyle.Render("🔄 "+status))
 		}
 
+		// Toolchain mismatches found while planning
+		if i < len(m.plans) {
+			for _, toolchain := range m.plans[i].Mismatches() {
+				itemParts = append(itemParts, renderToolchain(toolchain))
+			}
+		}
+
+		// Per-project outcome once the results are in
+		if i < len(m.results) {
+			for _, path := range m.results[i].EnvFiles {
+				itemParts = append(itemParts, SuccessStyle.Render("   󰈙 Created "+path))
+			}
+			for _, project := range m.results[i].Projects {
+				itemParts = append(itemParts, renderProjectResult(project))
+			}
+			if modified := m.results[i].Modified; len(modified) > 0 {
+				itemParts = append(itemParts, WarningStyle.Render("   ⚠ Modified tracked files: "+strings.Join(modified, ", ")))
+			}
+			if untracked := m.results[i].Untracked; len(untracked) > 0 {
+				itemParts = append(itemParts, WarningStyle.Render("   ⚠ New untracked files: "+strings.Join(untracked, ", ")))
+			}
+		}
+
 		// Join this repository's info
 		repoItem := lipgloss.JoinVertical(lipgloss.Left, itemParts...)
 		progressItems = append(progressItems, repoItem)
@@ -265,6 +414,9 @@ func (m *InstallationModel) renderSummary(width int) string {
 			MarginTop(1)
 
 		summary := fmt.Sprintf("Summary: %d successful, %d failed", m.successCount, m.errorCount)
+		if notOffline := m.notInstalledOffline(); len(notOffline) > 0 {
+			summary += "\nCould not be installed offline: " + strings.Join(notOffline, ", ")
+		}
 		summaryParts = append(summaryParts, summaryStyle.Render(summary))
 
 		// Instructions
@@ -272,6 +424,22 @@ func (m *InstallationModel) renderSummary(width int) string {
 			Align(lipgloss.Center).
 			MarginTop(1)
 		summaryParts = append(summaryParts, instructionStyle.Render("🎉 Press Enter to continue"))
+		if len(m.failedCommands()) > 0 {
+			logStyle := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("241")).
+				Italic(true).
+				Align(lipgloss.Center)
+			summaryParts = append(summaryParts, logStyle.Render("Press l to view the log of a failed command"))
+		}
+		if m.revertNotice != "" {
+			summaryParts = append(summaryParts, InfoStyle.Copy().Align(lipgloss.Center).Render(m.revertNotice))
+		} else if m.changedRepositories() > 0 {
+			revertStyle := lipgloss.NewStyle().
+				Foreground(lipgloss.Color("241")).
+				Italic(true).
+				Align(lipgloss.Center)
+			summaryParts = append(summaryParts, revertStyle.Render("Press r to revert the changes installs made to the working trees"))
+		}
 	} else {
 		// In-progress summary
 		progressText := fmt.Sprintf("Progress: %d/%d repositories processed",
@@ -287,29 +455,389 @@ func (m *InstallationModel) renderSummary(width int) string {
 			Foreground(lipgloss.Color("241")).
 			Italic(true).
 			Align(lipgloss.Center)
-		summaryParts = append(summaryParts, cancelStyle.Render("Press Ctrl+C to cancel"))
+		help := "Press Ctrl+C to cancel"
+		if m.lastLog != "" {
+			help = "Press l to follow the running command's log • Ctrl+C to cancel"
+		}
+		summaryParts = append(summaryParts, cancelStyle.Render(help))
 	}
 
 	return lipgloss.JoinVertical(lipgloss.Center, summaryParts...)
 }
 
+// startInstallationProcess builds the install manager and plans on the
+// command's goroutine; they are set on the model when the InstallPlanMsg
+// reaches Update
 func (m *InstallationModel) startInstallationProcess() tea.Cmd {
+	run := m.run
+	repositories := m.repositories
+	installCfg := m.app.config.Install
 	return func() tea.Msg {
-		if len(m.repositories) == 0 {
+		if len(repositories) == 0 {
 			return InstallProgressMsg{
 				Repository: "system",
 				Error:      fmt.Errorf("no repositories to install dependencies for"),
 			}
 		}
 
-		// Create installation manager with extended timeout for npm/yarn
-		m.installMgr = install.NewManager(3, 15*time.Minute)
-		m.installMgr.SetSkipOnError(true)
+		// Create installation manager from the effective install settings
+		var notice string
+		checker, err := m.trustChecker()
+		if err != nil {
+			notice = err.Error()
+		}
+		mgr := install.NewManager(installCfg.Concurrent, time.Duration(installCfg.TimeoutMinutes)*time.Minute)
+		mgr.SetSkipOnError(installCfg.SkipOnError)
+		mgr.SetVerify(installCfg.Verify)
+		mgr.SetDetectDepth(installCfg.DetectDepth)
+		mgr.SetToolchainPolicy(installCfg.ToolchainPolicy)
+		mgr.SetVersionManagers(installCfg.VersionManagers.ByTool(), installCfg.VersionManagers.Install)
+		mgr.SetTrust(checker, installCfg.Untrusted)
+		mgr.SetSandbox(install.NewSandbox(installCfg.Sandbox.Mode, installCfg.Sandbox.Network, installCfg.Sandbox.AllowedHosts))
+		mgr.SetLimits(install.Limits{
+			Commands: installCfg.Concurrent,
+			Network:  installCfg.Scheduler.Network,
+			Build:    installCfg.Scheduler.Build,
+			CPUs:     installCfg.Scheduler.CPUs,
+			MemoryMB: installCfg.Scheduler.MemoryMB,
+		})
+		// Post-clone hooks run when the repositories come from the cloning screen
+		cloned := run.Action == history.ActionClone
+		mgr.SetHooks(m.app.config.Hooks, cloned)
+		mgr.SetStrategy(installCfg.Container.Strategy, installCfg.Container.Runtime)
+		mgr.SetOffline(installCfg.Offline.Enabled, installCfg.Offline.FindLinks)
 
-		return InstallStartMsg{}
+		// A values file prefills the variables of created .env files
+		var envValues map[string]string
+		if installCfg.EnvValues != "" {
+			values, err := install.LoadEnvValues(installCfg.EnvValues)
+			if err != nil {
+				notice = err.Error()
+			}
+			envValues = values
+		}
+		mgr.SetEnvFiles(installCfg.EnvFiles, envValues)
+
+		// Logs are kept with the run the results are recorded in
+		if dir, err := history.LogDir(run.ID); err == nil {
+			mgr.SetLogDir(dir)
+		}
+
+		return InstallPlanMsg{Manager: mgr, Plans: mgr.Plans(repositories), Notice: notice}
 	}
 }
 
+// trustChecker trusts the signed-in user's repositories, their
+// organizations' and the allowlist's. The error tells why the allowlist
+// could not be read; the checker is usable without it.
+func (m *InstallationModel) trustChecker() (*trust.Checker, error) {
+	allowlist, err := trust.LoadAllowlist(m.app.config)
+
+	var owners []string
+	if m.app.githubClient != nil {
+		ctx, cancel := context.WithTimeout(m.ctx, 10*time.Second)
+		defer cancel()
+		owners, _ = trust.Owners(ctx, m.app.githubClient)
+	}
+	return trust.NewChecker(owners, allowlist), err
+}
+
+// startInstall starts the confirmed plans, first asking for the variables
+// of the .env files they create when the environment files are prompted
+// for, even with auto_install
+func (m *InstallationModel) startInstall() tea.Cmd {
+	if m.app.config.Install.EnvFiles == install.EnvPrompt {
+		for _, plan := range m.plans {
+			if plan.Error == nil && plan.UnsetEnv() {
+				m.envForm = NewEnvFormModel(m.app, m.plans)
+				return m.envForm.Init()
+			}
+		}
+	}
+	return func() tea.Msg { return InstallStartMsg{} }
+}
+
+// updatePlan handles keys while the install plan waits for confirmation
+func (m *InstallationModel) updatePlan(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "enter":
+		m.confirming = false
+		return m, m.startInstall()
+	case "esc", "ctrl+c":
+		m.cancel()
+		m.app.message = "Installation cancelled"
+		return m, m.app.NavigateTo(StateMainMenu)
+	case "up", "k":
+		if m.planCursor > 0 {
+			m.planCursor--
+		}
+	case "down", "j":
+		if m.planCursor < len(m.planCommands())-1 {
+			m.planCursor++
+		}
+	case " ":
+		if commands := m.planCommands(); m.planCursor < len(commands) {
+			ref := commands[m.planCursor]
+			ref.step.Skip[ref.index] = !ref.step.Skip[ref.index]
+		}
+	case "s":
+		// Toggle scripts for the selected command's repository
+		if commands := m.planCommands(); m.planCursor < len(commands) {
+			i := commands[m.planCursor].plan
+			plan := m.plans[i]
+			if plan.NoScripts {
+				m.plans[i] = m.installMgr.Plan(plan.Path)
+			} else {
+				m.plans[i] = m.installMgr.PlanWithoutScripts(plan.Path)
+			}
+			m.planCursor = min(m.planCursor, max(len(m.planCommands())-1, 0))
+		}
+	case "t":
+		// Trust the selected command's repository from now on
+		if commands := m.planCommands(); m.planCursor < len(commands) {
+			i := commands[m.planCursor].plan
+			plan := m.plans[i]
+			switch {
+			case plan.Trusted:
+			case plan.FullName == "":
+				m.planNotice = plan.Repository + " has no GitHub origin to trust"
+			default:
+				if err := m.installMgr.Trust().Allowlist().Add(plan.FullName); err != nil {
+					m.planNotice = "Failed to update the allowlist: " + err.Error()
+					break
+				}
+				m.planNotice = plan.FullName + " added to " + m.installMgr.Trust().Allowlist().Path()
+				m.plans[i] = m.installMgr.Plan(plan.Path)
+				m.planCursor = min(m.planCursor, max(len(m.planCommands())-1, 0))
+			}
+		}
+	}
+	return m, nil
+}
+
+// planCommand locates one command of the install plan
+type planCommand struct {
+	plan  int
+	step  *install.Step
+	index int
+}
+
+// planCommands lists every command of the install plan, in display order
+func (m *InstallationModel) planCommands() []planCommand {
+	var commands []planCommand
+	for p, plan := range m.plans {
+		for i := range plan.Steps {
+			for j := range plan.Steps[i].Commands {
+				commands = append(commands, planCommand{plan: p, step: &plan.Steps[i], index: j})
+			}
+		}
+	}
+	return commands
+}
+
+// renderPlan lists each repository's install steps and their commands
+func (m *InstallationModel) renderPlan(width, rows int) string {
+	boxStyle := lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(width - 20)
+
+	repoStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	stepStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("86"))
+	commandStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("252"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	skippedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Strikethrough(true)
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Italic(true)
+
+	var lines []string
+	commands, untrusted, index, cursorLine := 0, 0, 0, 0
+	for _, plan := range m.plans {
+		lines = append(lines, repoStyle.Render("󰏖 "+plan.Repository))
+		if plan.NeedsConfirmation() {
+			untrusted++
+			lines = append(lines, WarningStyle.Render("   ⚠ Untrusted: "+plan.TrustReason+" — its scripts will run"))
+		}
+		if plan.NoScripts {
+			lines = append(lines, dimStyle.Render("   Scripts disabled; commands that run repository code are skipped"))
+		}
+		if plan.Error != nil {
+			lines = append(lines, dimStyle.Render("   Skipped: "+plan.Describe()))
+			continue
+		}
+		for _, file := range plan.EnvFiles {
+			lines = append(lines, stepStyle.Render("   󰈙 Create "+file.Describe()))
+		}
+		if len(plan.Steps) == 0 {
+			lines = append(lines, dimStyle.Render("   Skipped: "+plan.Describe()))
+			continue
+		}
+
+		for _, step := range plan.Steps {
+			label := step.Name()
+			switch {
+			case step.Container != "":
+				label += " — runs with " + step.Container
+				if len(step.Matched) > 0 {
+					label += " instead of installing " + strings.Join(step.Matched, ", ") + " on the host"
+				}
+			case len(step.Matched) > 1:
+				label += " — matched " + strings.Join(step.Matched, ", ")
+			}
+			lines = append(lines, stepStyle.Render("   "+label))
+			for i, cmd := range step.Commands {
+				check, style := "[x]", commandStyle
+				if step.Skip[i] {
+					check, style = "[ ]", skippedStyle
+				}
+				if index == m.planCursor {
+					style = selectedStyle
+					cursorLine = len(lines)
+				}
+				line := fmt.Sprintf("   %s $ %s", check, strings.TrimSpace(cmd.Command+" "+strings.Join(cmd.Args, " ")))
+				if step.Hook != "" {
+					// Where the hook comes from matters for trust
+					line += " — " + strings.ToLower(cmd.Description)
+				}
+				lines = append(lines, style.Render(line))
+				index++
+			}
+			if len(step.Uncached) > 0 {
+				lines = append(lines, WarningStyle.Render("     offline: "+step.DescribeUncached()+" ⚠"))
+			}
+			for _, tool := range step.Missing() {
+				hint := ""
+				if suggestions := install.GetInstallationSuggestions(tool); len(suggestions) > 0 {
+					hint = " — " + suggestions[0]
+				}
+				lines = append(lines, WarningStyle.Render(fmt.Sprintf("     %s not found%s ⚠", tool, hint)))
+			}
+			for _, toolchain := range step.Toolchains {
+				lines = append(lines, renderToolchain(toolchain))
+			}
+		}
+		commands += plan.CommandCount()
+	}
+
+	if rows < 5 {
+		rows = 5
+	}
+	// Keep the selected command in view
+	if cursorLine < m.planOffset {
+		m.planOffset = cursorLine
+	}
+	if cursorLine >= m.planOffset+rows {
+		m.planOffset = cursorLine - rows + 1
+	}
+	if m.planOffset > len(lines)-rows {
+		m.planOffset = max(len(lines)-rows, 0)
+	}
+	visible := lines[m.planOffset:min(m.planOffset+rows, len(lines))]
+
+	footer := []string{"", InfoStyle.Render(fmt.Sprintf("%d commands will run • limits: %s", commands, m.installMgr.Scheduler().Describe()))}
+	if sandbox := m.installMgr.Sandbox(); sandbox.Mode != install.SandboxOff {
+		switch err := sandbox.Available(); {
+		case err == nil:
+			footer = append(footer, SuccessStyle.Render("󰌾 Sandboxed: "+sandbox.Describe()))
+		case sandbox.Mode == install.SandboxRequired:
+			footer = append(footer, ErrorStyle.Render("󰌾 Sandbox required but unavailable: "+err.Error()))
+		default:
+			footer = append(footer, WarningStyle.Render("󰌾 Sandbox unavailable, commands run unsandboxed: "+err.Error()))
+		}
+	}
+	if untrusted > 0 {
+		footer = append(footer, WarningStyle.Render(fmt.Sprintf("%d untrusted repositories will run their scripts; s disables them, t trusts the repository", untrusted)))
+	}
+	if m.planNotice != "" {
+		footer = append(footer, dimStyle.Render(m.planNotice))
+	}
+	return boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, append(visible, footer...)...))
+}
+
+// maxQueueLines is the number of scheduler queue entries shown
+const maxQueueLines = 8
+
+// renderQueue lists the commands the scheduler runs and holds back
+func (m *InstallationModel) renderQueue(width int) string {
+	queue := m.installMgr.Scheduler().Queue()
+	if len(queue) == 0 {
+		return ""
+	}
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	boxStyle := lipgloss.NewStyle().
+		Padding(0, 2).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("240")).
+		Width(width - 20)
+
+	waiting := 0
+	for _, entry := range queue {
+		if !entry.Running {
+			waiting++
+		}
+	}
+	lines := []string{InfoStyle.Render(fmt.Sprintf("Queue: %d running, %d waiting", len(queue)-waiting, waiting))}
+	for i, entry := range queue {
+		if i == maxQueueLines {
+			lines = append(lines, dimStyle.Render(fmt.Sprintf("  … %d more", len(queue)-maxQueueLines)))
+			break
+		}
+		icon := "󰇚"
+		if entry.Class == install.ClassBuild {
+			icon = "󰣪"
+		}
+		line := fmt.Sprintf("%s %-8s %s: %s", icon, entry.Class, entry.Repository, strings.TrimSpace(entry.Command))
+		if entry.Running {
+			lines = append(lines, SuccessStyle.Render("▶ "+line))
+		} else {
+			lines = append(lines, dimStyle.Render("  "+line+" • waiting for "+entry.Waiting))
+		}
+	}
+	return boxStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
+
+// renderToolchain shows a version pin with the version found locally
+func renderToolchain(toolchain detect.Toolchain) string {
+	found := "found " + toolchain.Found
+	switch {
+	case toolchain.Manager != "":
+		found = "provided by " + toolchain.Manager
+	case toolchain.Found == "":
+		found = "not installed"
+	}
+	line := fmt.Sprintf("     %s %s required (%s), %s", toolchain.Tool, toolchain.Required, toolchain.Source, found)
+
+	if toolchain.Satisfied {
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("241")).Render(line + " 󰄬")
+	}
+	return WarningStyle.Render(line + " ⚠")
+}
+
+// renderProjectResult shows the outcome of one install plan step
+func renderProjectResult(project install.ProjectResult) string {
+	name := project.Name
+	if project.Dir != "" {
+		name = fmt.Sprintf("%s (%s)", project.Name, project.Dir)
+	}
+
+	if project.Success {
+		line := fmt.Sprintf("   󰄬 %s in %s", name, project.Duration.Round(time.Second))
+		if project.VirtualEnv != "" {
+			line += " • venv " + project.VirtualEnv
+		}
+		if project.Sandboxed {
+			line += " • 󰌾 sandboxed"
+		}
+		return SuccessStyle.Render(line)
+	}
+	reason := "failed"
+	if project.Error != nil {
+		reason = project.Error.Error()
+	}
+	return ErrorStyle.Render(fmt.Sprintf("   󰅖 %s: %s", name, reason))
+}
+
 func (m *InstallationModel) monitorProgress() tea.Cmd {
 	return func() tea.Msg {
 		// Get progress channel
@@ -332,6 +860,8 @@ func (m *InstallationModel) monitorProgress() tea.Cmd {
 					Status:      progress.Status,
 					Error:       progress.Error,
 					Completed:   progress.Completed,
+					Sandboxed:   progress.Sandboxed,
+					LogFile:     progress.LogFile,
 				}
 
 			case <-m.ctx.Done():
@@ -354,12 +884,25 @@ func (m *InstallationModel) startActualInstallation() tea.Cmd {
 	return func() tea.Msg {
 		// Start the actual installation in a separate goroutine
 		go func() {
-			m.installMgr.InstallDependencies(m.ctx, m.repositories)
+			results, _ := m.installMgr.InstallPlans(m.ctx, m.plans)
+			m.resultsCh <- results
 		}()
 		return InstallStartMsg{}
 	}
 }
 
+// waitForResults delivers the install results once every plan has run
+func (m *InstallationModel) waitForResults() tea.Cmd {
+	return func() tea.Msg {
+		select {
+		case results := <-m.resultsCh:
+			return InstallResultsMsg{Results: results}
+		case <-m.ctx.Done():
+			return nil
+		}
+	}
+}
+
 func (m *InstallationModel) handleProgressUpdate(msg InstallProgressMsg) (tea.Model, tea.Cmd) {
 	// Ignore heartbeat messages
 	if msg.Repository == "_heartbeat" {
@@ -370,6 +913,12 @@ func (m *InstallationModel) handleProgressUpdate(msg InstallProgressMsg) (tea.Mo
 	if msg.Repository != "system" {
 		repoName := filepath.Base(msg.Repository)
 		m.statuses[repoName] = msg.Status
+		if msg.Sandboxed {
+			m.statuses[repoName] = "󰌾 " + msg.Status
+		}
+	}
+	if msg.LogFile != "" {
+		m.lastLog = msg.LogFile
 	}
 
 	// Handle completion
@@ -403,6 +952,120 @@ func (m *InstallationModel) handleProgressUpdate(msg InstallProgressMsg) (tea.Mo
 	return m, m.monitorProgress()
 }
 
+// recordResults attaches the install results to the screen's run and saves
+// it, once
+func (m *InstallationModel) recordResults(results []install.InstallResult) {
+	run := m.run
+	m.run = nil
+	if run == nil {
+		return
+	}
+
+	for i, result := range results {
+		// Repositories not reached before cancellation have no result
+		if i >= len(m.repositories) || result.Repository == "" {
+			continue
+		}
+		path := m.repositories[i]
+		entry := run.EntryByPath(path)
+		if entry == nil {
+			entry = &history.Entry{FullName: filepath.Base(path), Path: path}
+			run.Entries = append(run.Entries, entry)
+		}
+		entry.Install = history.NewInstallRecord(result)
+	}
+
+	run.FinishedAt = time.Now()
+	m.app.saveRun(run)
+}
+
+// notInstalledOffline returns the repositories an offline install failed
+// for, such as those with packages missing from the local caches
+func (m *InstallationModel) notInstalledOffline() []string {
+	if m.installMgr == nil || !m.installMgr.Offline() {
+		return nil
+	}
+	var repositories []string
+	for _, result := range m.results {
+		if result.Repository != "" && !result.Success {
+			repositories = append(repositories, result.Repository)
+		}
+	}
+	return repositories
+}
+
+// changedRepositories counts the repositories whose working tree an
+// install changed
+func (m *InstallationModel) changedRepositories() int {
+	changed := 0
+	for _, result := range m.results {
+		if len(result.Modified)+len(result.Untracked) > 0 {
+			changed++
+		}
+	}
+	return changed
+}
+
+// revertChanges restores the tracked files installs modified and removes
+// the untracked files they created, in every repository
+func (m *InstallationModel) revertChanges() {
+	reverted, files := 0, 0
+	var failures []string
+	for i := range m.results {
+		result := &m.results[i]
+		if i >= len(m.repositories) || len(result.Modified)+len(result.Untracked) == 0 {
+			continue
+		}
+		if err := install.RevertChanges(m.repositories[i], *result); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", result.Repository, err))
+			continue
+		}
+		reverted++
+		files += len(result.Modified) + len(result.Untracked)
+		result.Modified, result.Untracked = nil, nil
+	}
+
+	m.revertNotice = fmt.Sprintf("Reverted %d files in %d repositories", files, reverted)
+	if len(failures) > 0 {
+		m.revertNotice += " • failed: " + strings.Join(failures, "; ")
+	}
+}
+
+// failedCommands returns the failed commands that have a log
+func (m *InstallationModel) failedCommands() []install.CommandResult {
+	var failed []install.CommandResult
+	for _, result := range m.results {
+		for _, cmd := range result.Commands {
+			if !cmd.Success && cmd.LogFile != "" {
+				failed = append(failed, cmd)
+			}
+		}
+	}
+	return failed
+}
+
+// openLog follows the running command's log during the install, and
+// afterwards opens the failed commands' logs in turn
+func (m *InstallationModel) openLog() tea.Cmd {
+	if !m.allCompleted {
+		if m.lastLog == "" {
+			return nil
+		}
+		m.logViewer = NewLogViewerModel(m.app, "Command log", m.lastLog, true)
+		return m.logViewer.Init()
+	}
+
+	failed := m.failedCommands()
+	if len(failed) == 0 {
+		return nil
+	}
+	cmd := failed[m.failedLogs%len(failed)]
+	title := fmt.Sprintf("Failed: %s (%d/%d)", cmd.Command, m.failedLogs%len(failed)+1, len(failed))
+	m.failedLogs++
+	m.logViewer = NewLogViewerModel(m.app, title, cmd.LogFile, false)
+	return m.logViewer.Init()
+}
+
 // Message types for installation process
 type InstallStartMsg struct{}
 
@@ -412,6 +1075,20 @@ type InstallProgressMsg struct {
 	Status      string
 	Error       error
 	Completed   bool
+	Sandboxed   bool
+	LogFile     string
 }
 
 type InstallCompleteMsg struct{}
+
+// InstallPlanMsg carries the manager built for the install and its plans,
+// with a notice of the settings it could not load
+type InstallPlanMsg struct {
+	Manager *install.Manager
+	Plans   []*install.Plan
+	Notice  string
+}
+
+type InstallResultsMsg struct {
+	Results []install.InstallResult
+}
@@ -0,0 +1,359 @@
+package bubbletea
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// logFollowInterval is how often a followed log is read again
+const logFollowInterval = 500 * time.Millisecond
+
+// LogViewerModel shows an install command log. It opens on top of the
+// installation and history screens, which hand it their messages until it
+// is closed.
+type LogViewerModel struct {
+	app    *Application
+	title  string
+	path   string
+	lines  []string
+	err    error
+	offset int  // First visible line
+	follow bool // Read the file again as it grows and stay at its end
+	closed bool
+
+	// Search
+	searchInput textinput.Model
+	searching   bool
+	query       string
+	matches     []int // Lines containing query
+	match       int   // Current index into matches
+}
+
+// logLoadedMsg carries the lines read from a log file
+type logLoadedMsg struct {
+	path  string
+	lines []string
+	err   error
+}
+
+// logFollowMsg asks a following viewer to read its log again
+type logFollowMsg struct {
+	path string
+}
+
+func NewLogViewerModel(app *Application, title, path string, follow bool) *LogViewerModel {
+	searchInput := textinput.New()
+	searchInput.Placeholder = "Search"
+	searchInput.CharLimit = 100
+	searchInput.Width = 40
+
+	return &LogViewerModel{
+		app:         app,
+		title:       title,
+		path:        path,
+		follow:      follow,
+		searchInput: searchInput,
+	}
+}
+
+func (m *LogViewerModel) Init() tea.Cmd {
+	return m.load()
+}
+
+// Closed reports whether the user left the viewer.
+func (m *LogViewerModel) Closed() bool {
+	return m.closed
+}
+
+func (m *LogViewerModel) load() tea.Cmd {
+	path := m.path
+	return func() tea.Msg {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return logLoadedMsg{path: path, err: err}
+		}
+		text := strings.TrimSuffix(string(data), "\n")
+		lines := strings.Split(text, "\n")
+		for i, line := range lines {
+			// Keep what a terminal would show of progress bars redrawn with \r
+			if cr := strings.LastIndex(line, "\r"); cr >= 0 {
+				line = line[cr+1:]
+			}
+			lines[i] = strings.ReplaceAll(line, "\t", "    ")
+		}
+		return logLoadedMsg{path: path, lines: lines}
+	}
+}
+
+// Update handles the viewer's messages; the screen showing it returns the
+// command.
+func (m *LogViewerModel) Update(msg tea.Msg) tea.Cmd {
+	switch msg := msg.(type) {
+	case logLoadedMsg:
+		if msg.path != m.path || m.closed {
+			return nil
+		}
+		m.lines, m.err = msg.lines, msg.err
+		m.findMatches()
+		if m.follow {
+			m.offset = m.maxOffset()
+			path := m.path
+			return tea.Tick(logFollowInterval, func(time.Time) tea.Msg { return logFollowMsg{path: path} })
+		}
+		m.offset = min(m.offset, m.maxOffset())
+		return nil
+
+	case logFollowMsg:
+		if msg.path == m.path && m.follow && !m.closed {
+			return m.load()
+		}
+		return nil
+
+	case tea.KeyMsg:
+		if m.searching {
+			return m.updateSearch(msg)
+		}
+		return m.updateKeys(msg)
+	}
+
+	return nil
+}
+
+func (m *LogViewerModel) updateSearch(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		m.searching = false
+		m.searchInput.Blur()
+		return nil
+	case "enter":
+		m.searching = false
+		m.searchInput.Blur()
+		m.query = strings.TrimSpace(m.searchInput.Value())
+		m.findMatches()
+		m.match = 0
+		// Start from the first match at or below the top of the screen
+		for i, line := range m.matches {
+			if line >= m.offset {
+				m.match = i
+				break
+			}
+		}
+		m.showMatch()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	m.searchInput, cmd = m.searchInput.Update(msg)
+	return cmd
+}
+
+func (m *LogViewerModel) updateKeys(msg tea.KeyMsg) tea.Cmd {
+	rows := m.rows()
+
+	switch msg.String() {
+	case "esc":
+		m.closed = true
+		return nil
+	case "up", "k":
+		m.scroll(-1)
+	case "down", "j":
+		m.scroll(1)
+	case "pgup", "b":
+		m.scroll(-rows)
+	case "pgdown", " ":
+		m.scroll(rows)
+	case "home", "g":
+		m.scroll(-len(m.lines))
+	case "end", "G":
+		m.offset = m.maxOffset()
+	case "f":
+		m.follow = !m.follow
+		if m.follow {
+			return m.load()
+		}
+	case "/":
+		m.searching = true
+		m.searchInput.SetValue(m.query)
+		return m.searchInput.Focus()
+	case "n":
+		if len(m.matches) > 0 {
+			m.match = (m.match + 1) % len(m.matches)
+			m.showMatch()
+		}
+	case "N":
+		if len(m.matches) > 0 {
+			m.match = (m.match + len(m.matches) - 1) % len(m.matches)
+			m.showMatch()
+		}
+	}
+
+	return nil
+}
+
+// scroll moves by delta lines; scrolling up stops following
+func (m *LogViewerModel) scroll(delta int) {
+	if delta < 0 {
+		m.follow = false
+	}
+	m.offset = max(0, min(m.offset+delta, m.maxOffset()))
+}
+
+// showMatch scrolls the current match into view
+func (m *LogViewerModel) showMatch() {
+	if len(m.matches) == 0 {
+		return
+	}
+	line := m.matches[m.match]
+	if line < m.offset || line >= m.offset+m.rows() {
+		m.follow = false
+		m.offset = max(0, min(line-m.rows()/2, m.maxOffset()))
+	}
+}
+
+func (m *LogViewerModel) findMatches() {
+	m.matches = nil
+	if m.query == "" {
+		return
+	}
+	query := strings.ToLower(m.query)
+	for i, line := range m.lines {
+		if strings.Contains(strings.ToLower(line), query) {
+			m.matches = append(m.matches, i)
+		}
+	}
+	m.match = min(m.match, max(0, len(m.matches)-1))
+}
+
+// rows is the number of log lines shown at once
+func (m *LogViewerModel) rows() int {
+	return max(5, m.app.height-12)
+}
+
+func (m *LogViewerModel) maxOffset() int {
+	return max(0, len(m.lines)-m.rows())
+}
+
+func (m *LogViewerModel) View() string {
+	// Use full screen dimensions with fallback
+	width := m.app.width
+	height := m.app.height - 3
+	if width == 0 {
+		width = 120
+	}
+	if height <= 0 {
+		height = 30
+	}
+
+	var sections []string
+	sections = append(sections, TitleStyle.Width(width-20).Render("󰌱 "+m.title))
+
+	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	sections = append(sections, pathStyle.Render(m.path))
+
+	boxStyle := lipgloss.NewStyle().
+		Padding(0, 1).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(width - 20)
+	lineWidth := max(20, width-26)
+
+	var body string
+	switch {
+	case m.err != nil:
+		body = ErrorStyle.Render("Failed to read log: " + m.err.Error())
+	case len(m.lines) == 0:
+		body = pathStyle.Italic(true).Render("The log is empty")
+	default:
+		end := min(len(m.lines), m.offset+m.rows())
+		var lines []string
+		for i := m.offset; i < end; i++ {
+			lines = append(lines, m.renderLine(i, lineWidth))
+		}
+		body = strings.Join(lines, "\n")
+	}
+	sections = append(sections, boxStyle.Render(body))
+	sections = append(sections, m.renderStatus())
+
+	helpStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true).
+		Align(lipgloss.Center)
+	if m.searching {
+		sections = append(sections, m.searchInput.View())
+		sections = append(sections, helpStyle.Render("Enter to search • Esc to cancel"))
+	} else {
+		sections = append(sections, helpStyle.Render("↑/↓ PgUp/PgDn g/G to scroll • f follow • / search • n/N next/previous match • Esc to close"))
+	}
+
+	return lipgloss.Place(width, height, lipgloss.Center, lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, sections...))
+}
+
+// renderLine truncates a log line to width and highlights the search query
+func (m *LogViewerModel) renderLine(index, width int) string {
+	line := m.lines[index]
+	if runes := []rune(line); len(runes) > width {
+		line = string(runes[:width-1]) + "…"
+	}
+
+	style := lipgloss.NewStyle()
+	switch {
+	case strings.HasPrefix(line, "# "):
+		style = style.Foreground(lipgloss.Color("241"))
+	case strings.Contains(line, " [err] "):
+		style = style.Foreground(lipgloss.Color("214"))
+	}
+	if m.query == "" {
+		return style.Render(line)
+	}
+
+	matchStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("0")).Background(lipgloss.Color("220"))
+	if len(m.matches) > 0 && m.matches[m.match] == index {
+		matchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("229")).Background(lipgloss.Color("57"))
+	}
+
+	// Case-insensitive matching on a lowered copy; ToLower keeps the byte
+	// offsets of ASCII text, which logs mostly are
+	lower := strings.ToLower(line)
+	query := strings.ToLower(m.query)
+	if len(lower) != len(line) {
+		return style.Render(line)
+	}
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, query)
+		if i < 0 {
+			b.WriteString(style.Render(line))
+			break
+		}
+		b.WriteString(style.Render(line[:i]))
+		b.WriteString(matchStyle.Render(line[i : i+len(query)]))
+		line, lower = line[i+len(query):], lower[i+len(query):]
+	}
+	return b.String()
+}
+
+func (m *LogViewerModel) renderStatus() string {
+	var parts []string
+	if len(m.lines) > 0 {
+		last := min(len(m.lines), m.offset+m.rows())
+		parts = append(parts, fmt.Sprintf("Lines %d-%d of %d", m.offset+1, last, len(m.lines)))
+	}
+	if m.follow {
+		parts = append(parts, "following")
+	}
+	if m.query != "" {
+		if len(m.matches) == 0 {
+			parts = append(parts, fmt.Sprintf("no matches for %q", m.query))
+		} else {
+			parts = append(parts, fmt.Sprintf("match %d/%d for %q", m.match+1, len(m.matches), m.query))
+		}
+	}
+	return InfoStyle.Render(strings.Join(parts, " • "))
+}
@@ -33,6 +33,7 @@ type MainMenuModel struct {
 	unavailableDescStyle  lipgloss.Style
 	// Cache for card base styles
 	cardBaseStyle lipgloss.Style
+	compact       bool // Cards without padding for short terminals
 	// Pre-rendered static content cache
 	menuTitles       []string // Pre-rendered titles with icons
 	menuDescriptions []string // Pre-rendered descriptions with unavailable text
@@ -103,6 +104,31 @@ func NewMainMenuModel(app *Application) *MainMenuModel {
 			action:      StateAuth,
 			available:   true,
 		},
+		{
+			title:       "My Workspace",
+			description: "Check the status of local clones and fetch, pull or reinstall them",
+			icon:        "󰉓",
+			action:      StateMyWorkspace,
+			available:   true,
+		},
+		{
+			title:       "History",
+			description: "Browse past clone and install runs and repeat them",
+			icon:        "󰋚",
+			action:      StateHistory,
+			available:   true,
+		},
+	}
+
+	// Offer to sync the team workspace when running inside one
+	if ws := app.config.Workspace; ws != nil {
+		choices = append(choices, menuChoice{
+			title:       "Sync Workspace",
+			description: fmt.Sprintf("Clone and set up the %d repositories pinned by %s", len(ws.Repositories), ws.Name),
+			icon:        "󰉋",
+			action:      StateWorkspaceSync,
+			available:   app.isAuthenticated,
+		})
 	}
 
 	model := &MainMenuModel{
@@ -285,6 +311,11 @@ func (m *MainMenuModel) updateChoices() {
 
 	// Update the search repositories availability
 	m.choices[0].available = m.app.githubClient != nil
+	for i := range m.choices {
+		if m.choices[i].action == StateWorkspaceSync {
+			m.choices[i].available = m.app.githubClient != nil
+		}
+	}
 
 	// Update the GitHub Setup description
 	if m.choices[2].description != authDescription {
@@ -341,6 +372,15 @@ func (m *MainMenuModel) View() string {
 	// Join vertically and fill the screen
 	content := lipgloss.JoinVertical(lipgloss.Center, m.sections...)
 
+	// Switch to compact cards when the full-size menu does not fit
+	if !m.compact && lipgloss.Height(content) > height {
+		m.compact = true
+		m.cardBaseStyle = m.cardBaseStyle.Padding(0, 3).MarginBottom(0)
+		m.selectedCardStyle = lipgloss.NewStyle()
+		m.cachedMenu = ""
+		return m.View()
+	}
+
 	// Use full screen with proper centering
 	return lipgloss.Place(
 		width,
@@ -0,0 +1,401 @@
+package bubbletea
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/lvcasx1/quikgit/internal/inventory"
+)
+
+// MyWorkspaceModel lists the repositories already cloned under the
+// configured roots with their git status, and runs bulk actions on them.
+type MyWorkspaceModel struct {
+	app      *Application
+	roots    []string
+	repos    []*inventory.Repository
+	scanning bool
+	cursor   int
+	selected map[string]bool
+	message  string
+
+	// Bulk action state
+	ctx          context.Context
+	cancel       context.CancelFunc
+	manager      *inventory.Manager
+	running      inventory.Action
+	lastAction   inventory.Action
+	pending      int
+	actionStatus map[string]string
+	actionErrors map[string]error
+}
+
+// InventoryScannedMsg carries the repositories found by a scan
+type InventoryScannedMsg struct {
+	Repos []*inventory.Repository
+}
+
+// InventoryProgressMsg reports a bulk action update; Repo holds the fresh
+// status once the repository is done
+type InventoryProgressMsg struct {
+	Progress inventory.ActionProgress
+	Repo     *inventory.Repository
+}
+
+// InventoryActionDoneMsg is sent when every repository has been processed
+type InventoryActionDoneMsg struct{}
+
+func NewMyWorkspaceModel(app *Application) *MyWorkspaceModel {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &MyWorkspaceModel{
+		app:          app,
+		roots:        app.config.ScanRoots(),
+		scanning:     true,
+		selected:     make(map[string]bool),
+		ctx:          ctx,
+		cancel:       cancel,
+		actionStatus: make(map[string]string),
+		actionErrors: make(map[string]error),
+	}
+}
+
+func (m *MyWorkspaceModel) Init() tea.Cmd {
+	return m.scan()
+}
+
+func (m *MyWorkspaceModel) scan() tea.Cmd {
+	return func() tea.Msg {
+		return InventoryScannedMsg{Repos: inventory.Scan(m.ctx, m.roots, 8)}
+	}
+}
+
+func (m *MyWorkspaceModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case InventoryScannedMsg:
+		m.scanning = false
+		m.repos = msg.Repos
+		if m.cursor >= len(m.repos) {
+			m.cursor = 0
+		}
+		return m, nil
+
+	case InventoryProgressMsg:
+		return m.handleProgress(msg)
+
+	case InventoryActionDoneMsg:
+		m.message = fmt.Sprintf("Finished %s of %d repositories", m.running, m.pending)
+		if failed := len(m.actionErrors); failed > 0 {
+			m.message += fmt.Sprintf(", %d failed", failed)
+		}
+		m.running = ""
+		return m, nil
+
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+
+	return m, nil
+}
+
+func (m *MyWorkspaceModel) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.running != "" {
+		if msg.String() == "ctrl+c" || msg.String() == "esc" {
+			m.cancel()
+			return m, m.app.NavigateTo(StateMainMenu)
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.cancel()
+		return m, m.app.NavigateTo(StateMainMenu)
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.repos)-1 {
+			m.cursor++
+		}
+	case " ":
+		if m.cursor < len(m.repos) {
+			path := m.repos[m.cursor].Path
+			m.selected[path] = !m.selected[path]
+		}
+	case "a":
+		for _, repo := range m.repos {
+			m.selected[repo.Path] = true
+		}
+	case "d":
+		m.selected = make(map[string]bool)
+	case "r":
+		if !m.scanning {
+			m.scanning = true
+			m.message = ""
+			return m, m.scan()
+		}
+	case "f":
+		return m.startAction(inventory.ActionFetch)
+	case "p":
+		return m.startAction(inventory.ActionPull)
+	case "i":
+		targets := m.targets()
+		if len(targets) == 0 {
+			return m, nil
+		}
+		m.cancel()
+		m.app.currentRun = nil
+		m.app.clonedPaths = targets
+		return m, m.app.NavigateTo(StateInstalling)
+	case "o":
+		if m.cursor < len(m.repos) {
+			if err := openExternal(m.repos[m.cursor].Path); err != nil {
+				m.message = "Failed to open: " + err.Error()
+			}
+		}
+	}
+
+	return m, nil
+}
+
+// targets returns the selected repositories, or the one under the cursor
+func (m *MyWorkspaceModel) targets() []string {
+	var paths []string
+	for _, repo := range m.repos {
+		if m.selected[repo.Path] {
+			paths = append(paths, repo.Path)
+		}
+	}
+	if len(paths) == 0 && m.cursor < len(m.repos) {
+		paths = append(paths, m.repos[m.cursor].Path)
+	}
+	return paths
+}
+
+func (m *MyWorkspaceModel) startAction(action inventory.Action) (tea.Model, tea.Cmd) {
+	targets := m.targets()
+	if m.scanning || len(targets) == 0 {
+		return m, nil
+	}
+
+	token := ""
+	if m.app.authManager != nil {
+		token = m.app.authManager.GetToken()
+	}
+
+	manager := inventory.NewManager(token, m.app.config.GitHub.SSHKeyPath)
+	manager.Run(m.ctx, action, targets, m.app.config.Clone.Concurrent)
+	go manager.Wait()
+
+	m.manager = manager
+	m.running = action
+	m.lastAction = action
+	m.pending = len(targets)
+	m.message = ""
+	m.actionStatus = make(map[string]string)
+	m.actionErrors = make(map[string]error)
+	for _, path := range targets {
+		m.actionStatus[path] = "Queued"
+	}
+
+	return m, m.listen()
+}
+
+// listen waits for the next bulk action update
+func (m *MyWorkspaceModel) listen() tea.Cmd {
+	manager := m.manager
+	return func() tea.Msg {
+		progress, ok := <-manager.GetProgressChannel()
+		if !ok {
+			return InventoryActionDoneMsg{}
+		}
+
+		msg := InventoryProgressMsg{Progress: progress}
+		if progress.Completed {
+			msg.Repo = inventory.Status(m.ctx, progress.Path)
+		}
+		return msg
+	}
+}
+
+func (m *MyWorkspaceModel) handleProgress(msg InventoryProgressMsg) (tea.Model, tea.Cmd) {
+	progress := msg.Progress
+	m.actionStatus[progress.Path] = progress.Status
+	if progress.Error != nil {
+		m.actionErrors[progress.Path] = progress.Error
+	}
+
+	if msg.Repo != nil {
+		for i, repo := range m.repos {
+			if repo.Path == msg.Repo.Path {
+				msg.Repo.Root = repo.Root
+				m.repos[i] = msg.Repo
+				break
+			}
+		}
+	}
+
+	// Keep reading until the manager closes its channel
+	return m, m.listen()
+}
+
+func (m *MyWorkspaceModel) View() string {
+	// Use full screen dimensions with fallback
+	width := m.app.width
+	height := m.app.height - 3
+	if width == 0 {
+		width = 120
+	}
+	if height <= 0 {
+		height = 30
+	}
+
+	var sections []string
+
+	title := "󰉓 My Workspace"
+	if !m.scanning {
+		title = fmt.Sprintf("󰉓 My Workspace (%d repositories)", len(m.repos))
+	}
+	sections = append(sections, TitleStyle.Width(width-20).Render(title))
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	sections = append(sections, dimStyle.Render("Roots: "+strings.Join(m.roots, ", ")))
+
+	contentStyle := lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(width - 20)
+
+	var content string
+	switch {
+	case m.scanning:
+		content = InfoStyle.Render("󰔟 Reading repository status...")
+	case len(m.repos) == 0:
+		content = dimStyle.Render("No git repositories found. Add directories with clone.roots in the config file.")
+	default:
+		content = m.renderRepos(height - 14)
+	}
+	sections = append(sections, contentStyle.Render(content))
+
+	if m.message != "" {
+		sections = append(sections, InfoStyle.Copy().MarginTop(1).Render(m.message))
+	}
+
+	instructions := "↑/↓: navigate • Space: select • a/d: all/none • f: fetch • p: pull (ff) • i: install • o: open • r: rescan • Esc: back"
+	if m.running != "" {
+		instructions = fmt.Sprintf("Running %s on %d repositories • Esc: cancel", m.running, m.pending)
+	}
+	instructionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true).
+		MarginTop(1).
+		Align(lipgloss.Center)
+	sections = append(sections, instructionStyle.Render(instructions))
+
+	return lipgloss.Place(
+		width,
+		height,
+		lipgloss.Center,
+		lipgloss.Center,
+		lipgloss.JoinVertical(lipgloss.Center, sections...),
+	)
+}
+
+func (m *MyWorkspaceModel) renderRepos(rows int) string {
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+	selectedStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("205")).Bold(true)
+	warnStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+
+	// Pad names to the longest one shown
+	nameWidth := 0
+	names := make([]string, len(m.repos))
+	for i, repo := range m.repos {
+		names[i] = repo.Name
+		if repo.Root != "" {
+			if rel, err := filepath.Rel(repo.Root, repo.Path); err == nil {
+				names[i] = filepath.ToSlash(rel)
+			}
+		}
+		if len(names[i]) > nameWidth {
+			nameWidth = len(names[i])
+		}
+	}
+
+	start, end := scrollWindow(m.cursor, len(m.repos), rows)
+
+	var lines []string
+	for i := start; i < end; i++ {
+		repo := m.repos[i]
+
+		check := "[ ]"
+		if m.selected[repo.Path] {
+			check = "[x]"
+		}
+		name := fmt.Sprintf("%s %-*s", check, nameWidth, names[i])
+		if i == m.cursor {
+			name = selectedStyle.Render("▶ " + name)
+		} else {
+			name = "  " + name
+		}
+
+		var parts []string
+		if repo.Error != nil {
+			parts = append(parts, ErrorStyle.Render(repo.Error.Error()))
+		} else {
+			parts = append(parts, InfoStyle.Render(repo.Branch))
+
+			switch {
+			case repo.Upstream == "":
+				parts = append(parts, dimStyle.Render("no upstream"))
+			case repo.Ahead == 0 && repo.Behind == 0:
+				parts = append(parts, SuccessStyle.Render("up to date"))
+			default:
+				parts = append(parts, warnStyle.Render(fmt.Sprintf("↑%d ↓%d", repo.Ahead, repo.Behind)))
+			}
+
+			if repo.Dirty {
+				parts = append(parts, warnStyle.Render("● modified"))
+			}
+			parts = append(parts, dimStyle.Render("fetched "+formatAge(repo.LastFetch)))
+		}
+
+		if status, ok := m.actionStatus[repo.Path]; ok {
+			if err := m.actionErrors[repo.Path]; err != nil {
+				parts = append(parts, ErrorStyle.Render(string(m.lastAction)+" failed: "+err.Error()))
+			} else {
+				parts = append(parts, dimStyle.Render(status))
+			}
+		}
+
+		lines = append(lines, name+"  "+strings.Join(parts, "  "))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// formatAge renders how long ago t was in a compact form
+func formatAge(t time.Time) string {
+	if t.IsZero() {
+		return "never"
+	}
+
+	age := time.Since(t)
+	switch {
+	case age < time.Minute:
+		return "just now"
+	case age < time.Hour:
+		return fmt.Sprintf("%dm ago", int(age.Minutes()))
+	case age < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(age.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(age.Hours()/24))
+	}
+}
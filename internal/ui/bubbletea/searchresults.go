@@ -2,8 +2,6 @@ package bubbletea
 
 import (
 	"fmt"
-	"os/exec"
-	"runtime"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
@@ -371,18 +369,7 @@ func (m *SearchResultsModel) openRepository() (tea.Model, tea.Cmd) {
 	url := repo.HTMLURL
 
 	// Open repository in default browser
-	var cmd *exec.Cmd
-	switch runtime.GOOS {
-	case "windows":
-		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
-	case "darwin":
-		cmd = exec.Command("open", url)
-	default: // linux and others
-		cmd = exec.Command("xdg-open", url)
-	}
-
-	// Execute the command to open browser
-	if err := cmd.Start(); err != nil {
+	if err := openExternal(url); err != nil {
 		// If opening browser fails, we could show an error message
 		// For now, just continue without error handling
 	}
@@ -0,0 +1,154 @@
+package bubbletea
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/lvcasx1/quikgit/internal/workspace"
+)
+
+// WorkspaceSyncModel shows the repositories pinned by the team workspace
+// file and clones the ones that are missing.
+type WorkspaceSyncModel struct {
+	app      *Application
+	entries  []workspace.Entry
+	resolved bool
+	err      error
+}
+
+// WorkspaceResolvedMsg carries the result of looking up workspace repositories
+type WorkspaceResolvedMsg struct {
+	Entries []workspace.Entry
+	Error   error
+}
+
+func NewWorkspaceSyncModel(app *Application) *WorkspaceSyncModel {
+	return &WorkspaceSyncModel{app: app}
+}
+
+func (m *WorkspaceSyncModel) Init() tea.Cmd {
+	ws := m.app.config.Workspace
+	if ws == nil {
+		return nil
+	}
+
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+
+		entries, err := workspace.Resolve(ctx, m.app.githubClient, ws, m.app.config)
+		return WorkspaceResolvedMsg{Entries: entries, Error: err}
+	}
+}
+
+func (m *WorkspaceSyncModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case WorkspaceResolvedMsg:
+		m.resolved = true
+		m.entries = msg.Entries
+		m.err = msg.Error
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			return m, m.app.NavigateTo(StateMainMenu)
+		case "enter":
+			if !m.resolved {
+				return m, nil
+			}
+			missing := workspace.Missing(m.entries)
+			if len(missing) == 0 {
+				m.app.message = "Workspace is up to date"
+				return m, m.app.NavigateTo(StateMainMenu)
+			}
+			m.app.selectedRepos = missing
+			return m, m.app.NavigateTo(StateCloning)
+		}
+	}
+
+	return m, nil
+}
+
+func (m *WorkspaceSyncModel) View() string {
+	// Use full screen dimensions with fallback
+	width := m.app.width
+	height := m.app.height - 3
+	if width == 0 {
+		width = 120
+	}
+	if height <= 0 {
+		height = 30
+	}
+
+	ws := m.app.config.Workspace
+
+	var sections []string
+
+	title := "󰉋 Sync Workspace"
+	if ws != nil {
+		title = fmt.Sprintf("󰉋 Sync Workspace: %s", ws.Name)
+	}
+	sections = append(sections, TitleStyle.Width(width-20).Render(title))
+
+	contentStyle := lipgloss.NewStyle().
+		Padding(1, 3).
+		BorderStyle(lipgloss.RoundedBorder()).
+		BorderForeground(lipgloss.Color("62")).
+		Width(width - 40)
+
+	dimStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("241"))
+
+	var lines []string
+	switch {
+	case ws == nil:
+		lines = append(lines, dimStyle.Render("No workspace file found"))
+	case !m.resolved:
+		lines = append(lines, InfoStyle.Render(fmt.Sprintf("󰔟 Resolving %d repositories...", len(ws.Repositories))))
+	case m.err != nil:
+		lines = append(lines, ErrorStyle.Render("󰅖 "+m.err.Error()))
+	default:
+		lines = append(lines, dimStyle.Render("Workspace file: "+ws.Path))
+		lines = append(lines, "")
+		for _, entry := range m.entries {
+			switch {
+			case entry.Error != nil:
+				lines = append(lines, ErrorStyle.Render("󰅖 "+entry.FullName+" - "+entry.Error.Error()))
+			case entry.Present:
+				lines = append(lines, SuccessStyle.Render("󰄬 "+entry.FullName)+dimStyle.Render("  "+entry.Path))
+			default:
+				lines = append(lines, InfoStyle.Render("󰇚 "+entry.FullName)+dimStyle.Render("  will clone to "+entry.Path))
+			}
+		}
+	}
+	sections = append(sections, contentStyle.Render(strings.Join(lines, "\n")))
+
+	instructions := "Esc: back"
+	if m.resolved && m.err == nil {
+		if missing := len(workspace.Missing(m.entries)); missing > 0 {
+			instructions = fmt.Sprintf("Enter: clone and set up %d missing • Esc: back", missing)
+		} else {
+			instructions = "Enter: done • Esc: back"
+		}
+	}
+	instructionStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("241")).
+		Italic(true).
+		MarginTop(1).
+		Align(lipgloss.Center)
+	sections = append(sections, instructionStyle.Render(instructions))
+
+	content := lipgloss.JoinVertical(lipgloss.Center, sections...)
+
+	return lipgloss.Place(
+		width,
+		height,
+		lipgloss.Center,
+		lipgloss.Center,
+		content,
+	)
+}
@@ -0,0 +1,78 @@
+package workspace
+
+import (
+	"context"
+	"os"
+	"sync"
+
+	"github.com/lvcasx1/quikgit/internal/github"
+	"github.com/lvcasx1/quikgit/pkg/config"
+)
+
+// Entry is one repository listed by a workspace file.
+type Entry struct {
+	FullName   string
+	Repository *github.Repository
+	Path       string
+	Present    bool
+	Error      error
+}
+
+// Resolve looks up every repository listed in ws and works out where it is
+// cloned under the configured layout and whether it is already there.
+func Resolve(ctx context.Context, client *github.Client, ws *config.Workspace, cfg *config.Config) ([]Entry, error) {
+	names, err := ws.RepositoryNames()
+	if err != nil {
+		return nil, err
+	}
+
+	cloneDir, err := cfg.CloneDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, len(names))
+	semaphore := make(chan struct{}, 5)
+	var wg sync.WaitGroup
+
+	for i, name := range names {
+		wg.Add(1)
+		go func(index int, owner, repo string) {
+			defer wg.Done()
+
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			entry := Entry{FullName: owner + "/" + repo}
+
+			repository, err := client.GetRepository(ctx, owner, repo)
+			if err != nil {
+				entry.Error = err
+				entries[index] = entry
+				return
+			}
+
+			entry.Repository = repository
+			entry.Path = github.ClonePath(cloneDir, cfg.Clone.PathTemplate, cfg.Clone.CreateSubdirs, repository)
+			if _, err := os.Stat(entry.Path); err == nil {
+				entry.Present = true
+			}
+
+			entries[index] = entry
+		}(i, name[0], name[1])
+	}
+
+	wg.Wait()
+	return entries, nil
+}
+
+// Missing returns the resolved repositories that still need to be cloned.
+func Missing(entries []Entry) []*github.Repository {
+	var repos []*github.Repository
+	for _, entry := range entries {
+		if entry.Repository != nil && !entry.Present {
+			repos = append(repos, entry.Repository)
+		}
+	}
+	return repos
+}
@@ -1,60 +1,195 @@
 package config
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
 
 	"gopkg.in/yaml.v3"
 )
 
 type Config struct {
+	Version    int            `yaml:"version" config:"-" doc:"Schema version of this file"`
 	GitHub     GitHubConfig   `yaml:"github"`
 	Clone      CloneConfig    `yaml:"clone"`
 	Install    InstallConfig  `yaml:"install"`
+	Hooks      HooksConfig    `yaml:"hooks"`
 	UI         UIConfig       `yaml:"ui"`
 	Defaults   DefaultsConfig `yaml:"defaults"`
 	ConfigPath string         `yaml:"-"`
+
+	// Workspace is the project-local profile in effect, if any.
+	Workspace *Workspace `yaml:"-"`
+
+	// sources records which layer last set each key; keys absent from the
+	// map still hold their default value.
+	sources map[string]Source
+
+	// unknownKeys lists keys found in the file that no field accepts.
+	unknownKeys []string
+
+	// migratedFrom is the schema version the file was written in when it
+	// had to be migrated on load, or zero.
+	migratedFrom int
 }
 
 type GitHubConfig struct {
-	Token       string `yaml:"token,omitempty"`
-	PreferSSH   bool   `yaml:"prefer_ssh"`
-	SSHKeyPath  string `yaml:"ssh_key_path,omitempty"`
-	DefaultUser string `yaml:"default_user,omitempty"`
-	DefaultOrg  string `yaml:"default_org,omitempty"`
+	Token       string `yaml:"token,omitempty" secret:"true" doc:"GitHub personal access token"`
+	PreferSSH   bool   `yaml:"prefer_ssh" doc:"Clone over SSH when a key is available"`
+	SSHKeyPath  string `yaml:"ssh_key_path,omitempty" doc:"Private key used for SSH clones"`
+	DefaultUser string `yaml:"default_user,omitempty" doc:"User searched by default"`
+	DefaultOrg  string `yaml:"default_org,omitempty" doc:"Organization searched by default"`
 }
 
 type CloneConfig struct {
-	DefaultPath   string `yaml:"default_path,omitempty"`
-	Concurrent    int    `yaml:"concurrent"`
-	UseCurrentDir bool   `yaml:"use_current_dir"`
-	CreateSubdirs bool   `yaml:"create_subdirs"`
+	DefaultPath   string   `yaml:"default_path,omitempty" doc:"Directory repositories are cloned into"`
+	Concurrent    int      `yaml:"concurrent" validate:"min=1,max=32" doc:"Number of repositories cloned in parallel"`
+	UseCurrentDir bool     `yaml:"use_current_dir" doc:"Clone into the working directory instead of default_path"`
+	CreateSubdirs bool     `yaml:"create_subdirs" doc:"Clone into owner/name subdirectories"`
+	PathTemplate  string   `yaml:"path_template,omitempty" doc:"Layout of clone paths using {owner} and {name}, e.g. {owner}/{name}"`
+	Roots         []string `yaml:"roots,omitempty" doc:"Extra directories scanned for local clones by My Workspace"`
 }
 
 type InstallConfig struct {
-	Enabled        bool `yaml:"enabled"`
-	Concurrent     int  `yaml:"concurrent"`
-	TimeoutMinutes int  `yaml:"timeout_minutes"`
-	SkipOnError    bool `yaml:"skip_on_error"`
-	AutoInstall    bool `yaml:"auto_install"`
+	Enabled         bool   `yaml:"enabled" doc:"Install dependencies after cloning"`
+	Concurrent      int    `yaml:"concurrent" validate:"min=1,max=32" doc:"Number of install commands run at once across repositories"`
+	TimeoutMinutes  int    `yaml:"timeout_minutes" validate:"min=1" doc:"Timeout for each install command"`
+	SkipOnError     bool   `yaml:"skip_on_error" doc:"Keep running a project's commands after a required one fails"`
+	AutoInstall     bool   `yaml:"auto_install" doc:"Start installing without confirming the install plan"`
+	Verify          bool   `yaml:"verify" doc:"Check installs with a build where the detector has one, such as go build ./..."`
+	DetectDepth     int    `yaml:"detect_depth" validate:"min=0,max=10" doc:"Directory levels below the repository root searched for nested projects"`
+	ToolchainPolicy string `yaml:"toolchain_policy" validate:"oneof=ignore warn stop" doc:"When a pinned toolchain version is not installed: ignore, warn or stop the install"`
+	Untrusted       string `yaml:"untrusted" validate:"oneof=confirm no-scripts skip" doc:"Repositories outside your account, organizations and allowlist: confirm, install with scripts disabled, or skip"`
+	EnvFiles        string `yaml:"env_files" validate:"oneof=prompt create off" doc:"Missing .env files with a template such as .env.example: prompt for their values, create them with the template's defaults, or off"`
+	EnvValues       string `yaml:"env_values,omitempty" doc:"File of KEY=value lines giving the values of .env template variables, used instead of prompting"`
+
+	VersionManagers VersionManagersConfig `yaml:"version_managers"`
+	Sandbox         SandboxConfig         `yaml:"sandbox"`
+	Scheduler       SchedulerConfig       `yaml:"scheduler"`
+	Container       ContainerConfig       `yaml:"container"`
+	Offline         OfflineConfig         `yaml:"offline"`
+}
+
+// OfflineConfig installs from local caches only, for working without a
+// network.
+type OfflineConfig struct {
+	Enabled   bool   `yaml:"enabled" doc:"Install with each package manager's offline flags, and fail the projects whose lockfile the local caches cannot satisfy"`
+	FindLinks string `yaml:"find_links" doc:"Directory of wheels and sdists pip installs from offline, e.g. filled with pip download -d"`
+}
+
+// ContainerConfig installs the dependencies of repositories with a dev
+// container, Compose file or Dockerfile in that container instead of on the
+// host.
+type ContainerConfig struct {
+	Strategy string `yaml:"strategy" validate:"oneof=host container auto" doc:"Where dependencies install: on the host, in the repository's container setup, or auto for the container when Docker or Podman is reachable, replacing the host install only for a dev container with lifecycle commands"`
+	Runtime  string `yaml:"runtime" validate:"oneof=auto docker podman" doc:"Container engine: auto tries the Docker socket, then Podman"`
+}
+
+// SchedulerConfig limits install commands by what they mostly use: network
+// for downloads, CPU and memory for builds. Quick repositories run first.
+type SchedulerConfig struct {
+	Network  int `yaml:"network" validate:"min=0,max=64" doc:"Network-bound commands, such as downloads, run at once; 0 for no limit"`
+	Build    int `yaml:"build" validate:"min=0,max=64" doc:"CPU-bound commands, such as builds, run at once; 0 for half the CPUs"`
+	CPUs     int `yaml:"cpus" validate:"min=0" doc:"CPUs shared by the running commands; 0 for every CPU"`
+	MemoryMB int `yaml:"memory_mb" validate:"min=0" doc:"Memory in MiB shared by the running commands; 0 for half the system memory"`
+}
+
+// HooksConfig holds shell commands run in each repository at points of the
+// clone and install pipeline, for every repository or only for those of an
+// owner. Repositories add their own in a .quikgit/hooks.yaml file.
+type HooksConfig struct {
+	PostClone   []string              `yaml:"post_clone,omitempty" doc:"Commands run in a repository after it is cloned, before its install"`
+	PreInstall  []string              `yaml:"pre_install,omitempty" doc:"Commands run in a repository before its dependencies are installed"`
+	PostInstall []string              `yaml:"post_install,omitempty" doc:"Commands run in a repository after its dependencies installed successfully"`
+	Orgs        map[string]HookStages `yaml:"orgs,omitempty" doc:"Hooks for the repositories of an owner, keyed by owner"`
+}
+
+// HookStages lists the commands of each hook stage.
+type HookStages struct {
+	PostClone   []string `yaml:"post_clone,omitempty" doc:"Commands run after cloning"`
+	PreInstall  []string `yaml:"pre_install,omitempty" doc:"Commands run before installing dependencies"`
+	PostInstall []string `yaml:"post_install,omitempty" doc:"Commands run after installing dependencies"`
+}
+
+// Stage returns the commands of a stage: post_clone, pre_install or
+// post_install.
+func (h HookStages) Stage(stage string) []string {
+	switch stage {
+	case "post_clone":
+		return h.PostClone
+	case "pre_install":
+		return h.PreInstall
+	case "post_install":
+		return h.PostInstall
+	}
+	return nil
+}
+
+// Global returns the hooks run for every repository.
+func (h HooksConfig) Global() HookStages {
+	return HookStages{PostClone: h.PostClone, PreInstall: h.PreInstall, PostInstall: h.PostInstall}
+}
+
+// Owner returns the hooks of the repositories of owner, whose case is
+// ignored.
+func (h HooksConfig) Owner(owner string) HookStages {
+	for name, stages := range h.Orgs {
+		if strings.EqualFold(name, owner) {
+			return stages
+		}
+	}
+	return HookStages{}
+}
+
+// SandboxConfig runs install commands under bubblewrap, with write access
+// only to the repository and package caches and no credentials.
+type SandboxConfig struct {
+	Mode         string   `yaml:"mode" validate:"oneof=off auto required" doc:"Sandbox install commands: off, auto when bubblewrap works, or required"`
+	Network      string   `yaml:"network" validate:"oneof=full none allowlist" doc:"Network access of sandboxed commands: full, none, or allowlist, where a filtering proxy is the only way out"`
+	AllowedHosts []string `yaml:"allowed_hosts" doc:"Hosts reachable with network allowlist; .example.com also allows subdomains"`
+}
+
+// VersionManagersConfig picks, per tool, the version manager that installs
+// and selects a project's pinned version: auto tries mise, asdf and then the
+// tool's own manager; off runs whatever is on PATH.
+type VersionManagersConfig struct {
+	Node    string `yaml:"node" validate:"oneof=auto off mise asdf nvm" doc:"Version manager for pinned Node.js versions"`
+	Python  string `yaml:"python" validate:"oneof=auto off mise asdf pyenv" doc:"Version manager for pinned Python versions"`
+	Go      string `yaml:"go" validate:"oneof=auto off mise asdf" doc:"Version manager for pinned Go versions"`
+	Rust    string `yaml:"rust" validate:"oneof=auto off mise asdf rustup" doc:"Version manager for pinned Rust toolchains"`
+	Ruby    string `yaml:"ruby" validate:"oneof=auto off mise asdf" doc:"Version manager for pinned Ruby versions"`
+	Install bool   `yaml:"install" doc:"Install pinned versions the version manager does not have yet"`
+}
+
+// ByTool returns the choices keyed by the tool names install uses.
+func (v VersionManagersConfig) ByTool() map[string]string {
+	return map[string]string{
+		"node":   v.Node,
+		"python": v.Python,
+		"go":     v.Go,
+		"rust":   v.Rust,
+		"ruby":   v.Ruby,
+	}
 }
 
 type UIConfig struct {
-	Theme           string `yaml:"theme"`
-	ShowIcons       bool   `yaml:"show_icons"`
-	AnimationsSpeed string `yaml:"animations_speed"`
-	MouseSupport    bool   `yaml:"mouse_support"`
-	ShowLineNumbers bool   `yaml:"show_line_numbers"`
+	Theme           string `yaml:"theme" doc:"Color theme"`
+	ShowIcons       bool   `yaml:"show_icons" doc:"Show Nerd Font icons"`
+	AnimationsSpeed string `yaml:"animations_speed" validate:"oneof=slow normal fast" doc:"Speed of UI animations"`
+	MouseSupport    bool   `yaml:"mouse_support" doc:"Enable mouse input"`
+	ShowLineNumbers bool   `yaml:"show_line_numbers" doc:"Show line numbers in lists"`
 }
 
 type DefaultsConfig struct {
-	SearchSort     string `yaml:"search_sort"`
-	SearchOrder    string `yaml:"search_order"`
-	ResultsPerPage int    `yaml:"results_per_page"`
-	PreferredAuth  string `yaml:"preferred_auth"`
+	SearchSort     string `yaml:"search_sort" validate:"oneof=stars forks updated help-wanted-issues" doc:"Default search sort field"`
+	SearchOrder    string `yaml:"search_order" validate:"oneof=asc desc" doc:"Default search sort order"`
+	ResultsPerPage int    `yaml:"results_per_page" validate:"min=1,max=100" doc:"Search results requested per page"`
 }
 
 var DefaultConfig = Config{
+	Version: CurrentVersion,
 	GitHub: GitHubConfig{
 		PreferSSH: false,
 	},
@@ -65,11 +200,46 @@ var DefaultConfig = Config{
 		CreateSubdirs: false,
 	},
 	Install: InstallConfig{
-		Enabled:        true,
-		Concurrent:     3,
-		TimeoutMinutes: 10,
-		SkipOnError:    false,
-		AutoInstall:    true,
+		Enabled:         true,
+		Concurrent:      3,
+		TimeoutMinutes:  10,
+		SkipOnError:     false,
+		AutoInstall:     false,
+		DetectDepth:     3,
+		ToolchainPolicy: "warn",
+		Untrusted:       "confirm",
+		EnvFiles:        "prompt",
+		VersionManagers: VersionManagersConfig{
+			Node:    "auto",
+			Python:  "auto",
+			Go:      "auto",
+			Rust:    "auto",
+			Ruby:    "auto",
+			Install: true,
+		},
+		Sandbox: SandboxConfig{
+			Mode:    "off",
+			Network: "full",
+			AllowedHosts: []string{
+				"registry.npmjs.org", "registry.yarnpkg.com", "repo.yarnpkg.com",
+				"pypi.org", "files.pythonhosted.org",
+				"proxy.golang.org", "sum.golang.org",
+				"crates.io", "index.crates.io", "static.crates.io",
+				"rubygems.org", "index.rubygems.org",
+				"repo.packagist.org", "repo.maven.apache.org", "api.nuget.org", "pub.dev",
+				"github.com", "codeload.github.com", ".githubusercontent.com",
+			},
+		},
+		Scheduler: SchedulerConfig{
+			Network: 4,
+		},
+		Container: ContainerConfig{
+			Strategy: "host",
+			Runtime:  "auto",
+		},
+		Offline: OfflineConfig{
+			FindLinks: "~/.quikgit/wheels",
+		},
 	},
 	UI: UIConfig{
 		Theme:           "default",
@@ -82,16 +252,53 @@ var DefaultConfig = Config{
 		SearchSort:     "stars",
 		SearchOrder:    "desc",
 		ResultsPerPage: 30,
-		PreferredAuth:  "https",
 	},
 }
 
+// Load builds the effective configuration from the defaults, the file at
+// ~/.quikgit/config.yaml, the workspace file enclosing the clone directory
+// and QUIKGIT_* environment variables.
 func Load() (*Config, error) {
-	config := DefaultConfig
+	return LoadFrom("")
+}
 
-	configPath, err := getConfigPath()
+// LoadFrom is like Load but reads the configuration file at path, falling
+// back to the default location when path is empty.
+func LoadFrom(path string) (*Config, error) {
+	config, err := LoadFile(path)
 	if err != nil {
-		return &config, nil // Return default config if we can't find config path
+		return nil, err
+	}
+
+	if dir, err := config.CloneDir(); err == nil {
+		ws, err := FindWorkspace(dir)
+		if err != nil {
+			return nil, err
+		}
+		if ws != nil {
+			config.ApplyWorkspace(ws)
+		}
+	}
+
+	if err := config.ApplyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
+
+// LoadFile reads only the defaults and the configuration file, without any
+// environment overrides. It is used when the result is written back to disk.
+func LoadFile(path string) (*Config, error) {
+	config := DefaultConfig
+
+	configPath := path
+	if configPath == "" {
+		var err error
+		configPath, err = getConfigPath()
+		if err != nil {
+			return &config, nil // Return default config if we can't find config path
+		}
 	}
 
 	config.ConfigPath = configPath
@@ -105,13 +312,62 @@ func Load() (*Config, error) {
 		return nil, err
 	}
 
+	var raw map[string]interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, err
+	}
+
+	// Bring files written by older releases up to the current schema
+	if len(raw) > 0 {
+		from, err := migrate(raw)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", configPath, err)
+		}
+		if from != CurrentVersion {
+			config.migratedFrom = from
+			if data, err = yaml.Marshal(raw); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	if err := yaml.Unmarshal(data, &config); err != nil {
 		return nil, err
 	}
+	config.Version = CurrentVersion
+
+	// Record which keys the file actually sets so they can be reported
+	for _, key := range flattenKeys("", raw) {
+		if key == "version" {
+			continue
+		}
+		if !isKnownKey(key) {
+			config.unknownKeys = append(config.unknownKeys, key)
+		}
+		config.setSource(key, SourceFile)
+	}
+	sort.Strings(config.unknownKeys)
 
 	return &config, nil
 }
 
+// flattenKeys returns the dotted paths of every leaf in a decoded YAML mapping.
+func flattenKeys(prefix string, m map[string]interface{}) []string {
+	var keys []string
+	for name, value := range m {
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+		if nested, ok := value.(map[string]interface{}); ok {
+			keys = append(keys, flattenKeys(key, nested)...)
+			continue
+		}
+		keys = append(keys, key)
+	}
+	return keys
+}
+
 func (c *Config) Save() error {
 	if c.ConfigPath == "" {
 		var err error
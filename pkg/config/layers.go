@@ -0,0 +1,275 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Source identifies the configuration layer a value was taken from.
+type Source string
+
+const (
+	SourceDefault   Source = "default"
+	SourceFile      Source = "file"
+	SourceWorkspace Source = "workspace"
+	SourceEnv       Source = "env"
+	SourceFlag      Source = "flag"
+)
+
+// EnvPrefix is prepended to the upper-cased key to form its environment
+// variable, e.g. clone.concurrent -> QUIKGIT_CLONE_CONCURRENT.
+const EnvPrefix = "QUIKGIT_"
+
+// Setting describes the effective value of a single configuration key.
+type Setting struct {
+	Key    string
+	Value  string
+	Source Source
+	EnvVar string
+	Secret bool
+}
+
+// field is a settable leaf of the Config struct addressed by its dotted key.
+type field struct {
+	key   string
+	value reflect.Value
+	tag   reflect.StructTag
+}
+
+// fields walks the Config struct and returns every scalar leaf keyed by the
+// dotted path of its yaml tags.
+func (c *Config) fields() []field {
+	var fields []field
+	collectFields(reflect.ValueOf(c).Elem(), "", &fields)
+	return fields
+}
+
+func collectFields(v reflect.Value, prefix string, fields *[]field) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" || sf.Tag.Get("config") == "-" {
+			continue
+		}
+
+		key := name
+		if prefix != "" {
+			key = prefix + "." + name
+		}
+
+		fv := v.Field(i)
+		if fv.Kind() == reflect.Struct {
+			collectFields(fv, key, fields)
+			continue
+		}
+
+		if !isSupportedKind(fv) {
+			continue
+		}
+
+		*fields = append(*fields, field{key: key, value: fv, tag: sf.Tag})
+	}
+}
+
+func isSupportedKind(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.String, reflect.Bool, reflect.Int:
+		return true
+	case reflect.Slice:
+		return v.Type().Elem().Kind() == reflect.String
+	}
+	return false
+}
+
+func (c *Config) lookupField(key string) (field, error) {
+	for _, f := range c.fields() {
+		if f.key == key {
+			return f, nil
+		}
+	}
+	return field{}, fmt.Errorf("unknown configuration key %q", key)
+}
+
+// Keys returns every configuration key in sorted order.
+func Keys() []string {
+	cfg := DefaultConfig
+	var keys []string
+	for _, f := range cfg.fields() {
+		keys = append(keys, f.key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// EnvVar returns the environment variable that overrides key.
+func EnvVar(key string) string {
+	return EnvPrefix + strings.ToUpper(strings.NewReplacer(".", "_", "-", "_").Replace(key))
+}
+
+// Get returns the string form of the value stored under key.
+func (c *Config) Get(key string) (string, error) {
+	f, err := c.lookupField(key)
+	if err != nil {
+		return "", err
+	}
+	return formatValue(f.value), nil
+}
+
+// Set parses value into the field addressed by key and records source as its origin.
+func (c *Config) Set(key, value string, source Source) error {
+	f, err := c.lookupField(key)
+	if err != nil {
+		return err
+	}
+	if err := parseValue(f.value, value); err != nil {
+		return fmt.Errorf("%s: %w", key, err)
+	}
+	c.setSource(key, source)
+	return nil
+}
+
+// SourceOf reports which layer provided the current value of key.
+func (c *Config) SourceOf(key string) Source {
+	if source, ok := c.sources[key]; ok {
+		return source
+	}
+	return SourceDefault
+}
+
+func (c *Config) setSource(key string, source Source) {
+	if c.sources == nil {
+		c.sources = make(map[string]Source)
+	}
+	c.sources[key] = source
+}
+
+// ApplyEnv overrides keys from QUIKGIT_* variables found through lookup.
+func (c *Config) ApplyEnv(lookup func(string) (string, bool)) error {
+	for _, f := range c.fields() {
+		value, ok := lookup(EnvVar(f.key))
+		if !ok {
+			continue
+		}
+		if err := c.Set(f.key, value, SourceEnv); err != nil {
+			return fmt.Errorf("%s: %w", EnvVar(f.key), err)
+		}
+	}
+	return nil
+}
+
+// Settings returns the effective configuration annotated with the origin of each value.
+func (c *Config) Settings() []Setting {
+	var settings []Setting
+	for _, f := range c.fields() {
+		settings = append(settings, Setting{
+			Key:    f.key,
+			Value:  formatValue(f.value),
+			Source: c.SourceOf(f.key),
+			EnvVar: EnvVar(f.key),
+			Secret: f.tag.Get("secret") == "true",
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Key < settings[j].Key })
+	return settings
+}
+
+func formatValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Int:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Slice:
+		items := make([]string, v.Len())
+		for i := range items {
+			items[i] = v.Index(i).String()
+		}
+		return strings.Join(items, ",")
+	}
+	return ""
+}
+
+func parseValue(v reflect.Value, value string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid boolean %q", value)
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		v.SetInt(int64(n))
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported value type %s", v.Kind())
+	}
+	return nil
+}
+
+// Flags holds command-line overrides registered for every configuration key.
+type Flags struct {
+	fs     *flag.FlagSet
+	values map[string]*flagValue
+}
+
+type flagValue struct {
+	isBool bool
+	value  string
+}
+
+func (f *flagValue) String() string     { return f.value }
+func (f *flagValue) Set(s string) error { f.value = s; return nil }
+func (f *flagValue) IsBoolFlag() bool   { return f.isBool }
+
+// BindFlags registers a --<key> flag on fs for every configuration key.
+func BindFlags(fs *flag.FlagSet) *Flags {
+	flags := &Flags{fs: fs, values: make(map[string]*flagValue)}
+
+	cfg := DefaultConfig
+	for _, f := range cfg.fields() {
+		value := &flagValue{isBool: f.value.Kind() == reflect.Bool}
+		flags.values[f.key] = value
+		fs.Var(value, f.key, fmt.Sprintf("Override %s for this run", f.key))
+	}
+
+	return flags
+}
+
+// Apply copies the flags that were set on the command line into c.
+func (f *Flags) Apply(c *Config) error {
+	var err error
+	f.fs.Visit(func(fl *flag.Flag) {
+		value, ok := f.values[fl.Name]
+		if !ok || err != nil {
+			return
+		}
+		if setErr := c.Set(fl.Name, value.value, SourceFlag); setErr != nil {
+			err = fmt.Errorf("--%w", setErr)
+		}
+	})
+	return err
+}
@@ -0,0 +1,143 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// clearEnv unsets every QUIKGIT_* variable for the test, so that whoever
+// runs it does not change the results.
+func clearEnv(t *testing.T) {
+	t.Helper()
+	for _, key := range Keys() {
+		if _, ok := os.LookupEnv(EnvVar(key)); ok {
+			t.Setenv(EnvVar(key), "")
+			os.Unsetenv(EnvVar(key))
+		}
+	}
+}
+
+func TestLoadLayers(t *testing.T) {
+	clearEnv(t)
+	root := t.TempDir()
+	team := filepath.Join(root, "team")
+	writeWorkspace(t, team, "name: team\ndefault_org: acme\ninstall:\n  concurrent: 5\n")
+
+	path := filepath.Join(root, "config.yaml")
+	file := "clone:\n  default_path: " + team + "\n  use_current_dir: false\n" +
+		"install:\n  concurrent: 4\n  timeout_minutes: 20\n" +
+		"github:\n  default_org: file-org\n" +
+		"ui:\n  theme: dark\n"
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	type want struct {
+		value  string
+		source Source
+	}
+	check := func(t *testing.T, cfg *Config, wants map[string]want) {
+		t.Helper()
+		for key, w := range wants {
+			value, err := cfg.Get(key)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if value != w.value || cfg.SourceOf(key) != w.source {
+				t.Errorf("%s = %q from %s, want %q from %s", key, value, cfg.SourceOf(key), w.value, w.source)
+			}
+		}
+	}
+
+	// Defaults, then the file, then the workspace
+	cfg, err := LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+	check(t, cfg, map[string]want{
+		"defaults.search_sort":    {"stars", SourceDefault},
+		"ui.theme":                {"dark", SourceFile},
+		"install.timeout_minutes": {"20", SourceFile},
+		"install.concurrent":      {"5", SourceWorkspace},
+		"github.default_org":      {"acme", SourceWorkspace},
+	})
+
+	// Environment variables win over the workspace
+	t.Setenv("QUIKGIT_INSTALL_CONCURRENT", "6")
+	t.Setenv("QUIKGIT_UI_THEME", "light")
+	cfg, err = LoadFrom(path)
+	if err != nil {
+		t.Fatalf("LoadFrom: %v", err)
+	}
+	check(t, cfg, map[string]want{
+		"install.concurrent":      {"6", SourceEnv},
+		"ui.theme":                {"light", SourceEnv},
+		"install.timeout_minutes": {"20", SourceFile},
+		"github.default_org":      {"acme", SourceWorkspace},
+	})
+
+	// Flags win over everything
+	fs := flag.NewFlagSet("quikgit", flag.ContinueOnError)
+	flags := BindFlags(fs)
+	if err := fs.Parse([]string{"--install.concurrent=7", "--install.auto_install"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := flags.Apply(cfg); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+	check(t, cfg, map[string]want{
+		"install.concurrent":   {"7", SourceFlag},
+		"install.auto_install": {"true", SourceFlag},
+		"ui.theme":             {"light", SourceEnv},
+	})
+}
+
+func TestApplyEnv(t *testing.T) {
+	env := map[string]string{
+		"QUIKGIT_INSTALL_AUTO_INSTALL":          "true",
+		"QUIKGIT_UI_SHOW_ICONS":                 "0",
+		"QUIKGIT_CLONE_CONCURRENT":              "8",
+		"QUIKGIT_INSTALL_SANDBOX_ALLOWED_HOSTS": "registry.npmjs.org, pypi.org,,",
+		"QUIKGIT_CLONE_ROOTS":                   "~/src",
+		"QUIKGIT_UNKNOWN_KEY":                   "ignored",
+	}
+	cfg := DefaultConfig
+	if err := cfg.ApplyEnv(func(key string) (string, bool) { value, ok := env[key]; return value, ok }); err != nil {
+		t.Fatalf("ApplyEnv: %v", err)
+	}
+
+	if !cfg.Install.AutoInstall || cfg.UI.ShowIcons {
+		t.Errorf("auto_install = %v, show_icons = %v, want true and false", cfg.Install.AutoInstall, cfg.UI.ShowIcons)
+	}
+	if cfg.Clone.Concurrent != 8 {
+		t.Errorf("clone.concurrent = %d, want 8", cfg.Clone.Concurrent)
+	}
+	if want := []string{"registry.npmjs.org", "pypi.org"}; !slices.Equal(cfg.Install.Sandbox.AllowedHosts, want) {
+		t.Errorf("allowed_hosts = %q, want %q", cfg.Install.Sandbox.AllowedHosts, want)
+	}
+	if want := []string{"~/src"}; !slices.Equal(cfg.Clone.Roots, want) {
+		t.Errorf("clone.roots = %q, want %q", cfg.Clone.Roots, want)
+	}
+	if cfg.SourceOf("clone.concurrent") != SourceEnv || cfg.SourceOf("clone.default_path") != SourceDefault {
+		t.Errorf("sources = %s and %s", cfg.SourceOf("clone.concurrent"), cfg.SourceOf("clone.default_path"))
+	}
+	// The defaults are left as they were
+	if len(DefaultConfig.Install.Sandbox.AllowedHosts) < 3 || DefaultConfig.Clone.Concurrent != 3 {
+		t.Error("ApplyEnv changed DefaultConfig")
+	}
+
+	for key, value := range map[string]string{
+		"QUIKGIT_INSTALL_AUTO_INSTALL": "maybe",
+		"QUIKGIT_CLONE_CONCURRENT":     "eight",
+	} {
+		cfg := DefaultConfig
+		err := cfg.ApplyEnv(func(k string) (string, bool) { return value, k == key })
+		if err == nil || !strings.Contains(err.Error(), key) {
+			t.Errorf("%s=%s: error = %v, want one naming the variable", key, value, err)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package config
+
+import (
+	"fmt"
+)
+
+// CurrentVersion is the schema version written by this build. Files without
+// a version field predate versioning and are treated as version 1.
+const CurrentVersion = 3
+
+// migration upgrades a decoded configuration file from one schema version
+// to the next.
+type migration struct {
+	from  int
+	apply func(raw map[string]interface{}) error
+}
+
+var migrations = []migration{
+	{from: 1, apply: migrateV1ToV2},
+	{from: 2, apply: migrateV2ToV3},
+}
+
+// migrate upgrades raw in place to CurrentVersion and returns the version the
+// file was originally written in.
+func migrate(raw map[string]interface{}) (int, error) {
+	version := 1
+	if v, ok := raw["version"]; ok {
+		n, ok := v.(int)
+		if !ok || n < 1 {
+			return 0, fmt.Errorf("invalid schema version %v", v)
+		}
+		version = n
+	}
+
+	if version > CurrentVersion {
+		return 0, fmt.Errorf("schema version %d is newer than this release supports (%d)", version, CurrentVersion)
+	}
+
+	from := version
+	for _, m := range migrations {
+		if m.from != version {
+			continue
+		}
+		if err := m.apply(raw); err != nil {
+			return 0, fmt.Errorf("migrating from version %d: %w", version, err)
+		}
+		version++
+	}
+
+	raw["version"] = CurrentVersion
+	return from, nil
+}
+
+// migrateV1ToV2 folds defaults.preferred_auth, which duplicated
+// github.prefer_ssh, into the latter.
+func migrateV1ToV2(raw map[string]interface{}) error {
+	defaults, ok := raw["defaults"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	auth, ok := defaults["preferred_auth"]
+	if !ok {
+		return nil
+	}
+	delete(defaults, "preferred_auth")
+
+	if auth != "ssh" {
+		return nil
+	}
+
+	github, ok := raw["github"].(map[string]interface{})
+	if !ok {
+		github = make(map[string]interface{})
+		raw["github"] = github
+	}
+	if _, set := github["prefer_ssh"]; !set {
+		github["prefer_ssh"] = true
+	}
+
+	return nil
+}
+
+// migrateV2ToV3 resets install.auto_install, whose default became false so
+// that install plans are confirmed first. Earlier releases saved every key,
+// so a true in their files is the old default rather than a choice.
+func migrateV2ToV3(raw map[string]interface{}) error {
+	install, ok := raw["install"].(map[string]interface{})
+	if !ok {
+		return nil
+	}
+	if auto, ok := install["auto_install"].(bool); ok && auto {
+		install["auto_install"] = false
+	}
+	return nil
+}
+
+// MigratedFrom reports the schema version the file was migrated from when it
+// was loaded, or zero when it was already current.
+func (c *Config) MigratedFrom() int {
+	return c.migratedFrom
+}
@@ -0,0 +1,92 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func loadConfigFile(t *testing.T, content string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return LoadFile(path)
+}
+
+func TestMigrateV1(t *testing.T) {
+	tests := []struct {
+		name      string
+		file      string
+		preferSSH bool
+	}{
+		{"preferred_auth ssh", "defaults:\n  preferred_auth: ssh\n  search_sort: forks\n", true},
+		{"preferred_auth https", "defaults:\n  preferred_auth: https\n", false},
+		{"prefer_ssh already set", "github:\n  prefer_ssh: false\ndefaults:\n  preferred_auth: ssh\n", false},
+		{"version 1 without preferred_auth", "version: 1\nui:\n  theme: dark\n", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := loadConfigFile(t, tt.file)
+			if err != nil {
+				t.Fatalf("LoadFile: %v", err)
+			}
+			if cfg.MigratedFrom() != 1 || cfg.Version != CurrentVersion {
+				t.Errorf("migrated from %d to %d, want from 1 to %d", cfg.MigratedFrom(), cfg.Version, CurrentVersion)
+			}
+			if cfg.GitHub.PreferSSH != tt.preferSSH {
+				t.Errorf("github.prefer_ssh = %v, want %v", cfg.GitHub.PreferSSH, tt.preferSSH)
+			}
+			// The folded key is gone, so it is not reported as unknown
+			if err := cfg.Validate(); err != nil {
+				t.Errorf("Validate: %v", err)
+			}
+		})
+	}
+
+	cfg, err := loadConfigFile(t, "defaults:\n  preferred_auth: ssh\n  search_sort: forks\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Defaults.SearchSort != "forks" || cfg.SourceOf("github.prefer_ssh") != SourceFile {
+		t.Errorf("search_sort = %s, prefer_ssh from %s", cfg.Defaults.SearchSort, cfg.SourceOf("github.prefer_ssh"))
+	}
+}
+
+func TestMigrateAutoInstall(t *testing.T) {
+	for file, from := range map[string]int{
+		// Saved by releases before versioning, with every key
+		"install:\n  enabled: true\n  auto_install: true\n": 1,
+		"version: 2\ninstall:\n  auto_install: true\n":      2,
+	} {
+		cfg, err := loadConfigFile(t, file)
+		if err != nil {
+			t.Fatalf("LoadFile: %v", err)
+		}
+		if cfg.MigratedFrom() != from || cfg.Install.AutoInstall {
+			t.Errorf("%q: migrated from %d with auto_install %v, want from %d with false", file, cfg.MigratedFrom(), cfg.Install.AutoInstall, from)
+		}
+	}
+}
+
+func TestMigrateCurrent(t *testing.T) {
+	cfg, err := loadConfigFile(t, "version: 3\nui:\n  theme: dark\ninstall:\n  auto_install: true\n")
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	if cfg.MigratedFrom() != 0 || cfg.UI.Theme != "dark" || !cfg.Install.AutoInstall {
+		t.Errorf("migrated from %d, theme %s, auto_install %v", cfg.MigratedFrom(), cfg.UI.Theme, cfg.Install.AutoInstall)
+	}
+
+	for file, message := range map[string]string{
+		"version: 99\n":  "newer than this release supports",
+		"version: 0\n":   "invalid schema version",
+		"version: two\n": "invalid schema version",
+	} {
+		if _, err := loadConfigFile(t, file); err == nil || !strings.Contains(err.Error(), message) {
+			t.Errorf("%q: error = %v, want %q", file, err, message)
+		}
+	}
+}
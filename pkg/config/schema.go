@@ -0,0 +1,112 @@
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// SchemaID is the published location of the generated schema.
+const SchemaID = "https://raw.githubusercontent.com/lvcasx1/quikgit/main/schema/config.schema.json"
+
+// JSONSchema generates a JSON Schema (draft-07) for the configuration file
+// from the Config structs, their validate and doc tags, and DefaultConfig.
+func JSONSchema() ([]byte, error) {
+	schema := objectSchema(reflect.ValueOf(DefaultConfig))
+	schema["$schema"] = "http://json-schema.org/draft-07/schema#"
+	schema["$id"] = SchemaID
+	schema["title"] = "QuikGit configuration"
+
+	// The version field is not a regular setting, describe it explicitly
+	properties := schema["properties"].(map[string]interface{})
+	properties["version"] = map[string]interface{}{
+		"type":        "integer",
+		"minimum":     1,
+		"maximum":     CurrentVersion,
+		"default":     CurrentVersion,
+		"description": "Schema version of this file",
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func objectSchema(v reflect.Value) map[string]interface{} {
+	properties := make(map[string]interface{})
+
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		name := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		if prop := valueSchema(v.Field(i), sf.Tag); prop != nil {
+			properties[name] = prop
+		}
+	}
+
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func valueSchema(v reflect.Value, tag reflect.StructTag) map[string]interface{} {
+	var prop map[string]interface{}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		prop = objectSchema(v)
+	case reflect.String:
+		prop = map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		prop = map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		prop = map[string]interface{}{"type": "integer"}
+	case reflect.Slice:
+		items := valueSchema(reflect.New(v.Type().Elem()).Elem(), "")
+		if items == nil {
+			return nil
+		}
+		prop = map[string]interface{}{"type": "array", "items": items}
+	case reflect.Map:
+		values := valueSchema(reflect.New(v.Type().Elem()).Elem(), "")
+		if values == nil {
+			return nil
+		}
+		prop = map[string]interface{}{"type": "object", "additionalProperties": values}
+	default:
+		return nil
+	}
+
+	r := parseRules(tag.Get("validate"))
+	if r.min != nil {
+		prop["minimum"] = *r.min
+	}
+	if r.max != nil {
+		prop["maximum"] = *r.max
+	}
+	if len(r.oneOf) > 0 {
+		prop["enum"] = r.oneOf
+	}
+	if doc := tag.Get("doc"); doc != "" {
+		prop["description"] = doc
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		prop["default"] = v.Bool()
+	case reflect.String, reflect.Int:
+		if !v.IsZero() {
+			prop["default"] = v.Interface()
+		}
+	}
+
+	return prop
+}
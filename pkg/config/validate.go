@@ -0,0 +1,150 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ValidationError describes a single invalid configuration key.
+type ValidationError struct {
+	Key     string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Key, e.Message)
+}
+
+// ValidationErrors collects every problem found by Validate.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// rules are the constraints declared in a field's validate tag, e.g.
+// `validate:"min=1,max=32"` or `validate:"oneof=asc desc"`.
+type rules struct {
+	min   *int
+	max   *int
+	oneOf []string
+}
+
+func parseRules(tag string) rules {
+	var r rules
+	for _, rule := range strings.Split(tag, ",") {
+		name, arg, _ := strings.Cut(rule, "=")
+		switch name {
+		case "min":
+			if n, err := strconv.Atoi(arg); err == nil {
+				r.min = &n
+			}
+		case "max":
+			if n, err := strconv.Atoi(arg); err == nil {
+				r.max = &n
+			}
+		case "oneof":
+			r.oneOf = strings.Fields(arg)
+		}
+	}
+	return r
+}
+
+// Validate checks every key against the constraints declared on the Config
+// structs and reports unknown keys found in the file. It returns nil or a
+// ValidationErrors value.
+func (c *Config) Validate() error {
+	var errs ValidationErrors
+
+	for _, key := range c.unknownKeys {
+		errs = append(errs, ValidationError{Key: key, Message: "unknown key"})
+	}
+
+	for _, f := range c.fields() {
+		r := parseRules(f.tag.Get("validate"))
+
+		switch f.value.Kind() {
+		case reflect.Int:
+			n := int(f.value.Int())
+			if r.min != nil && n < *r.min {
+				errs = append(errs, ValidationError{Key: f.key, Message: fmt.Sprintf("must be at least %d (got %d)", *r.min, n)})
+			}
+			if r.max != nil && n > *r.max {
+				errs = append(errs, ValidationError{Key: f.key, Message: fmt.Sprintf("must be at most %d (got %d)", *r.max, n)})
+			}
+		case reflect.String:
+			value := f.value.String()
+			if len(r.oneOf) > 0 && !containsString(r.oneOf, value) {
+				errs = append(errs, ValidationError{
+					Key:     f.key,
+					Message: fmt.Sprintf("must be one of %s (got %q)", strings.Join(r.oneOf, ", "), value),
+				})
+			}
+		}
+	}
+
+	if t := c.Clone.PathTemplate; t != "" && !strings.Contains(t, "{name}") {
+		errs = append(errs, ValidationError{Key: "clone.path_template", Message: fmt.Sprintf("must contain {name} (got %q)", t)})
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// isKnownKey reports whether a dotted key from a configuration file is
+// accepted by some field of Config.
+func isKnownKey(key string) bool {
+	t := reflect.TypeOf(Config{})
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		sf, ok := fieldByYAMLName(t, part)
+		if !ok {
+			return false
+		}
+
+		ft := sf.Type
+		if ft.Kind() == reflect.Ptr {
+			ft = ft.Elem()
+		}
+
+		switch ft.Kind() {
+		case reflect.Struct:
+			t = ft
+		case reflect.Map:
+			// Map keys are free-form
+			return true
+		default:
+			return i == len(parts)-1
+		}
+	}
+
+	return true
+}
+
+func fieldByYAMLName(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if strings.Split(sf.Tag.Get("yaml"), ",")[0] == name {
+			return sf, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	cfg := DefaultConfig
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("defaults do not validate: %v", err)
+	}
+
+	tests := []struct {
+		key     string
+		value   string
+		message string
+	}{
+		{"install.toolchain_policy", "sometimes", `must be one of ignore, warn, stop (got "sometimes")`},
+		{"install.untrusted", "allow", `must be one of confirm, no-scripts, skip (got "allow")`},
+		{"install.sandbox.mode", "docker", "must be one of"},
+		{"ui.animations_speed", "instant", "must be one of slow, normal, fast"},
+		{"defaults.search_order", "random", "must be one of asc, desc"},
+		{"clone.concurrent", "0", "must be at least 1 (got 0)"},
+		{"clone.concurrent", "33", "must be at most 32 (got 33)"},
+		{"install.detect_depth", "11", "must be at most 10 (got 11)"},
+		{"defaults.results_per_page", "101", "must be at most 100 (got 101)"},
+		{"install.timeout_minutes", "-1", "must be at least 1 (got -1)"},
+		{"clone.path_template", "{owner}", `must contain {name} (got "{owner}")`},
+	}
+	for _, tt := range tests {
+		cfg := DefaultConfig
+		if err := cfg.Set(tt.key, tt.value, SourceFlag); err != nil {
+			t.Fatalf("Set(%s): %v", tt.key, err)
+		}
+
+		var errs ValidationErrors
+		if !errors.As(cfg.Validate(), &errs) || len(errs) != 1 {
+			t.Errorf("%s=%s: Validate = %v, want one error", tt.key, tt.value, cfg.Validate())
+			continue
+		}
+		if errs[0].Key != tt.key || !strings.Contains(errs[0].Message, tt.message) {
+			t.Errorf("%s=%s: error = %q, want %s: %s", tt.key, tt.value, errs[0], tt.key, tt.message)
+		}
+	}
+}
+
+func TestValidateFile(t *testing.T) {
+	clearEnv(t)
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := "clone:\n  concurrent: 40\n  shallow: true\n" +
+		"install:\n  untrusted: yes-please\n  sandbox:\n    network: some\n" +
+		"hooks:\n  orgs:\n    acme:\n      post_clone: [make]\n"
+	if err := os.WriteFile(path, []byte(file), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile: %v", err)
+	}
+	var errs ValidationErrors
+	if !errors.As(cfg.Validate(), &errs) {
+		t.Fatalf("Validate = %v, want ValidationErrors", cfg.Validate())
+	}
+
+	// Every problem is reported, each by its key; hook organizations are
+	// free-form
+	keys := make(map[string]bool)
+	for _, err := range errs {
+		keys[err.Key] = true
+	}
+	for _, key := range []string{"clone.shallow", "clone.concurrent", "install.untrusted", "install.sandbox.network"} {
+		if !keys[key] {
+			t.Errorf("no error for %s in %v", key, errs)
+		}
+	}
+	if len(errs) != 4 {
+		t.Errorf("Validate = %v, want 4 errors", errs)
+	}
+}
@@ -0,0 +1,182 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WorkspaceFileName is the name of the checked-in file that marks a team
+// workspace. It applies to every clone made inside its directory.
+const WorkspaceFileName = ".quikgit.yaml"
+
+// Workspace is a project-local profile shared by a team. Its settings are
+// layered between the user's config file and environment overrides.
+type Workspace struct {
+	Name         string           `yaml:"name"`
+	CloneTo      string           `yaml:"clone_to,omitempty"`
+	PathTemplate string           `yaml:"path_template,omitempty"`
+	DefaultOrg   string           `yaml:"default_org,omitempty"`
+	Install      WorkspaceInstall `yaml:"install,omitempty"`
+	Repositories []string         `yaml:"repositories"`
+
+	// Path is the location of the workspace file.
+	Path string `yaml:"-"`
+}
+
+// WorkspaceInstall holds the install settings a workspace can pin.
+type WorkspaceInstall struct {
+	Concurrent int `yaml:"concurrent,omitempty"`
+}
+
+// Root returns the directory containing the workspace file.
+func (w *Workspace) Root() string {
+	return filepath.Dir(w.Path)
+}
+
+// CloneRoot returns the directory repositories are cloned into, which is
+// clone_to resolved against the workspace root.
+func (w *Workspace) CloneRoot() string {
+	if w.CloneTo == "" {
+		return w.Root()
+	}
+	if filepath.IsAbs(w.CloneTo) {
+		return w.CloneTo
+	}
+	return filepath.Join(w.Root(), w.CloneTo)
+}
+
+// RepositoryNames splits each owner/name entry, qualifying bare names with
+// the workspace's default organization.
+func (w *Workspace) RepositoryNames() ([][2]string, error) {
+	var names [][2]string
+	for _, entry := range w.Repositories {
+		owner, name, found := strings.Cut(strings.TrimSpace(entry), "/")
+		if !found {
+			if w.DefaultOrg == "" {
+				return nil, fmt.Errorf("repository %q has no owner and the workspace has no default_org", entry)
+			}
+			owner, name = w.DefaultOrg, owner
+		}
+		if owner == "" || name == "" {
+			return nil, fmt.Errorf("invalid repository %q, expected owner/name", entry)
+		}
+		names = append(names, [2]string{owner, name})
+	}
+	return names, nil
+}
+
+// FindWorkspace looks for a workspace file in dir and each of its parents.
+// It returns nil without an error when none exists.
+func FindWorkspace(dir string) (*Workspace, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		path := filepath.Join(dir, WorkspaceFileName)
+		if _, err := os.Stat(path); err == nil {
+			return LoadWorkspace(path)
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadWorkspace reads the workspace file at path.
+func LoadWorkspace(path string) (*Workspace, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var ws Workspace
+	if err := yaml.Unmarshal(data, &ws); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	ws.Path = path
+
+	if ws.Name == "" {
+		ws.Name = filepath.Base(ws.Root())
+	}
+
+	if _, err := ws.RepositoryNames(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &ws, nil
+}
+
+// ApplyWorkspace layers the settings pinned by ws over c.
+func (c *Config) ApplyWorkspace(ws *Workspace) {
+	c.Workspace = ws
+
+	c.Clone.DefaultPath = ws.CloneRoot()
+	c.setSource("clone.default_path", SourceWorkspace)
+	c.Clone.UseCurrentDir = false
+	c.setSource("clone.use_current_dir", SourceWorkspace)
+
+	if ws.PathTemplate != "" {
+		c.Clone.PathTemplate = ws.PathTemplate
+		c.setSource("clone.path_template", SourceWorkspace)
+	}
+	if ws.DefaultOrg != "" {
+		c.GitHub.DefaultOrg = ws.DefaultOrg
+		c.setSource("github.default_org", SourceWorkspace)
+	}
+	if ws.Install.Concurrent > 0 {
+		c.Install.Concurrent = ws.Install.Concurrent
+		c.setSource("install.concurrent", SourceWorkspace)
+	}
+}
+
+// CloneDir returns the directory new clones are placed under.
+func (c *Config) CloneDir() (string, error) {
+	if c.Clone.UseCurrentDir || c.Clone.DefaultPath == "" {
+		return os.Getwd()
+	}
+	return c.Clone.DefaultPath, nil
+}
+
+// ScanRoots returns the directories searched for local clones: the clone
+// directory followed by clone.roots, without duplicates. A leading ~ in
+// clone.roots refers to the home directory.
+func (c *Config) ScanRoots() []string {
+	var roots []string
+	seen := make(map[string]bool)
+
+	add := func(dir string) {
+		if dir == "" {
+			return
+		}
+		if dir == "~" || strings.HasPrefix(dir, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, dir[1:])
+			}
+		}
+		if abs, err := filepath.Abs(dir); err == nil {
+			dir = abs
+		}
+		if !seen[dir] {
+			seen[dir] = true
+			roots = append(roots, dir)
+		}
+	}
+
+	if dir, err := c.CloneDir(); err == nil {
+		add(dir)
+	}
+	for _, dir := range c.Clone.Roots {
+		add(dir)
+	}
+
+	return roots
+}
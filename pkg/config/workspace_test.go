@@ -0,0 +1,80 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeWorkspace(t *testing.T, dir, content string) string {
+	t.Helper()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, WorkspaceFileName)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFindWorkspace(t *testing.T) {
+	root := t.TempDir()
+	team := filepath.Join(root, "team")
+	outer := writeWorkspace(t, team, "name: team\nclone_to: src\nrepositories: [acme/api]\n")
+	nested := writeWorkspace(t, filepath.Join(team, "src", "tools"), "default_org: acme\nrepositories: [cli]\n")
+
+	deep := filepath.Join(team, "src", "acme", "api", "internal")
+	if err := os.MkdirAll(deep, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"in the workspace root", team, outer},
+		{"walks up from a clone", deep, outer},
+		{"nearest file wins", filepath.Join(team, "src", "tools"), nested},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ws, err := FindWorkspace(tt.dir)
+			if err != nil {
+				t.Fatalf("FindWorkspace: %v", err)
+			}
+			if ws == nil || ws.Path != tt.want {
+				t.Fatalf("FindWorkspace(%s) = %v, want %s", tt.dir, ws, tt.want)
+			}
+		})
+	}
+
+	ws, _ := FindWorkspace(team)
+	if ws.Name != "team" || ws.CloneRoot() != filepath.Join(team, "src") {
+		t.Errorf("workspace = %q cloning to %s", ws.Name, ws.CloneRoot())
+	}
+	ws, _ = FindWorkspace(filepath.Join(team, "src", "tools"))
+	if names, err := ws.RepositoryNames(); err != nil || len(names) != 1 || names[0] != [2]string{"acme", "cli"} {
+		t.Errorf("RepositoryNames = %v, %v, want acme/cli", names, err)
+	}
+}
+
+func TestFindWorkspaceNone(t *testing.T) {
+	// Only the directories above are searched, never those beside or below
+	root := t.TempDir()
+	writeWorkspace(t, filepath.Join(root, "sibling"), "repositories: []\n")
+	writeWorkspace(t, filepath.Join(root, "clone", "child"), "repositories: []\n")
+
+	ws, err := FindWorkspace(filepath.Join(root, "clone"))
+	if err != nil || ws != nil {
+		t.Errorf("FindWorkspace = %v, %v, want none", ws, err)
+	}
+}
+
+func TestLoadWorkspaceInvalid(t *testing.T) {
+	path := writeWorkspace(t, t.TempDir(), "repositories: [tool]\n")
+	if _, err := LoadWorkspace(path); err == nil {
+		t.Error("a repository without owner or default_org was accepted")
+	}
+}